@@ -139,7 +139,8 @@ class Worker < ApplicationRecord
         @started_at = jobrun ? jobrun.created_at : Time.now
       end
 
-      worker_info = worker_run_infos.create!({ supervisor_id: s.id, log_location: wi.log_location, uid: wi.uid, jobrun_id: wi.jobrun_id,
+      worker_info = worker_run_infos.create!({ supervisor_id: s.id, log_location: wi.log_location, uid: wi.uid,
+                                               log_encryption_key: wi.log_encryption_key.presence, jobrun_id: wi.jobrun_id,
                                                rebuild_hash: wi.rebuild_hash.delete("\u0000"), exit_code: wi.exit_code.delete("\u0000"),
                                                output: wi.output.delete("\u0000"), error: wi.error.delete("\u0000"),
                                                project_id:, finished_at: @finished_at || wi.finished_at.to_time,
@@ -0,0 +1,177 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"brisk-supervisor/api"
+	"brisk-supervisor/brisk-cli/utilities"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	"brisk-supervisor/shared/constants"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// attachCmd represents the attach command
+var attachCmd = &cobra.Command{
+	Use:   "attach [run-id]",
+	Short: "Follow a run that is in progress",
+	Long: `Attach shows the output of a run as it happens, from another terminal or another machine. The output from the start of the run is replayed first.
+Without a run id it follows the last run started from this machine. To follow a teammate's run pass the run id and supervisor they were given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		var runId string
+		if len(args) > 0 {
+			runId = args[0]
+		}
+		endpoint, err := cmd.Flags().GetString("super")
+		if err != nil {
+			return err
+		}
+		return AttachToRun(cmd.Context(), runId, endpoint)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	attachCmd.Flags().String("super", "", "the log service of the supervisor running it, printed at the start of the run")
+}
+
+// logServiceEndpoint is the log service on the same host as the supervisor endpoint
+func logServiceEndpoint(superEndpoint string) string {
+	host, _, err := net.SplitHostPort(superEndpoint)
+	if err != nil {
+		host = superEndpoint
+	}
+	return net.JoinHostPort(host, constants.LOGSERVICE_PORT)
+}
+
+// logService is a connection to the log service of the supervisor running a run
+type logService struct {
+	pb.LogStreamClient
+	conn *grpc.ClientConn
+	// runId is the last run started from here when we weren't given one
+	runId  string
+	config *Config
+	// logEncryptionKey is the key of the run when it was started from here
+	logEncryptionKey string
+}
+
+func (l *logService) Close() {
+	l.conn.Close()
+}
+
+// connectToLogService gives the context with auth for the calls. A run started from here is found by its id, or is
+// the last run without one, so it doesn't need an endpoint.
+func connectToLogService(ctx context.Context, runId string, endpoint string) (context.Context, *logService, error) {
+	setupUUID(ctx)
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return ctx, nil, err
+	}
+	config, err := initialLoadConfig(ctx, workingDirectory)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	// with an endpoint and no run id it is the latest run on that supervisor, which may not be ours
+	var saved *utilities.LastRun
+	if runId != "" {
+		saved, err = utilities.LoadRun(ctx, config.ProjectToken, runId)
+	} else if endpoint == "" {
+		saved, err = utilities.LoadLastRun(ctx, config.ProjectToken)
+	}
+	if err != nil {
+		Logger(ctx).Errorf("Error loading the runs started from here %v", err)
+	}
+	var logEncryptionKey string
+	if saved != nil {
+		runId = saved.RunId
+		logEncryptionKey = saved.LogEncryptionKey
+		if endpoint == "" {
+			endpoint = saved.Endpoint
+		}
+	}
+	if endpoint == "" {
+		return ctx, nil, errors.New("no run started from here to use, pass the run id and --super printed at the start of the run")
+	}
+
+	ctx, _, err = setupAuthCtx(ctx, *config)
+	if err != nil {
+		return ctx, nil, err
+	}
+	conn, err := getConn(ctx, &api.Super{ExternalEndpoint: endpoint})
+	if err != nil {
+		return ctx, nil, fmt.Errorf("could not connect to %v: %w", endpoint, err)
+	}
+	return ctx, &logService{LogStreamClient: pb.NewLogStreamClient(conn), conn: conn, runId: runId, config: config, logEncryptionKey: logEncryptionKey}, nil
+}
+
+// AttachToRun prints the output of the run until it finishes, it fails if the run does
+func AttachToRun(ctx context.Context, runId string, endpoint string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ctx, span := otel.Tracer(name).Start(ctx, "AttachToRun")
+	defer span.End()
+
+	ctx, logs, err := connectToLogService(ctx, runId, endpoint)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	runId = logs.runId
+
+	stream, err := logs.Attach(ctx, &pb.AttachRequest{RunId: runId})
+	if err != nil {
+		return err
+	}
+
+	// the outputs are unbuffered so once one is taken the one before it has been printed
+	outputChan := make(chan *pb.Output)
+	go printOutputLoop(ctx, logs.config, outputChan, nil, &TerminalWriter{Output: os.Stdout}, &ScreenSect{}, nil)
+
+	failed := false
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if status.Code(err) == codes.NotFound {
+			return errors.New(status.Convert(err).Message())
+		}
+		if err != nil {
+			Logger(ctx).Errorf("Error following run %v %v", runId, err)
+			return err
+		}
+		if in.RunId != "" {
+			fmt.Printf("Attached to run %v\n", in.RunId)
+			continue
+		}
+		if in.Control == types.FAILED {
+			failed = true
+		}
+		outputChan <- in
+	}
+	// wait for the last output to be shown
+	outputChan <- nil
+	if failed {
+		fmt.Println("The run failed")
+		return TestFailedError
+	}
+	fmt.Println("The run finished")
+	return nil
+}
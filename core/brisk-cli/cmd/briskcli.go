@@ -76,6 +76,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -105,7 +106,7 @@ func getConn(ctx context.Context, super *api.Super) (*grpc.ClientConn, error) {
 		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)),
 		grpc_retry.WithMax(5),
 		grpc_retry.WithOnRetryCallback(func(ctx context.Context, attempt uint, err error) {
-			fmt.Printf("retry.. %v \n", attempt)
+			fmt.Fprintf(messageOutput(), "retry.. %v \n", attempt)
 			Logger(ctx).Errorf("retrying after error: %v attempt # %v while connecting to %+v", err, attempt, super)
 		}),
 	}
@@ -172,7 +173,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 
 	if err != nil {
 		Logger(ctx).Errorf("Error during setup connecting to %v :  %v ", super.ExternalEndpoint, err)
-		fmt.Printf(".")
+		fmt.Fprintf(messageOutput(), ".")
 		return err
 	}
 
@@ -188,7 +189,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 
 		outputChan <- &pb.Output{Response: "Starting run in CI mode", Created: timestamppb.Now()}
 
-		startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, repoInfo)
+		startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, repoInfo, viper.GetBool("ONLY_FAILED"))
 		if err != nil {
 			Logger(ctx).Errorf("Error during run %v ", err.Error())
 
@@ -204,7 +205,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 		duration, derr := durafmt.ParseString(totalTime.Round(time.Second).String())
 
 		if derr != nil {
-			fmt.Println(derr)
+			fmt.Fprintln(messageOutput(), derr)
 		}
 
 		outputChan <- &pb.Output{Response: fmt.Sprintf("Finished Run in %s", duration), Created: timestamppb.Now()}
@@ -226,7 +227,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 
 				outputChan <- &pb.Output{Response: "Starting run", Created: timestamppb.Now()}
 
-				startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, nil)
+				startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, nil, viper.GetBool("ONLY_FAILED"))
 
 				if err != nil {
 					Logger(ctx).Debugf("Error during run %+v ", err)
@@ -237,7 +238,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 				duration, timeErr := durafmt.ParseString(totalTime.Round(time.Second).String())
 
 				if timeErr != nil {
-					fmt.Println(timeErr)
+					fmt.Fprintln(messageOutput(), timeErr)
 					Logger(ctx).Errorf("Error getting time %+v ", timeErr)
 				}
 
@@ -252,13 +253,14 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 					outputChan <- &pb.Output{Response: "Test run failed", Created: timestamppb.Now()}
 				}
 
-			case "R", "\r", "\n":
+			case "R", "\r", "\n", "F":
 
 				outputChan <- &pb.Output{Response: "Starting run", Created: timestamppb.Now()}
 				Logger(ctx).Debug("Connectivity : %v", conn.GetState())
-				startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, nil)
+				onlyFailed := val == "F" || viper.GetBool("ONLY_FAILED")
+				startTime, err := singleTestRun(ctx, super, privateKey, syncHost, workingDirectory, outputChan, authCreds, true, nil, onlyFailed)
 				if err != nil && !errors.Is(err, context.Canceled) {
-					fmt.Println(err)
+					fmt.Fprintln(messageOutput(), err)
 					Logger(ctx).Errorf("Error during run %+v ", err)
 					_ = bugsnag.Notify(err)
 
@@ -267,7 +269,7 @@ func connectToServer(ctx context.Context, config Config, super *api.Super, outpu
 				totalTime := endTime.Sub(startTime)
 				duration, timeErr := durafmt.ParseString(totalTime.Round(time.Second).String())
 				if timeErr != nil {
-					fmt.Println(timeErr)
+					fmt.Fprintln(messageOutput(), timeErr)
 					Logger(ctx).Errorf("Error getting time %+v ", timeErr)
 				}
 				outputChan <- &pb.Output{Response: fmt.Sprintf("Completed in %v ", duration), Created: timestamppb.Now()}
@@ -414,7 +416,7 @@ func setUlimit(ctx context.Context, ulimit int) {
 	var rLimit syscall.Rlimit
 	err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit)
 	if err != nil {
-		fmt.Println("Error Getting Rlimit ", err)
+		fmt.Fprintln(messageOutput(), "Error Getting Rlimit ", err)
 	}
 	Logger(ctx).Debugf("The current rLimit is %v ", rLimit)
 	rLimit.Max = 999999
@@ -446,26 +448,56 @@ func ClearWorkers(ctx context.Context) error {
 	}
 	ctx, _, err = setupAuthCtx(ctx, *config)
 	if err != nil {
-		fmt.Println("Error during auth: ", err)
+		fmt.Fprintln(messageOutput(), "Error during auth: ", err)
 		return err
 	}
 
 	err = ClearWorkersForProject(ctx, viper.GetString("ApiEndpoint"), "")
 	if err != nil {
-		fmt.Println("Error clearing workers: ", err)
+		fmt.Fprintln(messageOutput(), "Error clearing workers: ", err)
 		return err
 	}
-	fmt.Println("Cleared workers")
+	fmt.Fprintln(messageOutput(), "Cleared workers")
 	return nil
 
 }
 
+// setupHumanOutput picks how the run is shown, the screens when we are on a terminal or plain lines
+// messageOutput is where messages for people go, stderr when the json events are written to stdout so they don't
+// end up in the event stream
+func messageOutput() io.Writer {
+	if viper.GetString("OUTPUT") == "json" && (viper.GetString("OUTPUT_FILE") == "" || viper.GetString("OUTPUT_FILE") == "-") {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func setupHumanOutput(ctx context.Context, events *JsonEventWriter) (OutputWriter, *ScreenSect, io.Writer) {
+	// when the json events go to stdout the human readable output moves to stderr
+	var humanOutput io.Writer = os.Stdout
+	if events != nil && events.Output == os.Stdout {
+		humanOutput = os.Stderr
+	}
+
+	// if no terminal
+	if viper.GetBool("NO_TERM") || !term.IsTerminal(os.Stdout.Fd()) || humanOutput != os.Stdout {
+		Logger(ctx).Debug("Not a terminal")
+		return &TerminalWriter{Output: humanOutput}, &ScreenSect{}, humanOutput
+	}
+	Logger(ctx).Debug("Is a terminal")
+
+	display := setupDisplay()
+	status := display.GetStatusScreen()
+	status.Set("⚡ Initializing")
+	return display, status, humanOutput
+}
+
 func initialLoadConfig(ctx context.Context, workingDirectory string) (*Config, error) {
 	var err error
 
 	out := viper.GetString("PROJECT_CONFIG_FILE")
 	if out != constants.DEFAULT_PROJECT_CONFIG_FILE {
-		fmt.Println("Config file is ", out)
+		fmt.Fprintln(messageOutput(), "Config file is ", out)
 	}
 
 	configFile := filepath.Join(workingDirectory, viper.GetString("PROJECT_CONFIG_FILE"))
@@ -535,7 +567,7 @@ func RunBrisk(ctx context.Context) error {
 	defer cleanup()
 
 	firstRunMessage()
-	fmt.Println()
+	fmt.Fprintln(messageOutput())
 	var err error
 	setupUUID(ctx)
 	if err != nil {
@@ -558,23 +590,13 @@ func RunBrisk(ctx context.Context) error {
 		return err
 
 	}
-	var display OutputWriter
-	var status *ScreenSect
-	// if no terminal
-	if viper.GetBool("NO_TERM") || !term.IsTerminal(os.Stdout.Fd()) {
-
-		Logger(ctx).Debug("Not a terminal")
-		display = &TerminalWriter{Output: os.Stdout}
-		status = &ScreenSect{}
-	} else {
-		Logger(ctx).Debug("Is a terminal")
-
-		display = setupDisplay()
-		// scroll := display.Screens[0]
-		status = display.GetStatusScreen()
-
-		status.Set("⚡ Initializing")
+	events, closeEvents, err := setupEventOutput(ctx)
+	if err != nil {
+		return err
 	}
+	defer closeEvents()
+
+	display, status, humanOutput := setupHumanOutput(ctx, events)
 	Logger(ctx).Infof("Brisk CLI version:  %s", constants.VERSION)
 	Logger(ctx).Debug(config)
 
@@ -603,19 +625,19 @@ func RunBrisk(ctx context.Context) error {
 	Logger(ctx).Debug(config)
 	listData = append(listData, "starting...")
 
-	go printOutputLoop(ctx, config, logOuputChannel, listData, display, status)
+	go printOutputLoop(ctx, config, logOuputChannel, listData, display, status, events)
 
-	fmt.Print("connecting to brisk...")
+	fmt.Fprint(humanOutput, "connecting to brisk...")
 	super, err := getSuperForProject(ctx, config.ProjectToken, uniqueInstanceId, *config)
-	fmt.Print("connected \n")
+	fmt.Fprint(humanOutput, "connected \n")
 	if err != nil {
 		Logger(ctx).Errorf("Error getting supervisor %v", err)
 
 		if errors.Is(err, context.Canceled) {
-			fmt.Println("\n Could not connect to api when getting supervisor - context canceled")
+			fmt.Fprintln(messageOutput(), "\n Could not connect to api when getting supervisor - context canceled")
 		}
 		if errors.Is(err, context.DeadlineExceeded) {
-			fmt.Println("\n Timeout - Could not connect to api when getting supervisor - deadline exceeded")
+			fmt.Fprintln(messageOutput(), "\n Timeout - Could not connect to api when getting supervisor - deadline exceeded")
 		}
 
 		return err
@@ -666,8 +688,8 @@ func RunBrisk(ctx context.Context) error {
 			if errors.Is(err, ProjectInUseError) {
 				Logger(ctx).Info(err)
 				status.Set("")
-				fmt.Println("Project is already in use - waiting.")
-				fmt.Println("")
+				fmt.Fprintln(messageOutput(), "Project is already in use - waiting.")
+				fmt.Fprintln(messageOutput(), "")
 
 				timeout := viper.GetInt("PROJECT_IN_USE_TIMEOUT")
 				retryCount = 0
@@ -676,7 +698,7 @@ func RunBrisk(ctx context.Context) error {
 			} else if retryCount > viper.GetInt("RETRY_COUNT") {
 				Logger(ctx).Info(err)
 				status.Set("")
-				fmt.Println("Too many errors - exiting")
+				fmt.Fprintln(messageOutput(), "Too many errors - exiting")
 				forSpan.End()
 				cancelChan <- "too many errors - exiting"
 				return
@@ -686,12 +708,12 @@ func RunBrisk(ctx context.Context) error {
 				status.Set("")
 				retryCount++
 				Logger(ctx).Infof("Timeout - retrying in %v seconds", (time.Duration(retryCount+1) * timeout))
-				fmt.Printf("Timeout - retrying in %v seconds \n", (time.Duration(retryCount+1) * timeout))
+				fmt.Fprintf(messageOutput(), "Timeout - retrying in %v seconds \n", (time.Duration(retryCount+1) * timeout))
 				time.Sleep(time.Duration(retryCount+1) * timeout)
 			} else if grpc_status.Convert(err).Code() == codes.Unavailable || grpc_status.Convert(err).Code() == codes.NotFound || grpc_status.Convert(err).Code() == codes.Internal || grpc_status.Convert(err).Code() == codes.Unauthenticated || err == RSyncError || isGatewayError(err) {
 				Logger(ctx).Infof("The error is %v with code %v", err, grpc_status.Convert(err).Code())
 				Logger(ctx).Infof("Network Error - retrying in %v seconds", (time.Duration(retryCount+1) * timeout))
-				fmt.Printf("Network Error - retrying in %v seconds \n", (time.Duration(retryCount+1) * timeout))
+				fmt.Fprintf(messageOutput(), "Network Error - retrying in %v seconds \n", (time.Duration(retryCount+1) * timeout))
 				retryCount++
 				time.Sleep(time.Duration(retryCount+1) * timeout)
 			} else {
@@ -727,7 +749,7 @@ func RunBrisk(ctx context.Context) error {
 					})
 					if newSuperErr != nil {
 						Logger(ctx).Error("Can't connect to servers %v", newSuperErr)
-						fmt.Println("Can't connect to servers")
+						fmt.Fprintln(messageOutput(), "Can't connect to servers")
 
 						forSpan.End()
 						cancelChan <- "can't connect to servers - exiting"
@@ -810,7 +832,7 @@ func PrintContextInternals(ctx interface{}, inner bool) {
 	contextKeys := reflect.TypeOf(ctx).Elem()
 
 	if !inner {
-		fmt.Printf("\nFields for %s.%s\n", contextKeys.PkgPath(), contextKeys.Name())
+		fmt.Fprintf(messageOutput(), "\nFields for %s.%s\n", contextKeys.PkgPath(), contextKeys.Name())
 	}
 
 	if contextKeys.Kind() == reflect.Struct {
@@ -823,12 +845,12 @@ func PrintContextInternals(ctx interface{}, inner bool) {
 			if reflectField.Name == "Context" {
 				PrintContextInternals(reflectValue.Interface(), true)
 			} else {
-				fmt.Printf("field name: %+v\n", reflectField.Name)
-				fmt.Printf("value: %+v\n", reflectValue.Interface())
+				fmt.Fprintf(messageOutput(), "field name: %+v\n", reflectField.Name)
+				fmt.Fprintf(messageOutput(), "value: %+v\n", reflectValue.Interface())
 			}
 		}
 	} else {
-		fmt.Printf("context is empty (int)\n")
+		fmt.Fprintf(messageOutput(), "context is empty (int)\n")
 	}
 
 }
@@ -855,7 +877,7 @@ func setupKeys(ctx context.Context, config Config, authCreds auth.AuthCreds, c p
 	if err != nil {
 		Logger(ctx).Infof("Error when calling Setup: %v", err)
 
-		fmt.Println(".")
+		fmt.Fprintln(messageOutput(), ".")
 		st, ok := status.FromError(err)
 		if !ok {
 			Logger(ctx).Error("NOT OK")
@@ -883,7 +905,7 @@ func setupKeys(ctx context.Context, config Config, authCreds auth.AuthCreds, c p
 	privateKey := response.Message
 	if len(privateKey) == 0 {
 		Logger(ctx).Error("No private key returned")
-		fmt.Println("Internal Error: No private key returned from server - please contact support")
+		fmt.Fprintln(messageOutput(), "Internal Error: No private key returned from server - please contact support")
 		os.Exit(1)
 	}
 
@@ -901,7 +923,7 @@ func grpcConfigFromConfig(ctx context.Context, config Config) *pb.Config {
 	for _, c := range config.BuildCommands {
 		buildCommands = append(buildCommands, &api.Command{Commandline: c.Commandline, WorkDirectory: c.WorkDirectory, Args: c.Args, Background: c.Background})
 	}
-	out := pb.Config{AutomaticSplitting: config.AutomaticSplitting, NoFailFast: config.NoFailFast, SkipRecalcFiles: config.SkipRecalcFiles, ListTestCommand: config.ListTestCommand, PreListTestCommand: config.PreListTestCommand, BuildCommands: buildCommands, Framework: config.Framework, ExcludedFromSync: config.ExcludedFromSync, WorkerImage: config.WorkerImage, Environment: config.Environment, Concurrency: int32(config.Concurrency), SplitByJUnit: config.SplitByJUnit, OrderedFiles: config.OrderedFiles, RebuildFilePaths: config.RebuildFilePaths}
+	out := pb.Config{ExampleSplitThreshold: int32(config.ExampleSplitThreshold), SplitAlgorithm: config.SplitAlgorithm, DynamicSplitting: config.DynamicSplitting, BatchSize: int32(config.BatchSize), Retries: int32(config.Retries), AutomaticSplitting: config.AutomaticSplitting, NoFailFast: config.NoFailFast, SkipRecalcFiles: config.SkipRecalcFiles, ListTestCommand: config.ListTestCommand, PreListTestCommand: config.PreListTestCommand, BuildCommands: buildCommands, Framework: config.Framework, ExcludedFromSync: config.ExcludedFromSync, WorkerImage: config.WorkerImage, Environment: config.Environment, Concurrency: int32(config.Concurrency), SplitByJUnit: config.SplitByJUnit, OrderedFiles: config.OrderedFiles, RebuildFilePaths: config.RebuildFilePaths}
 
 	return &out
 
@@ -937,8 +959,8 @@ func prepareOutgoingContext(ctx context.Context, projectToken string, authCreds
 
 	traceKey := GetKey(ctx, projectToken)
 	if viper.GetBool("PRINT_TRACE_KEY") {
-		fmt.Println("")
-		fmt.Println(traceKey)
+		fmt.Fprintln(messageOutput(), "")
+		fmt.Fprintln(messageOutput(), traceKey)
 	}
 	ctx = metadata.AppendToOutgoingContext(ctx, "trace-key", traceKey)
 	ctx = WithTraceId(ctx, traceKey)
@@ -955,7 +977,34 @@ func prepareOutgoingContext(ctx context.Context, projectToken string, authCreds
 }
 
 func apiCommandFromConfigCommand(command Command, env map[string]string, framework string) *api.Command {
-	return &api.Command{Environment: env, TestFramework: framework, Commandline: command.Commandline, WorkDirectory: command.WorkDirectory, Args: command.Args, Background: command.Background, CommandConcurrency: int32(command.CommandConcurrency), CommandId: command.CommandId, NoTestFiles: command.NoTestFiles}
+	return &api.Command{Environment: env, TestFramework: framework, Commandline: command.Commandline, WorkDirectory: command.WorkDirectory, Args: command.Args, Background: command.Background, CommandConcurrency: int32(command.CommandConcurrency), CommandId: command.CommandId, NoTestFiles: command.NoTestFiles, Retries: int32(command.Retries), DependsOn: command.DependsOn, Timeout: timeoutFromConfigCommand(command), Limits: limitsFromConfigCommand(command), ReadyCheck: readyCheckFromConfigCommand(command)}
+}
+
+// the config has been validated by now so bad limits are just left out
+func limitsFromConfigCommand(command Command) *api.ResourceLimits {
+	limits, err := command.Limits.Proto()
+	if err != nil {
+		return nil
+	}
+	return limits
+}
+
+// the config has been validated by now so a bad timeout is just left out
+func timeoutFromConfigCommand(command Command) *durationpb.Duration {
+	timeout, err := command.TimeoutDuration()
+	if err != nil || timeout == 0 {
+		return nil
+	}
+	return durationpb.New(timeout)
+}
+
+// the config has been validated by now so a bad readyCheck is just left out
+func readyCheckFromConfigCommand(command Command) *api.ReadyCheck {
+	check, err := command.ReadyCheck.Proto()
+	if err != nil {
+		return nil
+	}
+	return check
 }
 
 func parseCommandsFromConfig(ctx context.Context, config Config) ([]*api.Command, []*api.Command) {
@@ -963,17 +1012,23 @@ func parseCommandsFromConfig(ctx context.Context, config Config) ([]*api.Command
 	var commands []*api.Command
 	env := config.Environment
 	for _, v := range config.BuildCommands {
-		buildCommands = append(buildCommands, apiCommandFromConfigCommand(v, env, config.Framework))
+		command := apiCommandFromConfigCommand(v, env, config.Framework)
+		command.ResetCommands = config.ResetCommands
+		buildCommands = append(buildCommands, command)
 	}
 	Logger(ctx).Debugf("Build commands are %v", buildCommands)
 	for _, v := range config.Commands {
-		commands = append(commands, apiCommandFromConfigCommand(v, env, config.Framework))
+		command := apiCommandFromConfigCommand(v, env, config.Framework)
+		command.Artifacts = config.Artifacts
+		command.CoverageFile = config.CoverageFile
+		command.ResetCommands = config.ResetCommands
+		commands = append(commands, command)
 	}
 	Logger(ctx).Debugf("Test Commands are %v", commands)
 	return buildCommands, commands
 }
 
-func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syncHost string, workingDirectory string, outputChan chan *pb.Output, authCreds auth.AuthCreds, sync bool, repoInfo *api.RepoInfo) (time.Time, error) {
+func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syncHost string, workingDirectory string, outputChan chan *pb.Output, authCreds auth.AuthCreds, sync bool, repoInfo *api.RepoInfo, onlyFailed bool) (time.Time, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	startTime := time.Now() // we reset this later but we need something for errors
 
@@ -1000,10 +1055,18 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 	if err != nil {
 		return startTime, err
 	}
+	if onlyFailed {
+		for _, command := range commands {
+			if err := CheckOnlyFiles(config.Framework, command); err != nil {
+				outputChan <- &pb.Output{Response: err.Error(), Stderr: err.Error(), Created: timestamppb.Now()}
+				return startTime, err
+			}
+		}
+	}
 
 	outputChan <- &pb.Output{Response: "Waiting on project supervisor...", Created: timestamppb.Now()}
 	Logger(ctx).Debugf("Calling lock on super")
-	fmt.Print("Acquiring lock on supervisor...")
+	fmt.Fprint(messageOutput(), "Acquiring lock on supervisor...")
 	lockClient, lockErr := c.Lock(ctx, &pb.LockRequest{})
 	defer lockClient.CloseSend()
 	if lockErr != nil {
@@ -1075,6 +1138,21 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 	}
 	Logger(ctx).Debugf("Build commands I'm sending are %+v ", buildCommands)
 	conf := grpcConfigFromConfig(ctx, *config)
+	if onlyFailed {
+		failedFiles, failedErr := utilities.LoadFailedFiles(ctx, projectToken)
+		if failedErr != nil {
+			Logger(ctx).Errorf("Error loading failed files %v", failedErr)
+		}
+		if len(failedFiles) > 0 {
+			// jest reports the absolute path on the worker and rspec a ./ one, the files are sent relative to the repo
+			for _, f := range failedFiles {
+				conf.OnlyFiles = append(conf.OnlyFiles, RepoRelativeFile(f, constants.DEFAULT_REMOTE_DIR))
+			}
+			outputChan <- &pb.Output{Response: fmt.Sprintf("Running only the %v test files that failed last run", len(failedFiles)), Created: timestamppb.Now()}
+		} else {
+			outputChan <- &pb.Output{Response: "No failed test files recorded - running all tests", Created: timestamppb.Now()}
+		}
+	}
 	to := pb.TestOption{Command: "runn the tests", BuildCommands: buildCommands, Commands: commands, Config: conf, RepoInfo: repoInfo}
 	Logger(ctx).Debugf("The config I'm sending is %+v", to.Config)
 	Logger(ctx).Debugf("The environment I'm sending is %+v", to.Config.Environment)
@@ -1089,6 +1167,13 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 	Logger(ctx).Debugf("TIMING Starting test run at %v", time.Since(
 		startTime))
 	Logger(ctx).Debugf("the parameters to RunTests are %#v ", &to)
+	testResults := NewJunitReport()
+	defer writeJunitReport(ctx, *config, testResults, outputChan)
+	artifacts := &ArtifactWriter{Dir: filepath.Join(workingDirectory, ArtifactsDirectory, time.Now().Format("20060102-150405"))}
+	defer reportArtifacts(artifacts, outputChan)
+	coverage := NewCoverageReport()
+	defer writeCoverageReport(ctx, *config, coverage, artifacts.Dir, outputChan)
+
 	outputChan <- &pb.Output{Response: "Starting test run...", Created: timestamppb.Now()}
 	r, err := c.RunTests(ctx, &to)
 	defer r.CloseSend()
@@ -1098,12 +1183,40 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 		Logger(ctx).Debug(err)
 		return startTime, err
 	}
+	recorder := startRecording(ctx, *config, outputChan)
+	if recorder != nil {
+		defer recorder.Close()
+	}
 
 	for {
 		Logger(ctx).Debug("Before receive")
 
 		in, err := r.Recv()
+		if in != nil && recorder != nil {
+			if recordErr := recorder.Record(in); recordErr != nil {
+				Logger(ctx).Errorf("Error recording output %v", recordErr)
+			}
+		}
+		if in != nil && in.RunId != "" {
+			endpoint := logServiceEndpoint(super.ExternalEndpoint)
+			if saveErr := utilities.SaveLastRun(ctx, projectToken, utilities.LastRun{RunId: in.RunId, Endpoint: endpoint, LogEncryptionKey: in.LogEncryptionKey}); saveErr != nil {
+				Logger(ctx).Errorf("Error saving the run %v", saveErr)
+			}
+			outputChan <- &pb.Output{Response: fmt.Sprintf("Run %v - follow it with brisk attach, or from another machine with brisk attach %v --super %v", in.RunId, in.RunId, endpoint), Created: timestamppb.Now()}
+			continue
+		}
+		if in != nil && in.Artifact != nil {
+			if writeErr := artifacts.Write(in); writeErr != nil {
+				Logger(ctx).Errorf("Error writing artifact %v %v", in.Artifact.Path, writeErr)
+				outputChan <- &pb.Output{Stderr: fmt.Sprintf("Error writing artifact %v: %v", in.Artifact.Path, writeErr), Created: timestamppb.Now()}
+			}
+			continue
+		}
+		if in != nil && in.Coverage != nil {
+			coverage.Merge(CoverageFromProto(in.Coverage))
+		}
 		if in != nil {
+			testResults.AddOutput(ctx, config.Framework, in)
 			outputChan <- in
 			Logger(ctx).Debugf(" %+v", in)
 		}
@@ -1114,6 +1227,9 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 		if in != nil && in.Control == types.FAILED {
 			Logger(ctx).Debugf("Got Failed from server %+v", in)
 			Logger(ctx).Debugf("Exit code is %v", in.Exitcode)
+			if len(testResults.FailedFiles()) > 0 {
+				saveFailedFiles(ctx, projectToken, testResults)
+			}
 			return startTime, TestFailedError
 
 		}
@@ -1131,6 +1247,7 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 					Logger(ctx).Debugf("Recv'd done %v", err)
 					Logger(ctx).Debugf("Returning from single test run with : %v", err)
 					outputChan <- &pb.Output{Response: "Finished Run", Created: timestamppb.Now()}
+					saveFailedFiles(ctx, projectToken, testResults)
 
 					return startTime, nil
 				}
@@ -1146,7 +1263,7 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 			default:
 				{
 					if status.Code(err) == codes.Unavailable {
-						fmt.Print("server is unavailable")
+						fmt.Fprint(messageOutput(), "server is unavailable")
 						Logger(ctx).Debugf("Server is unavailable %v", err)
 
 						return startTime, err
@@ -1183,6 +1300,92 @@ func singleTestRun(ctx context.Context, super *api.Super, privateKey string, syn
 	}
 }
 
+// junitOutputPath gives the --junit-out flag precedence over junitOutput in brisk.json
+func junitOutputPath(config Config) string {
+	if path := viper.GetString("JUNIT_OUT"); path != "" {
+		return path
+	}
+	return config.JunitOutput
+}
+
+// writeJunitReport writes the results collected during the run if a JUnit output is configured
+func writeJunitReport(ctx context.Context, config Config, report *JunitReport, outputChan chan *pb.Output) {
+	path := junitOutputPath(config)
+	if path == "" {
+		return
+	}
+	err := report.WriteFile(ctx, path)
+	if err != nil {
+		Logger(ctx).Errorf("Error writing JUnit report to %v: %v", path, err)
+		outputChan <- &pb.Output{Stderr: fmt.Sprintf("Error writing JUnit report to %v: %v", path, err), Created: timestamppb.Now()}
+		return
+	}
+	outputChan <- &pb.Output{Response: fmt.Sprintf("JUnit report written to %v", path), Created: timestamppb.Now()}
+}
+
+// startRecording opens the --record file, each run in watch mode replaces the one before
+func startRecording(ctx context.Context, config Config, outputChan chan *pb.Output) *Recorder {
+	path := viper.GetString("RECORD")
+	if path == "" {
+		return nil
+	}
+	header := &pb.RecordingHeader{Version: constants.VERSION, Started: timestamppb.Now(), Framework: config.Framework, FilterList: config.FilterList}
+	for _, command := range config.Commands {
+		header.CommandIds = append(header.CommandIds, command.CommandId)
+	}
+	recorder, err := NewRecorder(path, header)
+	if err != nil {
+		Logger(ctx).Errorf("Error recording the run to %v: %v", path, err)
+		outputChan <- &pb.Output{Stderr: fmt.Sprintf("Error recording the run to %v: %v", path, err), Created: timestamppb.Now()}
+		return nil
+	}
+	outputChan <- &pb.Output{Response: fmt.Sprintf("Recording the run to %v", path), Created: timestamppb.Now()}
+	return recorder
+}
+
+func reportArtifacts(artifacts *ArtifactWriter, outputChan chan *pb.Output) {
+	if len(artifacts.Files) == 0 {
+		return
+	}
+	outputChan <- &pb.Output{Response: fmt.Sprintf("%v artifacts saved to %v", len(artifacts.Files), artifacts.Dir), Created: timestamppb.Now()}
+}
+
+// writeCoverageReport writes the coverage merged from all of the workers as lcov, to coverageOutput or with the artifacts of the run
+func writeCoverageReport(ctx context.Context, config Config, coverage *CoverageReport, artifactsDir string, outputChan chan *pb.Output) {
+	if len(coverage.Files) == 0 {
+		return
+	}
+	path := config.CoverageOutput
+	if path == "" {
+		path = filepath.Join(artifactsDir, "coverage.info")
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		var f *os.File
+		f, err = os.Create(path)
+		if err == nil {
+			err = coverage.WriteLcov(f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		Logger(ctx).Errorf("Error writing coverage report to %v: %v", path, err)
+		outputChan <- &pb.Output{Stderr: fmt.Sprintf("Error writing coverage report to %v: %v", path, err), Created: timestamppb.Now()}
+		return
+	}
+	outputChan <- &pb.Output{Response: fmt.Sprintf("Coverage for the run %v - report written to %v", coverage.Totals(), path), Created: timestamppb.Now()}
+}
+
+// saveFailedFiles remembers which files failed so the next --only-failed run can send just those
+func saveFailedFiles(ctx context.Context, projectToken string, report *JunitReport) {
+	err := utilities.SaveFailedFiles(ctx, projectToken, report.FailedFiles())
+	if err != nil {
+		Logger(ctx).Errorf("Error saving failed files %v", err)
+	}
+}
+
 func firstRunMessage() {
 	if (viper.GetTime("FIRST_RUN_AT") != time.Time{}) {
 		return
@@ -1191,7 +1394,7 @@ func firstRunMessage() {
 	if viper.GetBool("CI") {
 		return
 	}
-	fmt.Println(`
+	fmt.Fprintln(messageOutput(), `
 	
 Welcome to Brisk 👋👋👋
 	
@@ -1214,7 +1417,7 @@ Sean
 	viper.Set("FIRST_RUN_AT", time.Now())
 	err := viper.WriteConfig()
 	if err != nil {
-		fmt.Println("Error writing config")
+		fmt.Fprintln(messageOutput(), "Error writing config")
 	}
 }
 
@@ -1232,7 +1435,7 @@ func checkBuildCommands(ctx context.Context, buildCommands []*api.Command, outpu
 		viper.Set("BuildCommandHash", buildCommandHash)
 		err = viper.WriteConfig()
 		if err != nil {
-			fmt.Println("Error writing config")
+			fmt.Fprintln(messageOutput(), "Error writing config")
 			Logger(ctx).Errorf("Error writing config %v", err)
 			return err
 		}
@@ -1253,12 +1456,73 @@ func hash(s string) uint8 {
 	return uint8(u32)
 }
 
-func printOutputLoop(ctx context.Context, config *Config, logOuputChannel chan *pb.Output, listData []string, display OutputWriter, status *ScreenSect) {
+// setupEventOutput returns a writer for the --output=json event stream, or nil when we are just printing text
+func setupEventOutput(ctx context.Context) (*JsonEventWriter, func(), error) {
+	switch viper.GetString("OUTPUT") {
+	case "", "text":
+		return nil, func() {}, nil
+	case "json":
+	default:
+		return nil, nil, fmt.Errorf("unknown output format %v - use text or json", viper.GetString("OUTPUT"))
+	}
+
+	outputFile := viper.GetString("OUTPUT_FILE")
+	if outputFile == "" || outputFile == "-" {
+		return &JsonEventWriter{Output: os.Stdout}, func() {}, nil
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		Logger(ctx).Errorf("Error creating output file %v", err)
+		return nil, nil, err
+	}
+	Logger(ctx).Debugf("Writing json events to %v", outputFile)
+	return &JsonEventWriter{Output: f}, func() { f.Close() }, nil
+}
+
+// pipelineStatus shows each command with its stage status when the commands have a dependsOn
+func pipelineStatus(config *Config, stages map[string]string) string {
+	var parts []string
+	for _, command := range config.Commands {
+		if command.CommandId == "" {
+			continue
+		}
+		stageStatus := stages[command.CommandId]
+		if stageStatus == "" {
+			stageStatus = "waiting"
+		}
+		parts = append(parts, fmt.Sprintf("%v [%v]", strings.ToUpper(command.CommandId), strings.ToUpper(stageStatus)))
+	}
+	return "STAGES: " + strings.Join(parts, "     ")
+}
+
+func hasPipeline(config *Config) bool {
+	for _, command := range config.Commands {
+		if len(command.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func printOutputLoop(ctx context.Context, config *Config, logOuputChannel chan *pb.Output, listData []string, display OutputWriter, status *ScreenSect, events *JsonEventWriter) {
+	stages := map[string]string{}
 
 	for {
 
 		select {
 		case output := <-logOuputChannel:
+			if output == nil {
+				// sent to wait for the outputs before it to be shown
+				break
+			}
+			if events != nil {
+				if err := events.WriteEvent(output); err != nil {
+					Logger(ctx).Errorf("Error writing json event %v", err)
+				}
+			}
+			if output.StageStatus != "" && output.Command != nil {
+				stages[output.Command.CommandId] = output.StageStatus
+			}
 			var outputPrefix string
 			if len(config.Commands) > 1 && output.Command != nil && output.Command.CommandId != "" {
 				commandId := strings.ToUpper(output.Command.CommandId)
@@ -1324,8 +1588,14 @@ func printOutputLoop(ctx context.Context, config *Config, logOuputChannel chan *
 
 			}
 
-			if config.Framework == string(types.Jest) && len(config.Commands) == 1 {
-				status.Set(fmt.Sprintf("TESTS: [%v]     PASSED [%v]", output.TotalTestCount, output.TotalTestPass))
+			if (config.Framework == string(types.Jest) || config.Framework == string(types.Go)) && len(config.Commands) == 1 {
+				if output.TotalTestFlaky > 0 {
+					status.Set(fmt.Sprintf("TESTS: [%v]     PASSED [%v]     FLAKY [%v]", output.TotalTestCount, output.TotalTestPass, output.TotalTestFlaky))
+				} else {
+					status.Set(fmt.Sprintf("TESTS: [%v]     PASSED [%v]", output.TotalTestCount, output.TotalTestPass))
+				}
+			} else if hasPipeline(config) {
+				status.Set(pipelineStatus(config, stages))
 			} else {
 				status.Set("")
 			}
@@ -1347,7 +1617,7 @@ func watchFiles(ctx context.Context, workingDirectory string, logOuputChannel ch
 
 }
 
-var usageString = `Usage: "r" to run, "q" to quit, "x" to clear workers`
+var usageString = `Usage: "r" to run, "f" to run only failed tests, "q" to quit, "x" to clear workers`
 
 const fsWatchDelay = 10
 
@@ -1361,12 +1631,12 @@ func catchSignals(ctx context.Context, cancelChan chan string, cleanup func()) {
 	go func() {
 
 		time.Sleep(10 * time.Second)
-		fmt.Println()
-		fmt.Println("Exiting")
+		fmt.Fprintln(messageOutput())
+		fmt.Fprintln(messageOutput(), "Exiting")
 
 		os.Exit(1)
 	}()
-	fmt.Printf("Signal received %v, exiting \n", sig)
+	fmt.Fprintf(messageOutput(), "Signal received %v, exiting \n", sig)
 	Logger(ctx).Infof("Got interrupt %v, exiting", sig)
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(attribute.String("signal", sig.String()))
@@ -0,0 +1,64 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"brisk-supervisor/brisk-cli/cli_utils"
+	"brisk-supervisor/brisk-cli/projects"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+)
+
+// goCmd represents the go command
+var goCmd = &cobra.Command{
+	Use:   "go",
+	Short: "Create a new Go project and inits a config file in the current directory",
+	Long: `This command creates a new Go project and inits a config file in the current directory.
+
+It creates a default brisk.json file which you can edit with specific project settings.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		ctx, span := otel.Tracer(name).Start(ctx, "Go")
+		defer span.End()
+		ctx, err := cli_utils.CliAddAuthToCtx(ctx)
+		if err != nil {
+			return err
+		}
+		ctx, err = cli_utils.CliAddTraceKeyToCtx(ctx, "")
+		if err != nil {
+			return err
+		}
+
+		if projects.CheckForBriskJsonFile(ctx, viper.GetString("PROJECT_CONFIG_FILE")) {
+			return fmt.Errorf("project already initialized - %v already exists in the current directory", viper.GetString("PROJECT_CONFIG_FILE"))
+
+		}
+		fmt.Println("Creating a new Go project...")
+
+		err = projects.CreateGoProject(ctx)
+
+		if err != nil {
+			return err
+		}
+		fmt.Printf("New Go project created successfully. See %v for more information. \n", viper.GetString("PROJECT_CONFIG_FILE"))
+		return nil
+	},
+}
+
+func init() {
+	initCmd.AddCommand(goCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// goCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// goCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
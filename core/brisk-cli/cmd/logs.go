@@ -0,0 +1,251 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	"brisk-supervisor/shared/logcrypt"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs [run-id]",
+	Short: "Show the logs of a run",
+	Long: `Logs prints the logs the supervisor stored when a run finished, the logs of each worker merged in the order they were output. Without a run id it is the last run started from this machine.
+With --follow it shows a run that is still going as it happens.
+Runs started from this machine are found by their id. To get the logs of a teammate's run pass the run id and supervisor they were given, and the key of the run as logs are stored encrypted.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		var runId string
+		if len(args) > 0 {
+			runId = args[0]
+		}
+		endpoint, err := cmd.Flags().GetString("super")
+		if err != nil {
+			return err
+		}
+		key, err := cmd.Flags().GetString("key")
+		if err != nil {
+			return err
+		}
+		worker, err := cmd.Flags().GetInt32("worker")
+		if err != nil {
+			return err
+		}
+		commandId, err := cmd.Flags().GetString("command")
+		if err != nil {
+			return err
+		}
+		pattern, err := cmd.Flags().GetString("grep")
+		if err != nil {
+			return err
+		}
+		follow, err := cmd.Flags().GetBool("follow")
+		if err != nil {
+			return err
+		}
+
+		filter := LogFilter{Worker: worker, CommandId: commandId}
+		if pattern != "" {
+			filter.Grep, err = regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("--grep is not a valid pattern: %w", err)
+			}
+		}
+		return ShowLogs(cmd.Context(), runId, endpoint, key, filter, follow, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().String("super", "", "the log service of the supervisor that ran it, printed at the start of the run")
+	logsCmd.Flags().String("key", "", "the key the logs of the run are encrypted with when it wasn't started from here, it is saved on the machine that started the run")
+	logsCmd.Flags().Int32("worker", -1, "only show the lines of this worker, numbered from 0")
+	logsCmd.Flags().String("command", "", "only show the lines of the command with this commandId")
+	logsCmd.Flags().String("grep", "", "only show the lines matching this regular expression")
+	logsCmd.Flags().BoolP("follow", "f", false, "show the run as it happens if it is still going")
+}
+
+// logChunkReader reads the chunks of a stored log as they arrive
+type logChunkReader struct {
+	stream pb.LogStream_GetLogClient
+	data   []byte
+}
+
+func (r *logChunkReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = chunk.Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// ShowLogs writes the lines of the run that pass the filter to w. With follow a run still on the supervisor is shown
+// as it happens, otherwise the stored logs are decrypted with the key saved for the run or the one given when the run
+// wasn't started from here.
+func ShowLogs(ctx context.Context, runId string, endpoint string, key string, filter LogFilter, follow bool, w io.Writer) error {
+	ctx, span := otel.Tracer(name).Start(ctx, "ShowLogs")
+	defer span.End()
+
+	ctx, logs, err := connectToLogService(ctx, runId, endpoint)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	if logs.logEncryptionKey != "" {
+		key = logs.logEncryptionKey
+	}
+
+	if follow {
+		err := followLog(ctx, logs, filter, w)
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+		// the run has gone from the supervisor so its logs have been stored
+	}
+
+	lines, err := storedLogLines(ctx, logs, key, filter)
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%v - use --follow to see a run that is still going", status.Convert(err).Message())
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		return errors.New(status.Convert(err).Message())
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if filter.Matches(line) {
+			if _, err := fmt.Fprintln(w, line.Line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// followLog prints the run as it happens from the start, it is NotFound once the run has gone from the supervisor
+func followLog(ctx context.Context, logs *logService, filter LogFilter, w io.Writer) error {
+	stream, err := logs.Attach(ctx, &pb.AttachRequest{RunId: logs.runId})
+	if err != nil {
+		return err
+	}
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, line := range OutputLogLines(in) {
+			if filter.Matches(line) {
+				if _, err := fmt.Fprintln(w, line.Line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// storedLogLines merges the logs of the workers the filter wants with the lines from the supervisor, runs stored
+// before there were worker logs only have the log of the run
+func storedLogLines(ctx context.Context, logs *logService, key string, filter LogFilter) ([]LogLine, error) {
+	index, err := fetchLog(ctx, logs, WorkerLogIndexUid(logs.runId), key)
+	if status.Code(err) == codes.NotFound {
+		runLog, err := fetchLog(ctx, logs, logs.runId, key)
+		if err != nil {
+			return nil, err
+		}
+		return ReadLogLines(bytes.NewReader(runLog))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var workerLogs []WorkerLog
+	if err := json.Unmarshal(index, &workerLogs); err != nil {
+		return nil, fmt.Errorf("can't read the list of worker logs: %w", err)
+	}
+
+	var logLines [][]LogLine
+	if filter.FromWorker(-1, "") {
+		runLog, err := fetchLog(ctx, logs, logs.runId, key)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := ReadLogLines(bytes.NewReader(runLog))
+		if err != nil {
+			return nil, err
+		}
+		// the log of the run has the output of every worker too
+		var supervisorLines []LogLine
+		for _, line := range lines {
+			if line.Worker < 0 {
+				supervisorLines = append(supervisorLines, line)
+			}
+		}
+		logLines = append(logLines, supervisorLines)
+	}
+	for _, workerLog := range workerLogs {
+		if !filter.FromWorker(workerLog.Worker, workerLog.CommandId) {
+			continue
+		}
+		log, err := fetchLog(ctx, logs, workerLog.Uid, key)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := ReadLogLines(bytes.NewReader(log))
+		if err != nil {
+			return nil, err
+		}
+		logLines = append(logLines, lines)
+	}
+	return MergeLogLines(logLines...), nil
+}
+
+// fetchLog downloads a stored log and decrypts it, logs stored before they were encrypted are given as they are
+func fetchLog(ctx context.Context, logs *logService, uid string, key string) ([]byte, error) {
+	stream, err := logs.GetLog(ctx, &pb.GetLogRequest{Uid: uid})
+	if err != nil {
+		return nil, err
+	}
+	log := bufio.NewReader(&logChunkReader{stream: stream})
+	// a log shorter than the start of an encrypted one is plain text, any other error is from the stream
+	start, err := log.Peek(64)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var content io.Reader = log
+	if logcrypt.IsEncryptedLog(start) {
+		if key == "" {
+			return nil, errors.New("the logs are encrypted, pass the key of the run with --key, it is saved on the machine that started the run")
+		}
+		content, err = logcrypt.NewLogDecrypter(log, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(content)
+}
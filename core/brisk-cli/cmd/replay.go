@@ -0,0 +1,125 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay [file]",
+	Short: "Play back a run recorded with --record",
+	Long: `Replay shows a run recorded with brisk --record as it was shown at the time, without connecting to brisk.
+The outputs are played back with their original timing, --speed 10 plays them ten times faster and --speed 0 as fast as possible.
+--junit-out and --output json work the same as they do for a run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		speed, err := cmd.Flags().GetFloat64("speed")
+		if err != nil {
+			return err
+		}
+		if speed < 0 {
+			return errors.New("--speed can't be negative")
+		}
+		return ReplayRun(cmd.Context(), args[0], speed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().Float64("speed", 1, "how many times faster than the original to play it back, 0 for no waiting")
+}
+
+// configFromRecording is the part of brisk.json the recording kept
+func configFromRecording(header *pb.RecordingHeader) Config {
+	config := Config{Framework: header.Framework, FilterList: header.FilterList}
+	for _, commandId := range header.CommandIds {
+		config.Commands = append(config.Commands, Command{CommandId: commandId})
+	}
+	return config
+}
+
+// ReplayRun feeds a recording through the same output as a run, it fails if the recorded run did
+func ReplayRun(ctx context.Context, path string, speed float64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ctx, span := otel.Tracer(name).Start(ctx, "ReplayRun")
+	defer span.End()
+
+	recording, err := OpenRecording(path)
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+	config := configFromRecording(recording.Header)
+	Logger(ctx).Debugf("Replaying %v recorded by version %v", path, recording.Header.Version)
+
+	events, closeEvents, err := setupEventOutput(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
+	display, status, _ := setupHumanOutput(ctx, events)
+
+	// the outputs are unbuffered so once one is taken the one before it has been shown
+	outputChan := make(chan *pb.Output)
+	go printOutputLoop(ctx, &config, outputChan, nil, display, status, events)
+	outputChan <- &pb.Output{Response: fmt.Sprintf("Replaying the run recorded at %v", recording.Header.Started.AsTime().Local().Format("2006-01-02 15:04:05")), Created: recording.Header.Started}
+
+	testResults := NewJunitReport()
+	failed := false
+	start := time.Now()
+	for {
+		recorded, err := recording.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			outputChan <- &pb.Output{Stderr: "The recording stops part way through, the CLI was probably killed", Created: timestamppb.Now()}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if speed > 0 {
+			wait := time.Duration(float64(recorded.Offset.AsDuration())/speed) - time.Since(start)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		in := recorded.Output
+		// the run id is for attaching while it runs and the artifacts were saved at the time
+		if in == nil || in.RunId != "" || in.Artifact != nil {
+			continue
+		}
+		testResults.AddOutput(ctx, config.Framework, in)
+		if in.Control == types.FAILED {
+			failed = true
+		}
+		outputChan <- in
+	}
+	writeJunitReport(ctx, config, testResults, outputChan)
+	// wait for the last output to be shown
+	outputChan <- nil
+	if failed {
+		return TestFailedError
+	}
+	return nil
+}
@@ -50,6 +50,11 @@ func initFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().StringP("config", "c", "brisk.json", "project config file")
 	rootCmd.PersistentFlags().StringP("credentials", "a", "$HOME/.config/brisk/config.toml", "brisk credentials file")
 	rootCmd.PersistentFlags().BoolP("watch", "w", true, "should brisk watch for local changes")
+	rootCmd.PersistentFlags().String("junit-out", "", "write an aggregated JUnit XML report of the run to this file")
+	rootCmd.PersistentFlags().Bool("only-failed", false, "only run the test files that failed in the previous run")
+	rootCmd.PersistentFlags().String("output", "text", "output format, text or json (one JSON object per line)")
+	rootCmd.PersistentFlags().String("output-file", "", "write the json output to this file instead of stdout")
+	rootCmd.PersistentFlags().String("record", "", "record the output of the run to this file, brisk replay plays it back")
 	// cfgFile := os.Getenv("BRISK_CONFIG")
 	err := viper.BindPFlag("PROJECT_CONFIG_FILE", rootCmd.PersistentFlags().Lookup("config"))
 	if err != nil {
@@ -66,6 +71,31 @@ func initFlags(rootCmd *cobra.Command) {
 		fmt.Println("Error binding watch flag")
 	}
 
+	err = viper.BindPFlag("JUNIT_OUT", rootCmd.PersistentFlags().Lookup("junit-out"))
+	if err != nil {
+		fmt.Println("Error binding junit-out flag")
+	}
+
+	err = viper.BindPFlag("ONLY_FAILED", rootCmd.PersistentFlags().Lookup("only-failed"))
+	if err != nil {
+		fmt.Println("Error binding only-failed flag")
+	}
+
+	err = viper.BindPFlag("OUTPUT", rootCmd.PersistentFlags().Lookup("output"))
+	if err != nil {
+		fmt.Println("Error binding output flag")
+	}
+
+	err = viper.BindPFlag("OUTPUT_FILE", rootCmd.PersistentFlags().Lookup("output-file"))
+	if err != nil {
+		fmt.Println("Error binding output-file flag")
+	}
+
+	err = viper.BindPFlag("RECORD", rootCmd.PersistentFlags().Lookup("record"))
+	if err != nil {
+		fmt.Println("Error binding record flag")
+	}
+
 }
 func init() {
 	initFlags(rootCmd)
@@ -53,21 +53,44 @@ func createJestProjectFile(ctx context.Context, projectToken string, concurrency
 	writeErr := config.WriteConfig(ctx)
 	return writeErr
 }
+
+// creates a shell brisk.json file for a go project, the tests are split by package
+func createGoProjectFile(ctx context.Context, projectToken string, concurrency int) error {
+
+	config := shared.Config{}
+	config.Framework = "Go"
+	config.WorkerImage = "go"
+
+	config.ExcludedFromSync = []string{"log/", ".git/", "vendor/"}
+	config.ExcludedFromWatch = []string{"log/", ".git/", "log", ".git", "vendor"}
+	config.ListTestCommand = shared.GoListTestCommand
+	config.Commands = []shared.Command{{Commandline: "go test"}}
+	config.BuildCommands = []shared.Command{{Commandline: "go mod download"}}
+	config.Environment = map[string]string{"MY_ENV": "empty"}
+	config.FilterList = []string{"SOME_FILTER"}
+	config.ProjectToken = projectToken
+	config.Concurrency = concurrency
+	config.SplitAlgorithm = shared.SplitTimingPartition
+
+	writeErr := config.WriteConfig(ctx)
+	return writeErr
+}
 func createPythonProjectFile(ctx context.Context, projectToken string, concurrency int) error {
 
 	config := shared.Config{}
 	config.Framework = "Python"
 	config.WorkerImage = "python"
 
-	config.ExcludedFromSync = []string{"log/", ".git/", "node_modules", ".rvm"}
-	config.ExcludedFromWatch = []string{"log/", ".git/", "log", ".git", "node_modules"}
-	config.ListTestCommand = "echo 'list tests'"
-	config.Commands = []shared.Command{{Commandline: "pytest --splits $BRISK_NODE_TOTAL --group $((BRISK_NODE_INDEX+1))"}}
-	config.BuildCommands = []shared.Command{{Commandline: ""}}
+	config.ExcludedFromSync = []string{"log/", ".git/", "node_modules", ".rvm", "__pycache__", ".venv"}
+	config.ExcludedFromWatch = []string{"log/", ".git/", "log", ".git", "node_modules", "__pycache__", ".venv"}
+	config.ListTestCommand = shared.PytestListTestCommand
+	config.Commands = []shared.Command{{Commandline: "pytest"}}
+	config.BuildCommands = []shared.Command{{Commandline: "pip install -r requirements.txt"}}
 	config.Environment = map[string]string{"MY_ENV": "empty"}
 	config.FilterList = []string{"SOME_FILTER"}
 	config.ProjectToken = projectToken
 	config.Concurrency = concurrency
+	config.SplitAlgorithm = shared.SplitTimingPartition
 
 	writeErr := config.WriteConfig(ctx)
 	return writeErr
@@ -218,3 +241,25 @@ func ListAll(ctx context.Context) ([]*api.Project, error) {
 	return resp.Projects, nil
 
 }
+
+func CreateGoProject(ctx context.Context) error {
+
+	endpoint := viper.GetString("ApiEndpoint")
+	conn, err := shared.ApiConn(ctx, endpoint)
+
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c := api.NewProjectsClient(conn)
+	resp, initErr := c.InitProject(ctx, &api.InitProjectReq{Framework: "Go"})
+
+	if initErr != nil {
+		return initErr
+	}
+	projectToken := resp.Project.ProjectToken
+	concurrency := resp.Project.Concurrency
+	err = createGoProjectFile(ctx, projectToken, int(concurrency))
+	return err
+}
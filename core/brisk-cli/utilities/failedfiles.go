@@ -0,0 +1,78 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utilities
+
+import (
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// the failed files from the last run are kept next to the credentials config, one file per project
+
+func failedFilesPath(projectToken string) (string, error) {
+	if projectToken == "" {
+		return "", errors.New("need a project token to store failed files")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, viper.GetString("CREDENTIALS_CONFIG"), "failed", projectToken+".json"), nil
+}
+
+// SaveFailedFiles records the test files that failed in the last run for this project, an empty list clears them
+func SaveFailedFiles(ctx context.Context, projectToken string, files []string) error {
+	path, err := failedFilesPath(projectToken)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if files == nil {
+		files = []string{}
+	}
+	jsonBytes, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+	Logger(ctx).Debugf("Saving %v failed files to %v", len(files), path)
+	return os.WriteFile(path, jsonBytes, 0600)
+}
+
+// LoadFailedFiles returns the test files that failed in the last run, or nothing if we have no record
+func LoadFailedFiles(ctx context.Context, projectToken string) ([]string, error) {
+	path, err := failedFilesPath(projectToken)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		Logger(ctx).Debugf("No failed files recorded at %v", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = json.Unmarshal(jsonBytes, &files)
+	return files, err
+}
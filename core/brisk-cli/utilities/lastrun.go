@@ -0,0 +1,151 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utilities
+
+import (
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// how many of the runs started from this machine are remembered for each project
+const savedRuns = 200
+
+// LastRun is the latest run started from this machine for a project, brisk attach follows it when it isn't given one.
+// The runs before it are kept too so brisk logs can find their supervisor and key from the run id.
+type LastRun struct {
+	RunId string `json:"runId"`
+	// Endpoint is the log service of the supervisor running it
+	Endpoint string `json:"endpoint"`
+	// LogEncryptionKey decrypts the stored logs of the run, only the machine that started it has it
+	LogEncryptionKey string `json:"logEncryptionKey,omitempty"`
+}
+
+func lastRunPath(projectToken string) (string, error) {
+	return runsPath(projectToken, ".json")
+}
+
+// savedRunsPath is where the runs started from here are kept, the latest last
+func savedRunsPath(projectToken string) (string, error) {
+	return runsPath(projectToken, "-history.json")
+}
+
+func runsPath(projectToken string, suffix string) (string, error) {
+	if projectToken == "" {
+		return "", errors.New("need a project token to store the last run")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, viper.GetString("CREDENTIALS_CONFIG"), "runs", projectToken+suffix), nil
+}
+
+func SaveLastRun(ctx context.Context, projectToken string, run LastRun) error {
+	path, err := lastRunPath(projectToken)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	jsonBytes, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	Logger(ctx).Debugf("Saving run %v to %v", run.RunId, path)
+	if err := os.WriteFile(path, jsonBytes, 0600); err != nil {
+		return err
+	}
+	return saveRun(ctx, projectToken, run)
+}
+
+func saveRun(ctx context.Context, projectToken string, run LastRun) error {
+	path, err := savedRunsPath(projectToken)
+	if err != nil {
+		return err
+	}
+	runs, err := loadSavedRuns(path)
+	if err != nil {
+		// they are only there to look up old runs so start again rather than stop the run being saved
+		Logger(ctx).Errorf("Error reading the saved runs %v so starting again %v", path, err)
+		runs = nil
+	}
+	runs = append(runs, run)
+	if len(runs) > savedRuns {
+		runs = runs[len(runs)-savedRuns:]
+	}
+	jsonBytes, err := json.Marshal(runs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, jsonBytes, 0600)
+}
+
+func loadSavedRuns(path string) ([]LastRun, error) {
+	jsonBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []LastRun
+	err = json.Unmarshal(jsonBytes, &runs)
+	return runs, err
+}
+
+// LoadRun returns the run with the id if it was started from here, nil if it wasn't
+func LoadRun(ctx context.Context, projectToken string, runId string) (*LastRun, error) {
+	path, err := savedRunsPath(projectToken)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := loadSavedRuns(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].RunId == runId {
+			return &runs[i], nil
+		}
+	}
+	Logger(ctx).Debugf("Run %v wasn't started from here", runId)
+	return nil, nil
+}
+
+// LoadLastRun returns nil if no run has been started from here
+func LoadLastRun(ctx context.Context, projectToken string) (*LastRun, error) {
+	path, err := lastRunPath(projectToken)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		Logger(ctx).Debugf("No last run recorded at %v", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run LastRun
+	err = json.Unmarshal(jsonBytes, &run)
+	return &run, err
+}
@@ -15,6 +15,7 @@ import (
 	api "brisk-supervisor/api"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -27,6 +28,149 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type AttachRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the run to follow, empty for the latest run
+	RunId string `protobuf:"bytes,1,opt,name=runId,proto3" json:"runId,omitempty"`
+}
+
+func (x *AttachRequest) Reset() {
+	*x = AttachRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachRequest) ProtoMessage() {}
+
+func (x *AttachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachRequest.ProtoReflect.Descriptor instead.
+func (*AttachRequest) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AttachRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type GetLogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the id of the run or the uid of the worker run
+	Uid string `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+}
+
+func (x *GetLogRequest) Reset() {
+	*x = GetLogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogRequest) ProtoMessage() {}
+
+func (x *GetLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogRequest.ProtoReflect.Descriptor instead.
+func (*GetLogRequest) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetLogRequest) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+type LogChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *LogChunk) Reset() {
+	*x = LogChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogChunk) ProtoMessage() {}
+
+func (x *LogChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogChunk.ProtoReflect.Descriptor instead.
+func (*LogChunk) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LogChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type CheckBuildMsg struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -38,7 +182,7 @@ type CheckBuildMsg struct {
 func (x *CheckBuildMsg) Reset() {
 	*x = CheckBuildMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[0]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -51,7 +195,7 @@ func (x *CheckBuildMsg) String() string {
 func (*CheckBuildMsg) ProtoMessage() {}
 
 func (x *CheckBuildMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[0]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -64,7 +208,7 @@ func (x *CheckBuildMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckBuildMsg.ProtoReflect.Descriptor instead.
 func (*CheckBuildMsg) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{0}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CheckBuildMsg) GetConfig() *Config {
@@ -87,7 +231,7 @@ type CheckBuildResp struct {
 func (x *CheckBuildResp) Reset() {
 	*x = CheckBuildResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[1]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -100,7 +244,7 @@ func (x *CheckBuildResp) String() string {
 func (*CheckBuildResp) ProtoMessage() {}
 
 func (x *CheckBuildResp) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[1]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,7 +257,7 @@ func (x *CheckBuildResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckBuildResp.ProtoReflect.Descriptor instead.
 func (*CheckBuildResp) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{1}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CheckBuildResp) GetSuccess() bool {
@@ -148,7 +292,7 @@ type Identifier struct {
 func (x *Identifier) Reset() {
 	*x = Identifier{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[2]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -161,7 +305,7 @@ func (x *Identifier) String() string {
 func (*Identifier) ProtoMessage() {}
 
 func (x *Identifier) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[2]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -174,7 +318,7 @@ func (x *Identifier) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Identifier.ProtoReflect.Descriptor instead.
 func (*Identifier) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{2}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Identifier) GetCommandRun() string {
@@ -189,29 +333,35 @@ type Config struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Command            []*api.Command    `protobuf:"bytes,1,rep,name=command,proto3" json:"command,omitempty"`
-	BuildCommands      []*api.Command    `protobuf:"bytes,2,rep,name=buildCommands,proto3" json:"buildCommands,omitempty"`
-	ListTestCommand    string            `protobuf:"bytes,3,opt,name=listTestCommand,proto3" json:"listTestCommand,omitempty"`
-	PreListTestCommand string            `protobuf:"bytes,4,opt,name=preListTestCommand,proto3" json:"preListTestCommand,omitempty"`
-	Framework          string            `protobuf:"bytes,5,opt,name=framework,proto3" json:"framework,omitempty"`
-	ExcludedFromSync   []string          `protobuf:"bytes,6,rep,name=excludedFromSync,proto3" json:"excludedFromSync,omitempty"`
-	WorkerImage        string            `protobuf:"bytes,7,opt,name=workerImage,proto3" json:"workerImage,omitempty"`
-	Environment        map[string]string `protobuf:"bytes,8,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Concurrency        int32             `protobuf:"varint,9,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
-	SplitByJUnit       bool              `protobuf:"varint,10,opt,name=splitByJUnit,proto3" json:"splitByJUnit,omitempty"`
-	OrderedFiles       []string          `protobuf:"bytes,11,rep,name=orderedFiles,proto3" json:"orderedFiles,omitempty"`
-	RebuildFilePaths   []string          `protobuf:"bytes,12,rep,name=rebuildFilePaths,proto3" json:"rebuildFilePaths,omitempty"`
-	GithubRepo         string            `protobuf:"bytes,13,opt,name=githubRepo,proto3" json:"githubRepo,omitempty"`
-	GithubCommit       string            `protobuf:"bytes,14,opt,name=githubCommit,proto3" json:"githubCommit,omitempty"`
-	SkipRecalcFiles    bool              `protobuf:"varint,15,opt,name=skipRecalcFiles,proto3" json:"skipRecalcFiles,omitempty"`
-	NoFailFast         bool              `protobuf:"varint,16,opt,name=noFailFast,proto3" json:"noFailFast,omitempty"`
-	AutomaticSplitting bool              `protobuf:"varint,17,opt,name=automaticSplitting,proto3" json:"automaticSplitting,omitempty"`
+	Command               []*api.Command    `protobuf:"bytes,1,rep,name=command,proto3" json:"command,omitempty"`
+	BuildCommands         []*api.Command    `protobuf:"bytes,2,rep,name=buildCommands,proto3" json:"buildCommands,omitempty"`
+	ListTestCommand       string            `protobuf:"bytes,3,opt,name=listTestCommand,proto3" json:"listTestCommand,omitempty"`
+	PreListTestCommand    string            `protobuf:"bytes,4,opt,name=preListTestCommand,proto3" json:"preListTestCommand,omitempty"`
+	Framework             string            `protobuf:"bytes,5,opt,name=framework,proto3" json:"framework,omitempty"`
+	ExcludedFromSync      []string          `protobuf:"bytes,6,rep,name=excludedFromSync,proto3" json:"excludedFromSync,omitempty"`
+	WorkerImage           string            `protobuf:"bytes,7,opt,name=workerImage,proto3" json:"workerImage,omitempty"`
+	Environment           map[string]string `protobuf:"bytes,8,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Concurrency           int32             `protobuf:"varint,9,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
+	SplitByJUnit          bool              `protobuf:"varint,10,opt,name=splitByJUnit,proto3" json:"splitByJUnit,omitempty"`
+	OrderedFiles          []string          `protobuf:"bytes,11,rep,name=orderedFiles,proto3" json:"orderedFiles,omitempty"`
+	RebuildFilePaths      []string          `protobuf:"bytes,12,rep,name=rebuildFilePaths,proto3" json:"rebuildFilePaths,omitempty"`
+	GithubRepo            string            `protobuf:"bytes,13,opt,name=githubRepo,proto3" json:"githubRepo,omitempty"`
+	GithubCommit          string            `protobuf:"bytes,14,opt,name=githubCommit,proto3" json:"githubCommit,omitempty"`
+	SkipRecalcFiles       bool              `protobuf:"varint,15,opt,name=skipRecalcFiles,proto3" json:"skipRecalcFiles,omitempty"`
+	NoFailFast            bool              `protobuf:"varint,16,opt,name=noFailFast,proto3" json:"noFailFast,omitempty"`
+	AutomaticSplitting    bool              `protobuf:"varint,17,opt,name=automaticSplitting,proto3" json:"automaticSplitting,omitempty"`
+	OnlyFiles             []string          `protobuf:"bytes,18,rep,name=onlyFiles,proto3" json:"onlyFiles,omitempty"`
+	Retries               int32             `protobuf:"varint,19,opt,name=retries,proto3" json:"retries,omitempty"`
+	DynamicSplitting      bool              `protobuf:"varint,20,opt,name=dynamicSplitting,proto3" json:"dynamicSplitting,omitempty"`
+	BatchSize             int32             `protobuf:"varint,21,opt,name=batchSize,proto3" json:"batchSize,omitempty"`
+	SplitAlgorithm        string            `protobuf:"bytes,22,opt,name=splitAlgorithm,proto3" json:"splitAlgorithm,omitempty"`
+	ExampleSplitThreshold int32             `protobuf:"varint,23,opt,name=exampleSplitThreshold,proto3" json:"exampleSplitThreshold,omitempty"`
 }
 
 func (x *Config) Reset() {
 	*x = Config{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[3]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -224,7 +374,7 @@ func (x *Config) String() string {
 func (*Config) ProtoMessage() {}
 
 func (x *Config) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[3]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -237,7 +387,7 @@ func (x *Config) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Config.ProtoReflect.Descriptor instead.
 func (*Config) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{3}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Config) GetCommand() []*api.Command {
@@ -359,6 +509,48 @@ func (x *Config) GetAutomaticSplitting() bool {
 	return false
 }
 
+func (x *Config) GetOnlyFiles() []string {
+	if x != nil {
+		return x.OnlyFiles
+	}
+	return nil
+}
+
+func (x *Config) GetRetries() int32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Config) GetDynamicSplitting() bool {
+	if x != nil {
+		return x.DynamicSplitting
+	}
+	return false
+}
+
+func (x *Config) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Config) GetSplitAlgorithm() string {
+	if x != nil {
+		return x.SplitAlgorithm
+	}
+	return ""
+}
+
+func (x *Config) GetExampleSplitThreshold() int32 {
+	if x != nil {
+		return x.ExampleSplitThreshold
+	}
+	return 0
+}
+
 type TestOption struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -377,7 +569,7 @@ type TestOption struct {
 func (x *TestOption) Reset() {
 	*x = TestOption{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[4]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -390,7 +582,7 @@ func (x *TestOption) String() string {
 func (*TestOption) ProtoMessage() {}
 
 func (x *TestOption) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[4]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,7 +595,7 @@ func (x *TestOption) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TestOption.ProtoReflect.Descriptor instead.
 func (*TestOption) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{4}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *TestOption) GetCommand() string {
@@ -475,7 +667,7 @@ type UserDetails struct {
 func (x *UserDetails) Reset() {
 	*x = UserDetails{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[5]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -488,7 +680,7 @@ func (x *UserDetails) String() string {
 func (*UserDetails) ProtoMessage() {}
 
 func (x *UserDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[5]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -501,7 +693,7 @@ func (x *UserDetails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserDetails.ProtoReflect.Descriptor instead.
 func (*UserDetails) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{5}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *UserDetails) GetApiToken() string {
@@ -538,7 +730,7 @@ type Response struct {
 func (x *Response) Reset() {
 	*x = Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[6]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -551,7 +743,7 @@ func (x *Response) String() string {
 func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[6]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -564,7 +756,7 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Response.ProtoReflect.Descriptor instead.
 func (*Response) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{6}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Response) GetValue() bool {
@@ -601,12 +793,14 @@ type BriskError struct {
 	AdditionalMessage string `protobuf:"bytes,6,opt,name=additionalMessage,proto3" json:"additionalMessage,omitempty"`
 	Fatal             bool   `protobuf:"varint,7,opt,name=fatal,proto3" json:"fatal,omitempty"`
 	StackTrace        string `protobuf:"bytes,8,opt,name=stackTrace,proto3" json:"stackTrace,omitempty"`
+	// the resource limit the command was killed for going over e.g. memory or pids
+	LimitExceeded string `protobuf:"bytes,9,opt,name=limitExceeded,proto3" json:"limitExceeded,omitempty"`
 }
 
 func (x *BriskError) Reset() {
 	*x = BriskError{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[7]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -619,7 +813,7 @@ func (x *BriskError) String() string {
 func (*BriskError) ProtoMessage() {}
 
 func (x *BriskError) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[7]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -632,7 +826,7 @@ func (x *BriskError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BriskError.ProtoReflect.Descriptor instead.
 func (*BriskError) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{7}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *BriskError) GetError() string {
@@ -691,6 +885,13 @@ func (x *BriskError) GetStackTrace() string {
 	return ""
 }
 
+func (x *BriskError) GetLimitExceeded() string {
+	if x != nil {
+		return x.LimitExceeded
+	}
+	return ""
+}
+
 type Output struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -715,12 +916,25 @@ type Output struct {
 	Command        *api.Command           `protobuf:"bytes,16,opt,name=command,proto3" json:"command,omitempty"`
 	Created        *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=created,proto3" json:"created,omitempty"`
 	ExecutionInfo  *api.ExecutionInfo     `protobuf:"bytes,18,opt,name=executionInfo,proto3" json:"executionInfo,omitempty"`
+	TotalTestFlaky int32                  `protobuf:"varint,19,opt,name=totalTestFlaky,proto3" json:"totalTestFlaky,omitempty"`
+	// the results of a test run parsed on the worker, one for each test
+	TestResults []*TestResult `protobuf:"bytes,20,rep,name=testResults,proto3" json:"testResults,omitempty"`
+	// where a command is in the dependsOn pipeline - started, passed, failed or skipped
+	StageStatus string `protobuf:"bytes,21,opt,name=stageStatus,proto3" json:"stageStatus,omitempty"`
+	// a chunk of a file matched by the artifacts globs, collected on the worker after a command finishes
+	Artifact *Artifact `protobuf:"bytes,22,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	// the coverage from a worker, or from all of the workers of a command when it comes from the supervisor
+	Coverage *Coverage `protobuf:"bytes,23,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	// the id of the run, sent once at the start so the CLI can tell others how to attach
+	RunId string `protobuf:"bytes,24,opt,name=runId,proto3" json:"runId,omitempty"`
+	// the key the logs of the run are encrypted with, sent with the runId to the CLI that started the run
+	LogEncryptionKey string `protobuf:"bytes,25,opt,name=logEncryptionKey,proto3" json:"logEncryptionKey,omitempty"`
 }
 
 func (x *Output) Reset() {
 	*x = Output{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[8]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -733,7 +947,7 @@ func (x *Output) String() string {
 func (*Output) ProtoMessage() {}
 
 func (x *Output) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[8]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -746,7 +960,7 @@ func (x *Output) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Output.ProtoReflect.Descriptor instead.
 func (*Output) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{8}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Output) GetResponse() string {
@@ -875,84 +1089,87 @@ func (x *Output) GetExecutionInfo() *api.ExecutionInfo {
 	return nil
 }
 
-type Worker struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Number int32  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
-	Uid    string `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+func (x *Output) GetTotalTestFlaky() int32 {
+	if x != nil {
+		return x.TotalTestFlaky
+	}
+	return 0
 }
 
-func (x *Worker) Reset() {
-	*x = Worker{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *Output) GetTestResults() []*TestResult {
+	if x != nil {
+		return x.TestResults
 	}
+	return nil
 }
 
-func (x *Worker) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Output) GetStageStatus() string {
+	if x != nil {
+		return x.StageStatus
+	}
+	return ""
 }
 
-func (*Worker) ProtoMessage() {}
-
-func (x *Worker) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *Output) GetArtifact() *Artifact {
+	if x != nil {
+		return x.Artifact
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use Worker.ProtoReflect.Descriptor instead.
-func (*Worker) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{9}
+func (x *Output) GetCoverage() *Coverage {
+	if x != nil {
+		return x.Coverage
+	}
+	return nil
 }
 
-func (x *Worker) GetNumber() int32 {
+func (x *Output) GetRunId() string {
 	if x != nil {
-		return x.Number
+		return x.RunId
 	}
-	return 0
+	return ""
 }
 
-func (x *Worker) GetUid() string {
+func (x *Output) GetLogEncryptionKey() string {
 	if x != nil {
-		return x.Uid
+		return x.LogEncryptionKey
 	}
 	return ""
 }
 
-type LockRequest struct {
+// a recording of a run is gzipped length delimited messages, a RecordingHeader and then a RecordedOutput for each output
+type RecordingHeader struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// the version of the CLI that made it
+	Version string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Started *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started,proto3" json:"started,omitempty"`
+	// what the CLI needs from brisk.json to show the run
+	Framework  string   `protobuf:"bytes,3,opt,name=framework,proto3" json:"framework,omitempty"`
+	CommandIds []string `protobuf:"bytes,4,rep,name=commandIds,proto3" json:"commandIds,omitempty"`
+	FilterList []string `protobuf:"bytes,5,rep,name=filterList,proto3" json:"filterList,omitempty"`
 }
 
-func (x *LockRequest) Reset() {
-	*x = LockRequest{}
+func (x *RecordingHeader) Reset() {
+	*x = RecordingHeader{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[10]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LockRequest) String() string {
+func (x *RecordingHeader) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LockRequest) ProtoMessage() {}
+func (*RecordingHeader) ProtoMessage() {}
 
-func (x *LockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[10]
+func (x *RecordingHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,9 +1180,506 @@ func (x *LockRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
+// Deprecated: Use RecordingHeader.ProtoReflect.Descriptor instead.
+func (*RecordingHeader) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RecordingHeader) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RecordingHeader) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *RecordingHeader) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *RecordingHeader) GetCommandIds() []string {
+	if x != nil {
+		return x.CommandIds
+	}
+	return nil
+}
+
+func (x *RecordingHeader) GetFilterList() []string {
+	if x != nil {
+		return x.FilterList
+	}
+	return nil
+}
+
+type RecordedOutput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// when the output was received, from the start of the recording
+	Offset *durationpb.Duration `protobuf:"bytes,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Output *Output              `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+}
+
+func (x *RecordedOutput) Reset() {
+	*x = RecordedOutput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordedOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordedOutput) ProtoMessage() {}
+
+func (x *RecordedOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordedOutput.ProtoReflect.Descriptor instead.
+func (*RecordedOutput) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RecordedOutput) GetOffset() *durationpb.Duration {
+	if x != nil {
+		return x.Offset
+	}
+	return nil
+}
+
+func (x *RecordedOutput) GetOutput() *Output {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type Coverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files []*FileCoverage `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+}
+
+func (x *Coverage) Reset() {
+	*x = Coverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Coverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coverage) ProtoMessage() {}
+
+func (x *Coverage) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coverage.ProtoReflect.Descriptor instead.
+func (*Coverage) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Coverage) GetFiles() []*FileCoverage {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// the line numbers and how many times each was hit
+	Lines    []int32 `protobuf:"varint,2,rep,packed,name=lines,proto3" json:"lines,omitempty"`
+	LineHits []int64 `protobuf:"varint,3,rep,packed,name=lineHits,proto3" json:"lineHits,omitempty"`
+	// an id for each branch and how many times it was taken
+	Branches   []string `protobuf:"bytes,4,rep,name=branches,proto3" json:"branches,omitempty"`
+	BranchHits []int64  `protobuf:"varint,5,rep,packed,name=branchHits,proto3" json:"branchHits,omitempty"`
+}
+
+func (x *FileCoverage) Reset() {
+	*x = FileCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileCoverage) ProtoMessage() {}
+
+func (x *FileCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileCoverage.ProtoReflect.Descriptor instead.
+func (*FileCoverage) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FileCoverage) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileCoverage) GetLines() []int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *FileCoverage) GetLineHits() []int64 {
+	if x != nil {
+		return x.LineHits
+	}
+	return nil
+}
+
+func (x *FileCoverage) GetBranches() []string {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+func (x *FileCoverage) GetBranchHits() []int64 {
+	if x != nil {
+		return x.BranchHits
+	}
+	return nil
+}
+
+type Artifact struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the path of the file relative to the work directory on the worker
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// where the data goes in the file, the chunks of a file are sent in order
+	Offset int64  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data   []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// set on the final chunk of a file
+	Last bool `protobuf:"varint,4,opt,name=last,proto3" json:"last,omitempty"`
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Artifact) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Artifact) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Artifact) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Artifact) GetLast() bool {
+	if x != nil {
+		return x.Last
+	}
+	return false
+}
+
+type TestResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Suite          string               `protobuf:"bytes,1,opt,name=suite,proto3" json:"suite,omitempty"`
+	Name           string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	File           string               `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	Status         string               `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Duration       *durationpb.Duration `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	FailureMessage string               `protobuf:"bytes,6,opt,name=failureMessage,proto3" json:"failureMessage,omitempty"`
+	WorkerNumber   int32                `protobuf:"varint,7,opt,name=workerNumber,proto3" json:"workerNumber,omitempty"`
+	WorkerUid      string               `protobuf:"bytes,8,opt,name=workerUid,proto3" json:"workerUid,omitempty"`
+	Attempt        int32                `protobuf:"varint,9,opt,name=attempt,proto3" json:"attempt,omitempty"`
+}
+
+func (x *TestResult) Reset() {
+	*x = TestResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestResult) ProtoMessage() {}
+
+func (x *TestResult) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestResult.ProtoReflect.Descriptor instead.
+func (*TestResult) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TestResult) GetSuite() string {
+	if x != nil {
+		return x.Suite
+	}
+	return ""
+}
+
+func (x *TestResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TestResult) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *TestResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TestResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *TestResult) GetFailureMessage() string {
+	if x != nil {
+		return x.FailureMessage
+	}
+	return ""
+}
+
+func (x *TestResult) GetWorkerNumber() int32 {
+	if x != nil {
+		return x.WorkerNumber
+	}
+	return 0
+}
+
+func (x *TestResult) GetWorkerUid() string {
+	if x != nil {
+		return x.WorkerUid
+	}
+	return ""
+}
+
+func (x *TestResult) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+type Worker struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number int32  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Uid    string `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+}
+
+func (x *Worker) Reset() {
+	*x = Worker{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Worker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Worker) ProtoMessage() {}
+
+func (x *Worker) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Worker.ProtoReflect.Descriptor instead.
+func (*Worker) Descriptor() ([]byte, []int) {
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Worker) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Worker) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+type LockRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *LockRequest) Reset() {
+	*x = LockRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockRequest) ProtoMessage() {}
+
+func (x *LockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
 func (*LockRequest) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{10}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{19}
 }
 
 type LockResponse struct {
@@ -981,7 +1695,7 @@ type LockResponse struct {
 func (x *LockResponse) Reset() {
 	*x = LockResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[11]
+		mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -994,7 +1708,7 @@ func (x *LockResponse) String() string {
 func (*LockResponse) ProtoMessage() {}
 
 func (x *LockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[11]
+	mi := &file_brisk_supervisor_brisk_supervisor_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1007,7 +1721,7 @@ func (x *LockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LockResponse.ProtoReflect.Descriptor instead.
 func (*LockResponse) Descriptor() ([]byte, []int) {
-	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{11}
+	return file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *LockResponse) GetLocked() bool {
@@ -1032,120 +1746,145 @@ var file_brisk_supervisor_brisk_supervisor_proto_rawDesc = []byte{
 	0x73, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x62, 0x72, 0x69, 0x73, 0x6b,
 	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
 	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x16, 0x61, 0x70, 0x69,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x16, 0x61, 0x70, 0x69,
 	0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x22, 0x40, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c,
-	0x64, 0x4d, 0x73, 0x67, 0x12, 0x2f, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65,
-	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x5a, 0x0a, 0x0e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75,
-	0x69, 0x6c, 0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65,
-	0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x22, 0x2c, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12,
-	0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x22,
-	0x8a, 0x06, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x26, 0x0a, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70,
-	0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x12, 0x32, 0x0a, 0x0d, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0d, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x6c, 0x69, 0x73, 0x74, 0x54, 0x65,
-	0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0f, 0x6c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x12, 0x2e, 0x0a, 0x12, 0x70, 0x72, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x73, 0x74, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x25, 0x0a, 0x0d, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x22, 0x21, 0x0a, 0x0d, 0x47, 0x65,
+	0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x22, 0x1e, 0x0a,
+	0x08, 0x4c, 0x6f, 0x67, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x40, 0x0a,
+	0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4d, 0x73, 0x67, 0x12, 0x2f,
+	0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
+	0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22,
+	0x5a, 0x0a, 0x0e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x2c, 0x0a, 0x0a, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x22, 0xea, 0x07, 0x0a, 0x06, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x26, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x32, 0x0a, 0x0d,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x0d, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x12, 0x28, 0x0a, 0x0f, 0x6c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6c, 0x69, 0x73, 0x74, 0x54,
+	0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2e, 0x0a, 0x12, 0x70, 0x72,
 	0x65, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x2a,
-	0x0a, 0x10, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x53, 0x79,
-	0x6e, 0x63, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64,
-	0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x53, 0x79, 0x6e, 0x63, 0x12, 0x20, 0x0a, 0x0b, 0x77, 0x6f,
-	0x72, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x4a, 0x0a, 0x0b,
-	0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x28, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
-	0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72,
-	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x65, 0x6e, 0x76,
-	0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x63,
-	0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63,
-	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x70,
-	0x6c, 0x69, 0x74, 0x42, 0x79, 0x4a, 0x55, 0x6e, 0x69, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x0c, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x42, 0x79, 0x4a, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x22,
-	0x0a, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x0b,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c,
-	0x65, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x46, 0x69, 0x6c,
-	0x65, 0x50, 0x61, 0x74, 0x68, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65,
-	0x62, 0x75, 0x69, 0x6c, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x1e,
-	0x0a, 0x0a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6f, 0x18, 0x0d, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6f, 0x12, 0x22,
-	0x0a, 0x0c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x0e,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x43, 0x6f, 0x6d, 0x6d,
-	0x69, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x73, 0x6b, 0x69, 0x70, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x63,
-	0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x6b, 0x69,
-	0x70, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x63, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
-	0x6e, 0x6f, 0x46, 0x61, 0x69, 0x6c, 0x46, 0x61, 0x73, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x0a, 0x6e, 0x6f, 0x46, 0x61, 0x69, 0x6c, 0x46, 0x61, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x12,
-	0x61, 0x75, 0x74, 0x6f, 0x6d, 0x61, 0x74, 0x69, 0x63, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x74, 0x69,
-	0x6e, 0x67, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x61, 0x75, 0x74, 0x6f, 0x6d, 0x61,
-	0x74, 0x69, 0x63, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x1a, 0x3e, 0x0a, 0x10,
-	0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
-	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xdc, 0x02, 0x0a,
-	0x0a, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c,
-	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x74, 0x65, 0x73, 0x74, 0x46, 0x69,
-	0x6c, 0x65, 0x73, 0x12, 0x3e, 0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69,
-	0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b,
-	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x44,
-	0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x52, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61,
-	0x69, 0x6c, 0x73, 0x12, 0x32, 0x0a, 0x0d, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69,
-	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0d, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x43,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x28, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
-	0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12,
-	0x2f, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x17, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x12, 0x29, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x08, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x49, 0x6e, 0x66,
-	0x6f, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x68, 0x0a, 0x0b, 0x55,
-	0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x70,
-	0x69, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61,
-	0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x6b,
-	0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79,
-	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
-	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74,
-	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x56, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xf4, 0x01,
-	0x0a, 0x0a, 0x42, 0x72, 0x69, 0x73, 0x6b, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05,
-	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x72, 0x72, 0x6f,
-	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x4f, 0x75, 0x74, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64, 0x4f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06,
-	0x73, 0x74, 0x64, 0x45, 0x72, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74,
-	0x64, 0x45, 0x72, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65,
-	0x12, 0x2c, 0x0a, 0x11, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x61, 0x64, 0x64,
-	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14,
-	0x0a, 0x05, 0x66, 0x61, 0x74, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66,
-	0x61, 0x74, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x72, 0x61,
-	0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x54,
-	0x72, 0x61, 0x63, 0x65, 0x22, 0xa6, 0x05, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x70, 0x72, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x54,
+	0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72,
+	0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66,
+	0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x2a, 0x0a, 0x10, 0x65, 0x78, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x53, 0x79, 0x6e, 0x63, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x10, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d,
+	0x53, 0x79, 0x6e, 0x63, 0x12, 0x20, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x65,
+	0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x4a, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x62, 0x72,
+	0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63,
+	0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x42, 0x79, 0x4a,
+	0x55, 0x6e, 0x69, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x70, 0x6c, 0x69,
+	0x74, 0x42, 0x79, 0x4a, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x6f, 0x72, 0x64, 0x65,
+	0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x10,
+	0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68, 0x73,
+	0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x46,
+	0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x52, 0x65, 0x70, 0x6f, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6f, 0x12, 0x22, 0x0a, 0x0c, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x28, 0x0a, 0x0f,
+	0x73, 0x6b, 0x69, 0x70, 0x52, 0x65, 0x63, 0x61, 0x6c, 0x63, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18,
+	0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x6b, 0x69, 0x70, 0x52, 0x65, 0x63, 0x61, 0x6c,
+	0x63, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x6f, 0x46, 0x61, 0x69, 0x6c,
+	0x46, 0x61, 0x73, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6e, 0x6f, 0x46, 0x61,
+	0x69, 0x6c, 0x46, 0x61, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x12, 0x61, 0x75, 0x74, 0x6f, 0x6d, 0x61,
+	0x74, 0x69, 0x63, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x12, 0x61, 0x75, 0x74, 0x6f, 0x6d, 0x61, 0x74, 0x69, 0x63, 0x53, 0x70, 0x6c,
+	0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x6e, 0x6c, 0x79, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x6e, 0x6c, 0x79, 0x46,
+	0x69, 0x6c, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x2a,
+	0x0a, 0x10, 0x64, 0x79, 0x6e, 0x61, 0x6d, 0x69, 0x63, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x74, 0x69,
+	0x6e, 0x67, 0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x64, 0x79, 0x6e, 0x61, 0x6d, 0x69,
+	0x63, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x70, 0x6c, 0x69,
+	0x74, 0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d,
+	0x12, 0x34, 0x0a, 0x15, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x70, 0x6c, 0x69, 0x74,
+	0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x15, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x1a, 0x3e, 0x0a, 0x10, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xdc, 0x02, 0x0a, 0x0a, 0x54, 0x65, 0x73, 0x74, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x74, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x74, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x3e, 0x0a,
+	0x0b, 0x75, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
+	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73,
+	0x52, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x32, 0x0a,
+	0x0d, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x0d, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x73, 0x12, 0x28, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x50,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x2f, 0x0a, 0x06, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x62, 0x72, 0x69, 0x73,
+	0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x29, 0x0a, 0x08, 0x52, 0x65,
+	0x70, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x52, 0x65, 0x70,
+	0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x68, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x44, 0x65, 0x74,
+	0x61, 0x69, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x70, 0x69, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72,
+	0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0x56, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75,
+	0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75,
+	0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x9a, 0x02, 0x0a, 0x0a, 0x42, 0x72, 0x69, 0x73,
+	0x6b, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x20, 0x0a, 0x0b,
+	0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x64, 0x4f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x64, 0x4f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x45, 0x72, 0x72,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64, 0x45, 0x72, 0x72, 0x12, 0x1a,
+	0x0a, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x61, 0x64,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x61, 0x74, 0x61,
+	0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x61, 0x74, 0x61, 0x6c, 0x12, 0x1e,
+	0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x72, 0x61, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x54, 0x72, 0x61, 0x63, 0x65, 0x12, 0x24,
+	0x0a, 0x0d, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x45, 0x78, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x45, 0x78, 0x63, 0x65,
+	0x65, 0x64, 0x65, 0x64, 0x22, 0xdf, 0x07, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12,
 	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
 	0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64,
@@ -1187,46 +1926,132 @@ var file_brisk_supervisor_brisk_supervisor_proto_rawDesc = []byte{
 	0x74, 0x65, 0x64, 0x12, 0x38, 0x0a, 0x0d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
 	0x49, 0x6e, 0x66, 0x6f, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69,
 	0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0d,
-	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x32, 0x0a,
-	0x06, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
-	0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69,
-	0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x22, 0x40, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x32, 0xe3, 0x01, 0x0a, 0x0f, 0x42, 0x72, 0x69, 0x73, 0x6b, 0x53, 0x75, 0x70, 0x65,
-	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x12, 0x47, 0x0a, 0x04, 0x4c, 0x6f, 0x63, 0x6b, 0x12, 0x1c,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x26, 0x0a,
+	0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x65, 0x73, 0x74, 0x46, 0x6c, 0x61, 0x6b, 0x79, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x65, 0x73, 0x74,
+	0x46, 0x6c, 0x61, 0x6b, 0x79, 0x12, 0x3d, 0x0a, 0x0b, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x62, 0x72, 0x69,
+	0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x73,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0b, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x67, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x67, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61,
+	0x63, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b,
+	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x41, 0x72, 0x74, 0x69, 0x66,
+	0x61, 0x63, 0x74, 0x52, 0x08, 0x61, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x12, 0x35, 0x0a,
+	0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
+	0x72, 0x2e, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x18, 0x18, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x10, 0x6c, 0x6f,
+	0x67, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x19,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6c, 0x6f, 0x67, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x22, 0xbf, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x34, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72,
+	0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66,
+	0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x49, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x74, 0x0a, 0x0e, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x65, 0x64, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x2f, 0x0a,
+	0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x3f,
+	0x0a, 0x08, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x33, 0x0a, 0x05, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x62, 0x72, 0x69, 0x73,
+	0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22,
+	0x90, 0x01, 0x0a, 0x0c, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x69,
+	0x6e, 0x65, 0x48, 0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x69,
+	0x6e, 0x65, 0x48, 0x69, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68,
+	0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x48, 0x69, 0x74, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x03, 0x52, 0x0a, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x48, 0x69,
+	0x74, 0x73, 0x22, 0x5e, 0x0a, 0x08, 0x41, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x61, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x61,
+	0x73, 0x74, 0x22, 0x9d, 0x02, 0x0a, 0x0a, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x75, 0x69, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x73, 0x75, 0x69, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66,
+	0x69, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26,
+	0x0a, 0x0e, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x77, 0x6f,
+	0x72, 0x6b, 0x65, 0x72, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x77, 0x6f,
+	0x72, 0x6b, 0x65, 0x72, 0x55, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x77,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x55, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x74, 0x74, 0x65,
+	0x6d, 0x70, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x61, 0x74, 0x74, 0x65, 0x6d,
+	0x70, 0x74, 0x22, 0x32, 0x0a, 0x06, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x40, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xe3, 0x01, 0x0a, 0x0f, 0x42, 0x72, 0x69, 0x73,
+	0x6b, 0x53, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x12, 0x47, 0x0a, 0x04, 0x4c,
+	0x6f, 0x63, 0x6b, 0x12, 0x1c, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x08, 0x52, 0x75, 0x6e, 0x54, 0x65, 0x73, 0x74, 0x73,
+	0x12, 0x1b, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
+	0x6f, 0x72, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x17, 0x2e,
+	0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x41, 0x0a, 0x05, 0x53, 0x65,
+	0x74, 0x75, 0x70, 0x12, 0x1b, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x1a, 0x19, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
+	0x6f, 0x72, 0x2e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x32, 0xdf, 0x01,
+	0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x6e, 0x65, 0x72, 0x12,
+	0x3a, 0x0a, 0x0b, 0x52, 0x75, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x0c,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x17, 0x2e, 0x62,
+	0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x41, 0x0a, 0x05, 0x53,
+	0x65, 0x74, 0x75, 0x70, 0x12, 0x1b, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x1a, 0x19, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4f,
+	0x0a, 0x0a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x1e, 0x2e, 0x62,
+	0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x1f, 0x2e, 0x62,
+	0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x32,
+	0x9b, 0x01, 0x0a, 0x09, 0x4c, 0x6f, 0x67, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x45, 0x0a,
+	0x06, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x12, 0x1e, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73,
+	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73,
+	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x22, 0x00, 0x30, 0x01, 0x12, 0x47, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x12, 0x1e,
 	0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
-	0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x62,
-	0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c,
-	0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12,
-	0x44, 0x0a, 0x08, 0x52, 0x75, 0x6e, 0x54, 0x65, 0x73, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x62, 0x72,
-	0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65,
-	0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x17, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b,
-	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75,
-	0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x41, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x75, 0x70, 0x12, 0x1b,
+	0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
 	0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
-	0x2e, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x19, 0x2e, 0x62, 0x72,
-	0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x72, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x32, 0xdf, 0x01, 0x0a, 0x0d, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x0b, 0x52, 0x75,
-	0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x17, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73,
-	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
-	0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x41, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x75, 0x70, 0x12,
-	0x1b, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x19, 0x2e, 0x62,
-	0x72, 0x69, 0x73, 0x6b, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4f, 0x0a, 0x0a, 0x43, 0x68, 0x65,
-	0x63, 0x6b, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x12, 0x1e, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73,
-	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42,
-	0x75, 0x69, 0x6c, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x1f, 0x2e, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x73,
-	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x42,
-	0x75, 0x69, 0x6c, 0x64, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x42, 0x14, 0x5a, 0x12, 0x2e, 0x2f,
-	0x62, 0x72, 0x69, 0x73, 0x6b, 0x2d, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x00, 0x30, 0x01, 0x42, 0x14, 0x5a,
+	0x12, 0x2e, 0x2f, 0x62, 0x72, 0x69, 0x73, 0x6b, 0x2d, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1241,58 +2066,80 @@ func file_brisk_supervisor_brisk_supervisor_proto_rawDescGZIP() []byte {
 	return file_brisk_supervisor_brisk_supervisor_proto_rawDescData
 }
 
-var file_brisk_supervisor_brisk_supervisor_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_brisk_supervisor_brisk_supervisor_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_brisk_supervisor_brisk_supervisor_proto_goTypes = []interface{}{
-	(*CheckBuildMsg)(nil),         // 0: brisksupervisor.CheckBuildMsg
-	(*CheckBuildResp)(nil),        // 1: brisksupervisor.CheckBuildResp
-	(*Identifier)(nil),            // 2: brisksupervisor.identifier
-	(*Config)(nil),                // 3: brisksupervisor.Config
-	(*TestOption)(nil),            // 4: brisksupervisor.TestOption
-	(*UserDetails)(nil),           // 5: brisksupervisor.UserDetails
-	(*Response)(nil),              // 6: brisksupervisor.response
-	(*BriskError)(nil),            // 7: brisksupervisor.BriskError
-	(*Output)(nil),                // 8: brisksupervisor.Output
-	(*Worker)(nil),                // 9: brisksupervisor.Worker
-	(*LockRequest)(nil),           // 10: brisksupervisor.LockRequest
-	(*LockResponse)(nil),          // 11: brisksupervisor.LockResponse
-	nil,                           // 12: brisksupervisor.Config.EnvironmentEntry
-	(*api.Command)(nil),           // 13: api.Command
-	(*api.RepoInfo)(nil),          // 14: api.RepoInfo
-	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
-	(*api.ExecutionInfo)(nil),     // 16: api.ExecutionInfo
+	(*AttachRequest)(nil),         // 0: brisksupervisor.AttachRequest
+	(*GetLogRequest)(nil),         // 1: brisksupervisor.GetLogRequest
+	(*LogChunk)(nil),              // 2: brisksupervisor.LogChunk
+	(*CheckBuildMsg)(nil),         // 3: brisksupervisor.CheckBuildMsg
+	(*CheckBuildResp)(nil),        // 4: brisksupervisor.CheckBuildResp
+	(*Identifier)(nil),            // 5: brisksupervisor.identifier
+	(*Config)(nil),                // 6: brisksupervisor.Config
+	(*TestOption)(nil),            // 7: brisksupervisor.TestOption
+	(*UserDetails)(nil),           // 8: brisksupervisor.UserDetails
+	(*Response)(nil),              // 9: brisksupervisor.response
+	(*BriskError)(nil),            // 10: brisksupervisor.BriskError
+	(*Output)(nil),                // 11: brisksupervisor.Output
+	(*RecordingHeader)(nil),       // 12: brisksupervisor.RecordingHeader
+	(*RecordedOutput)(nil),        // 13: brisksupervisor.RecordedOutput
+	(*Coverage)(nil),              // 14: brisksupervisor.Coverage
+	(*FileCoverage)(nil),          // 15: brisksupervisor.FileCoverage
+	(*Artifact)(nil),              // 16: brisksupervisor.Artifact
+	(*TestResult)(nil),            // 17: brisksupervisor.TestResult
+	(*Worker)(nil),                // 18: brisksupervisor.Worker
+	(*LockRequest)(nil),           // 19: brisksupervisor.LockRequest
+	(*LockResponse)(nil),          // 20: brisksupervisor.LockResponse
+	nil,                           // 21: brisksupervisor.Config.EnvironmentEntry
+	(*api.Command)(nil),           // 22: api.Command
+	(*api.RepoInfo)(nil),          // 23: api.RepoInfo
+	(*timestamppb.Timestamp)(nil), // 24: google.protobuf.Timestamp
+	(*api.ExecutionInfo)(nil),     // 25: api.ExecutionInfo
+	(*durationpb.Duration)(nil),   // 26: google.protobuf.Duration
 }
 var file_brisk_supervisor_brisk_supervisor_proto_depIdxs = []int32{
-	3,  // 0: brisksupervisor.CheckBuildMsg.config:type_name -> brisksupervisor.Config
-	13, // 1: brisksupervisor.Config.command:type_name -> api.Command
-	13, // 2: brisksupervisor.Config.buildCommands:type_name -> api.Command
-	12, // 3: brisksupervisor.Config.environment:type_name -> brisksupervisor.Config.EnvironmentEntry
-	5,  // 4: brisksupervisor.TestOption.userDetails:type_name -> brisksupervisor.UserDetails
-	13, // 5: brisksupervisor.TestOption.BuildCommands:type_name -> api.Command
-	13, // 6: brisksupervisor.TestOption.Commands:type_name -> api.Command
-	3,  // 7: brisksupervisor.TestOption.Config:type_name -> brisksupervisor.Config
-	14, // 8: brisksupervisor.TestOption.RepoInfo:type_name -> api.RepoInfo
-	7,  // 9: brisksupervisor.Output.BriskError:type_name -> brisksupervisor.BriskError
-	9,  // 10: brisksupervisor.Output.worker:type_name -> brisksupervisor.Worker
-	13, // 11: brisksupervisor.Output.command:type_name -> api.Command
-	15, // 12: brisksupervisor.Output.created:type_name -> google.protobuf.Timestamp
-	16, // 13: brisksupervisor.Output.executionInfo:type_name -> api.ExecutionInfo
-	10, // 14: brisksupervisor.BriskSupervisor.Lock:input_type -> brisksupervisor.LockRequest
-	4,  // 15: brisksupervisor.BriskSupervisor.RunTests:input_type -> brisksupervisor.TestOption
-	4,  // 16: brisksupervisor.BriskSupervisor.Setup:input_type -> brisksupervisor.TestOption
-	13, // 17: brisksupervisor.CommandRunner.RunCommands:input_type -> api.Command
-	4,  // 18: brisksupervisor.CommandRunner.Setup:input_type -> brisksupervisor.TestOption
-	0,  // 19: brisksupervisor.CommandRunner.CheckBuild:input_type -> brisksupervisor.CheckBuildMsg
-	11, // 20: brisksupervisor.BriskSupervisor.Lock:output_type -> brisksupervisor.LockResponse
-	8,  // 21: brisksupervisor.BriskSupervisor.RunTests:output_type -> brisksupervisor.Output
-	6,  // 22: brisksupervisor.BriskSupervisor.Setup:output_type -> brisksupervisor.response
-	8,  // 23: brisksupervisor.CommandRunner.RunCommands:output_type -> brisksupervisor.Output
-	6,  // 24: brisksupervisor.CommandRunner.Setup:output_type -> brisksupervisor.response
-	1,  // 25: brisksupervisor.CommandRunner.CheckBuild:output_type -> brisksupervisor.CheckBuildResp
-	20, // [20:26] is the sub-list for method output_type
-	14, // [14:20] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	6,  // 0: brisksupervisor.CheckBuildMsg.config:type_name -> brisksupervisor.Config
+	22, // 1: brisksupervisor.Config.command:type_name -> api.Command
+	22, // 2: brisksupervisor.Config.buildCommands:type_name -> api.Command
+	21, // 3: brisksupervisor.Config.environment:type_name -> brisksupervisor.Config.EnvironmentEntry
+	8,  // 4: brisksupervisor.TestOption.userDetails:type_name -> brisksupervisor.UserDetails
+	22, // 5: brisksupervisor.TestOption.BuildCommands:type_name -> api.Command
+	22, // 6: brisksupervisor.TestOption.Commands:type_name -> api.Command
+	6,  // 7: brisksupervisor.TestOption.Config:type_name -> brisksupervisor.Config
+	23, // 8: brisksupervisor.TestOption.RepoInfo:type_name -> api.RepoInfo
+	10, // 9: brisksupervisor.Output.BriskError:type_name -> brisksupervisor.BriskError
+	18, // 10: brisksupervisor.Output.worker:type_name -> brisksupervisor.Worker
+	22, // 11: brisksupervisor.Output.command:type_name -> api.Command
+	24, // 12: brisksupervisor.Output.created:type_name -> google.protobuf.Timestamp
+	25, // 13: brisksupervisor.Output.executionInfo:type_name -> api.ExecutionInfo
+	17, // 14: brisksupervisor.Output.testResults:type_name -> brisksupervisor.TestResult
+	16, // 15: brisksupervisor.Output.artifact:type_name -> brisksupervisor.Artifact
+	14, // 16: brisksupervisor.Output.coverage:type_name -> brisksupervisor.Coverage
+	24, // 17: brisksupervisor.RecordingHeader.started:type_name -> google.protobuf.Timestamp
+	26, // 18: brisksupervisor.RecordedOutput.offset:type_name -> google.protobuf.Duration
+	11, // 19: brisksupervisor.RecordedOutput.output:type_name -> brisksupervisor.Output
+	15, // 20: brisksupervisor.Coverage.files:type_name -> brisksupervisor.FileCoverage
+	26, // 21: brisksupervisor.TestResult.duration:type_name -> google.protobuf.Duration
+	19, // 22: brisksupervisor.BriskSupervisor.Lock:input_type -> brisksupervisor.LockRequest
+	7,  // 23: brisksupervisor.BriskSupervisor.RunTests:input_type -> brisksupervisor.TestOption
+	7,  // 24: brisksupervisor.BriskSupervisor.Setup:input_type -> brisksupervisor.TestOption
+	22, // 25: brisksupervisor.CommandRunner.RunCommands:input_type -> api.Command
+	7,  // 26: brisksupervisor.CommandRunner.Setup:input_type -> brisksupervisor.TestOption
+	3,  // 27: brisksupervisor.CommandRunner.CheckBuild:input_type -> brisksupervisor.CheckBuildMsg
+	0,  // 28: brisksupervisor.LogStream.Attach:input_type -> brisksupervisor.AttachRequest
+	1,  // 29: brisksupervisor.LogStream.GetLog:input_type -> brisksupervisor.GetLogRequest
+	20, // 30: brisksupervisor.BriskSupervisor.Lock:output_type -> brisksupervisor.LockResponse
+	11, // 31: brisksupervisor.BriskSupervisor.RunTests:output_type -> brisksupervisor.Output
+	9,  // 32: brisksupervisor.BriskSupervisor.Setup:output_type -> brisksupervisor.response
+	11, // 33: brisksupervisor.CommandRunner.RunCommands:output_type -> brisksupervisor.Output
+	9,  // 34: brisksupervisor.CommandRunner.Setup:output_type -> brisksupervisor.response
+	4,  // 35: brisksupervisor.CommandRunner.CheckBuild:output_type -> brisksupervisor.CheckBuildResp
+	11, // 36: brisksupervisor.LogStream.Attach:output_type -> brisksupervisor.Output
+	2,  // 37: brisksupervisor.LogStream.GetLog:output_type -> brisksupervisor.LogChunk
+	30, // [30:38] is the sub-list for method output_type
+	22, // [22:30] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_brisk_supervisor_brisk_supervisor_proto_init() }
@@ -1302,7 +2149,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CheckBuildMsg); i {
+			switch v := v.(*AttachRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1314,7 +2161,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CheckBuildResp); i {
+			switch v := v.(*GetLogRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1326,7 +2173,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Identifier); i {
+			switch v := v.(*LogChunk); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1338,7 +2185,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Config); i {
+			switch v := v.(*CheckBuildMsg); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1350,7 +2197,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TestOption); i {
+			switch v := v.(*CheckBuildResp); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1362,7 +2209,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UserDetails); i {
+			switch v := v.(*Identifier); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1374,7 +2221,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Response); i {
+			switch v := v.(*Config); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1386,7 +2233,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BriskError); i {
+			switch v := v.(*TestOption); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1398,7 +2245,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Output); i {
+			switch v := v.(*UserDetails); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1410,7 +2257,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Worker); i {
+			switch v := v.(*Response); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1422,7 +2269,7 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LockRequest); i {
+			switch v := v.(*BriskError); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1434,6 +2281,114 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			}
 		}
 		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Output); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordingHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordedOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Coverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Artifact); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Worker); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LockRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_brisk_supervisor_brisk_supervisor_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*LockResponse); i {
 			case 0:
 				return &v.state
@@ -1452,9 +2407,9 @@ func file_brisk_supervisor_brisk_supervisor_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_brisk_supervisor_brisk_supervisor_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   22,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   3,
 		},
 		GoTypes:           file_brisk_supervisor_brisk_supervisor_proto_goTypes,
 		DependencyIndexes: file_brisk_supervisor_brisk_supervisor_proto_depIdxs,
@@ -426,3 +426,183 @@ var CommandRunner_ServiceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "brisk-supervisor/brisk-supervisor.proto",
 }
+
+// LogStreamClient is the client API for LogStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LogStreamClient interface {
+	// Attach replays the output of the run from the start and then follows it until the run finishes
+	Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (LogStream_AttachClient, error)
+	// GetLog downloads the stored log of a finished run or worker run
+	GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (LogStream_GetLogClient, error)
+}
+
+type logStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogStreamClient(cc grpc.ClientConnInterface) LogStreamClient {
+	return &logStreamClient{cc}
+}
+
+func (c *logStreamClient) Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (LogStream_AttachClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogStream_ServiceDesc.Streams[0], "/brisksupervisor.LogStream/Attach", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logStreamAttachClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogStream_AttachClient interface {
+	Recv() (*Output, error)
+	grpc.ClientStream
+}
+
+type logStreamAttachClient struct {
+	grpc.ClientStream
+}
+
+func (x *logStreamAttachClient) Recv() (*Output, error) {
+	m := new(Output)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logStreamClient) GetLog(ctx context.Context, in *GetLogRequest, opts ...grpc.CallOption) (LogStream_GetLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogStream_ServiceDesc.Streams[1], "/brisksupervisor.LogStream/GetLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logStreamGetLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogStream_GetLogClient interface {
+	Recv() (*LogChunk, error)
+	grpc.ClientStream
+}
+
+type logStreamGetLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *logStreamGetLogClient) Recv() (*LogChunk, error) {
+	m := new(LogChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LogStreamServer is the server API for LogStream service.
+// All implementations must embed UnimplementedLogStreamServer
+// for forward compatibility
+type LogStreamServer interface {
+	// Attach replays the output of the run from the start and then follows it until the run finishes
+	Attach(*AttachRequest, LogStream_AttachServer) error
+	// GetLog downloads the stored log of a finished run or worker run
+	GetLog(*GetLogRequest, LogStream_GetLogServer) error
+	mustEmbedUnimplementedLogStreamServer()
+}
+
+// UnimplementedLogStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedLogStreamServer struct {
+}
+
+func (UnimplementedLogStreamServer) Attach(*AttachRequest, LogStream_AttachServer) error {
+	return status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
+func (UnimplementedLogStreamServer) GetLog(*GetLogRequest, LogStream_GetLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetLog not implemented")
+}
+func (UnimplementedLogStreamServer) mustEmbedUnimplementedLogStreamServer() {}
+
+// UnsafeLogStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogStreamServer will
+// result in compilation errors.
+type UnsafeLogStreamServer interface {
+	mustEmbedUnimplementedLogStreamServer()
+}
+
+func RegisterLogStreamServer(s grpc.ServiceRegistrar, srv LogStreamServer) {
+	s.RegisterService(&LogStream_ServiceDesc, srv)
+}
+
+func _LogStream_Attach_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttachRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogStreamServer).Attach(m, &logStreamAttachServer{stream})
+}
+
+type LogStream_AttachServer interface {
+	Send(*Output) error
+	grpc.ServerStream
+}
+
+type logStreamAttachServer struct {
+	grpc.ServerStream
+}
+
+func (x *logStreamAttachServer) Send(m *Output) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LogStream_GetLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogStreamServer).GetLog(m, &logStreamGetLogServer{stream})
+}
+
+type LogStream_GetLogServer interface {
+	Send(*LogChunk) error
+	grpc.ServerStream
+}
+
+type logStreamGetLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *logStreamGetLogServer) Send(m *LogChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LogStream_ServiceDesc is the grpc.ServiceDesc for LogStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "brisksupervisor.LogStream",
+	HandlerType: (*LogStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Attach",
+			Handler:       _LogStream_Attach_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetLog",
+			Handler:       _LogStream_GetLog_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "brisk-supervisor/brisk-supervisor.proto",
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	. "brisk-supervisor/shared"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+// how long the commands still running get to shut down after SIGINT before they are killed
+const terminateGrace = 5 * time.Second
+
+const resetCommandTimeout = 2 * time.Minute
+
+// how long each run of a readyCheck command gets
+const readyProbeTimeout = 30 * time.Second
+
+// lastRunLeftovers are the processes and ports the last run left behind, the next run is told about them.
+// RunCommands only runs one stream at a time so the gate looks after it.
+var lastRunLeftovers WorkerSnapshot
+
+// runCleanup is what a RunCommands stream started, so it can be stopped when the stream ends
+type runCleanup struct {
+	processes ProcessGroups
+	before    WorkerSnapshot
+	mu        sync.Mutex
+	// reset is the latest command with resetCommands, they run in its work directory with its environment
+	reset *api.Command
+}
+
+func newRunCleanup(ctx context.Context) *runCleanup {
+	before, err := TakeWorkerSnapshot()
+	if err != nil {
+		Logger(ctx).Errorf("Error taking a snapshot of the worker %v", err)
+	}
+	return &runCleanup{before: before}
+}
+
+func (c *runCleanup) setResetCommands(in *api.Command) {
+	if len(in.ResetCommands) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset = in
+}
+
+// finish stops anything still running, runs the resetCommands and then checks for anything that got away, e.g. a
+// daemon that started its own session
+func (c *runCleanup) finish(ctx context.Context, cancel context.CancelFunc) {
+	// the stream is over but we still want to log
+	ctx = context.WithoutCancel(ctx)
+	// TermStart stops the commands that are still running but doesn't wait for them, they have to be gone before the
+	// reset commands run and the snapshot is taken
+	cancel()
+	c.processes.Terminate(ctx, terminateGrace)
+
+	c.mu.Lock()
+	reset := c.reset
+	c.mu.Unlock()
+	if reset != nil {
+		for _, commandline := range reset.ResetCommands {
+			runResetCommand(ctx, commandline, reset)
+		}
+	}
+
+	after, err := TakeWorkerSnapshot()
+	if err != nil {
+		Logger(ctx).Errorf("Error taking a snapshot of the worker %v", err)
+		return
+	}
+	lastRunLeftovers = after.LeftSince(c.before)
+	if !lastRunLeftovers.Empty() {
+		Logger(ctx).Warnf("The run left behind %v", lastRunLeftovers)
+	}
+}
+
+func runResetCommand(ctx context.Context, commandline string, in *api.Command) {
+	ctx, cancel := context.WithTimeout(ctx, resetCommandTimeout)
+	defer cancel()
+	Logger(ctx).Infof("Running reset command %v", commandline)
+	out, err := TermCombinedOutput(ctx, createCmd(ctx, commandline, in, ""))
+	if err != nil {
+		Logger(ctx).Errorf("Reset command %v failed %v - %s", commandline, err, out)
+		return
+	}
+	Logger(ctx).Debugf("Reset command %v output %s", commandline, out)
+}
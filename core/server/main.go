@@ -31,6 +31,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -49,6 +50,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "brisk-supervisor/brisk-supervisor"
@@ -146,7 +148,8 @@ var runCommandsGate = RunCommandsGate{}
 // api.CommandRunner_RunCommandsServer
 func (s *server) RunCommands(stream pb.CommandRunner_RunCommandsServer) error {
 
-	ctx, cancel := context.WithDeadline(stream.Context(), time.Now().Add(viper.GetDuration("RUNNER_COMMAND_TIMEOUT")))
+	// each command has its own timeout so a slow build doesn't use up the time for the tests, see executeCommandStream
+	ctx, cancel := context.WithCancel(stream.Context())
 	defer DebugCancelCtx(ctx, cancel, "RunCommands finished so cancelling context")
 
 	ctx, span := otel.Tracer(name).Start(ctx, "RunCommands")
@@ -188,12 +191,20 @@ func (s *server) RunCommands(stream pb.CommandRunner_RunCommandsServer) error {
 	}
 	ctx = WithNomadAllocId(ctx, nomad.GetSmallNomadAllocId())
 
+	// registered after the gate so the next run waits until this one is cleaned up
+	cleanup := newRunCleanup(ctx)
+	defer cleanup.finish(ctx, cancel)
+	if leftovers := lastRunLeftovers.StillIn(cleanup.before); !leftovers.Empty() {
+		warning := fmt.Sprintf("This worker still has %v left behind by an earlier run", leftovers)
+		stream.Send(&pb.Output{Response: warning, Stderr: warning, Worker: &pb.Worker{Uid: nomad.GetSmallNomadAllocId()}, Created: timestamppb.Now()})
+	}
+
 	// 1000 is the max number of commands we can queue up
 	commandChan := make(chan *api.Command, 1000)
 	errChan := make(chan error, 1)
 	finishChan := make(chan bool, 1)
 
-	go executeCommandStream(commandChan, ctx, errChan, stream, finishChan)
+	go executeCommandStream(commandChan, ctx, errChan, stream, finishChan, cleanup)
 
 	go readCommands(ctx, stream, errChan, commandChan)
 
@@ -221,10 +232,11 @@ func (s *server) RunCommands(stream pb.CommandRunner_RunCommandsServer) error {
 
 }
 
-func executeCommandStream(commandChan chan *api.Command, ctx context.Context, errChan chan error, stream pb.CommandRunner_RunCommandsServer, finishChan chan bool) bool {
+func executeCommandStream(commandChan chan *api.Command, ctx context.Context, errChan chan error, stream pb.CommandRunner_RunCommandsServer, finishChan chan bool, cleanup *runCleanup) bool {
 	for in := range commandChan {
 		ctx, forSpan := otel.Tracer(name).Start(ctx, "RunCommands-Recv-loop")
 		defer forSpan.End()
+		cleanup.setResetCommands(in)
 
 		Logger(ctx).Debugf("The params from the super are %+v", in)
 		Logger(ctx).Debugf("The command incoming is %+v", in)
@@ -234,7 +246,8 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 		commandNumber := in.SequenceNumber
 		Logger(ctx).Debugf("Command sequence number is %v", commandNumber)
 
-		var resultsFilename = fmt.Sprintf("/tmp/test-output-test-run-%v", time.Now().Unix())
+		// a session can run several batches of tests so the sequence number keeps the results files apart
+		var resultsFilename = fmt.Sprintf("/tmp/test-output-test-run-%v-%v", time.Now().Unix(), commandNumber)
 		command, commandErr := setCommand(in, ctx, resultsFilename, errChan)
 
 		if commandErr != nil {
@@ -244,11 +257,34 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 		Logger(ctx).Debugf("the Command we are about to run is %v", command)
 
-		cmd := createCmd(ctx, command, in)
+		// background commands carry on until the stream is finished, everything else is killed when it times out
+		timeout := CommandTimeout(in)
+		cmdCtx, cmdCancel := context.WithTimeout(ctx, timeout)
+		if in.Background {
+			cmdCtx = ctx
+		}
+
+		cmd := createCmd(ctx, command, in, resultsFilename)
+		limiter := LimitCommand(ctx, cmd, in.Limits, fmt.Sprint(commandNumber))
+
+		// stops the timeout, removes the cgroup and what was written for the command once it has finished or couldn't
+		// be started
+		commandDone := func() {
+			cmdCancel()
+			limiter.Close(ctx)
+			os.Remove(filesFilename(resultsFilename))
+		}
+		ready := NewReadyWaiter(in)
+		tail := &OutputTail{}
+		if in.CoverageFile != "" {
+			// so we never send back the coverage from an earlier run
+			os.Remove(filepath.Join(in.WorkDirectory, in.CoverageFile))
+		}
 
 		streamErr := stream.Send(&pb.Output{Response: fmt.Sprintf("Running command %v", cmd.String()), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
 		if streamErr != nil {
 			Logger(ctx).Errorf("Error sending to stream %v", streamErr)
+			commandDone()
 			errChan <- streamErr
 			return false
 		}
@@ -262,6 +298,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 				errChan <- streamErr
 			}
 			Logger(ctx).Errorf("RunCommands Got an Error %v", errStd)
+			commandDone()
 			errChan <- errStd
 			return true
 		}
@@ -271,7 +308,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 		wg.Add(2)
 
-		go scanStdErr(ctx, &wg, stderr, in, commandNumber, stream)
+		go scanStdErr(ctx, &wg, stderr, in, commandNumber, stream, tail, ready)
 
 		var lastStdout string
 
@@ -284,6 +321,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 				errChan <- streamErr
 			}
 			Logger(ctx).Errorf("RunCommands got an Error %v", err1)
+			commandDone()
 			errChan <- err1
 			lastStdout = ""
 			return false
@@ -301,6 +339,8 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 				m := stdOutScanner.Text()
 				Logger(ctx).Debugf("StdOut: %v", m)
 				lastStdout = m
+				tail.Add(m)
+				ready.Line(m)
 				rs := pb.Output{Response: string(m), Stdout: string(m), Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()}
 				Logger(ctx).Debug("Waiting to send to the stream in stdout scanner")
 				streamErr := stream.Send(&rs)
@@ -333,7 +373,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 		Logger(ctx).Debug("Before start")
 		startTime := time.Now()
-		cmdWait, startErr := TermStart(ctx, cmd)
+		cmdWait, startErr := TermStart(cmdCtx, cmd)
 
 		if startErr != nil {
 			Logger(ctx).Debugf("The start error is %v", startErr)
@@ -351,18 +391,31 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 				Logger(ctx).Errorf("executeCommandStream Got an Error %v", streamErr)
 
 			}
+			commandDone()
 			errChan <- startErr
 			return true
 		}
 
+		cleanup.processes.Add(cmd.Process.Pid)
+
 		if in.Background {
 			Logger(ctx).Debug("Not waiting because command is a background job")
 
+			// only the readyCheck is waiting on it
+			exited := make(chan error, 1)
 			go func() {
-				cmdWait()
-
+				err := cmdWait()
+				commandDone()
+				exited <- err
 			}()
 
+			if ready != nil {
+				if readyErr := waitUntilReady(ctx, stream, in, commandNumber, ready, exited, tail); readyErr != nil {
+					errChan <- readyErr
+					return true
+				}
+			}
+
 			Logger(ctx).Debug("About to respond for the background job")
 			hash, err := getRebuildHash(ctx)
 			if err != nil {
@@ -383,6 +436,14 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 			Logger(ctx).Debug("wait group is finished - now we are waiting on the cmd to finish")
 			err := cmdWait()
 			endTime := time.Now()
+			timedOut := errors.Is(cmdCtx.Err(), context.DeadlineExceeded)
+			limitExceeded := limiter.Exceeded(ctx)
+			commandDone()
+			// sent before the command's result as the supervisor stops listening once it has that
+			if len(in.Artifacts) > 0 {
+				sendArtifacts(ctx, stream, in, commandNumber)
+			}
+			sendCoverage(ctx, stream, in, commandNumber)
 			hash, hashErr := getRebuildHash(ctx)
 			if hashErr != nil {
 				Logger(ctx).Errorf("Error getting rebuild hash %v", hashErr)
@@ -393,11 +454,14 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 			executionInfo := api.ExecutionInfo{Command: in, RebuildHash: hash, Started: timestamppb.New(startTime), Finished: timestamppb.New(endTime)}
 			Logger(ctx).Debugf("The error after cmd.Wait is %v", err)
+			// the results of a failed test run so the supervisor can tell which files failed
+			var failedResults []byte
 			if err != nil {
-				if in.IsTestRun && !in.NoTestFiles && in.TestFramework != string(types.Cypress) {
+				if writesResultsFile(in) {
 					Logger(ctx).Debug("error and checking to see if we have a file")
 
 					results, readErr := os.ReadFile(resultsFilename)
+					failedResults = results
 					if readErr != nil {
 						Logger(ctx).Debug("Can't read a file after error")
 					} else {
@@ -416,9 +480,25 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 						}
 					}
+				} else {
+					failedResults = optionalResultsFile(in, resultsFilename)
 				}
 			}
 			if err != nil {
+				exitCode := int32(cmd.ProcessState.ExitCode())
+				if timedOut {
+					// the process group has been killed, say why rather than passing on the signal it died from
+					executionInfo.TimedOut = true
+					executionInfo.ExitCode = TimedOutExitCode
+					executionInfo.Output = TimedOutMessage(in, timeout, tail)
+					exitCode = TimedOutExitCode
+					err = errors.New(executionInfo.Output)
+				}
+				var briskError *pb.BriskError
+				if limitExceeded != "" {
+					briskError = limiter.BriskError(in, limitExceeded, exitCode)
+					err = errors.New(briskError.Error)
+				}
 				Logger(ctx).Errorf("RunCommands sending back exit error", err)
 
 				streamErr := stream.Send(&pb.Output{Command: in, Response: err.Error(), Stderr: err.Error(), CmdSqNum: commandNumber, Created: timestamppb.Now()})
@@ -435,7 +515,11 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 						errChan <- streamErr
 					}
 				}
-				cmdErrResponse := pb.Output{Control: types.FAILED, Command: in, Response: "command failed :- " + err.Error(), Stderr: err.Error(), Exitcode: int32(cmd.ProcessState.ExitCode()), CmdSqNum: commandNumber, Created: timestamppb.Now()}
+				cmdErrResponse := pb.Output{Control: types.FAILED, Command: in, Response: "command failed :- " + err.Error(), Stderr: err.Error(), Exitcode: exitCode, JsonResults: string(failedResults), TestResults: parseTestResults(ctx, in, failedResults), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()}
+				if timedOut {
+					cmdErrResponse.ExecutionInfo = &executionInfo
+				}
+				cmdErrResponse.BriskError = briskError
 				streamErr = stream.Send(&cmdErrResponse)
 				if streamErr != nil {
 					Logger(ctx).Errorf("executeCommandStream Got an Error %v", streamErr)
@@ -455,7 +539,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 			Logger(ctx).Debugf("The process state is %v\n", cmd.ProcessState)
 			Logger(ctx).Debugf("the string is %v", cmd.String())
 
-			if in.IsTestRun && !in.NoTestFiles && (in.TestFramework != string(types.Cypress) && in.TestFramework != string(types.Rails) && in.TestFramework != string(types.Python) && in.TestFramework != string(types.Raw)) {
+			if writesResultsFile(in) {
 				Logger(ctx).Debug("go check to see if the file is there")
 
 				results, readErr := os.ReadFile(resultsFilename)
@@ -470,7 +554,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 				} else {
 					Logger(ctx).Debugf("Stream Send: %v We are sending back the results from the worker with executionInfo %v with rebuild hash %v", in.Commandline, executionInfo, hash)
 
-					streamErr := stream.Send(&pb.Output{ExecutionInfo: &executionInfo, Stdout: "Finished running command", Command: in, Control: cmdState, Exitcode: int32(cmd.ProcessState.ExitCode()), JsonResults: string(results), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+					streamErr := stream.Send(&pb.Output{ExecutionInfo: &executionInfo, Stdout: "Finished running command", Command: in, Control: cmdState, Exitcode: int32(cmd.ProcessState.ExitCode()), JsonResults: string(results), TestResults: parseTestResults(ctx, in, results), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
 					if streamErr != nil {
 						Logger(ctx).Errorf("executeCommandStream Got an Error %v", streamErr)
 						errChan <- streamErr
@@ -488,7 +572,8 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 
 			} else {
 				Logger(ctx).Debug("Not a test run so not fetching json file")
-				streamErr := stream.Send(&pb.Output{ExecutionInfo: &executionInfo, Stdout: "Finished running command", Command: in, Control: cmdState, Exitcode: int32(cmd.ProcessState.ExitCode()), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+				results := optionalResultsFile(in, resultsFilename)
+				streamErr := stream.Send(&pb.Output{ExecutionInfo: &executionInfo, Stdout: "Finished running command", Command: in, Control: cmdState, Exitcode: int32(cmd.ProcessState.ExitCode()), JsonResults: string(results), TestResults: parseTestResults(ctx, in, results), CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
 				if streamErr != nil {
 					Logger(ctx).Errorf("executeCommandStream Got an Error %v", streamErr)
 					errChan <- streamErr
@@ -520,7 +605,7 @@ func executeCommandStream(commandChan chan *api.Command, ctx context.Context, er
 }
 
 // https://pkg.go.dev/gg-scm.io/tool/internal/sigterm
-func createCmd(ctx context.Context, command string, in *api.Command) (cmd *exec.Cmd) {
+func createCmd(ctx context.Context, command string, in *api.Command, resultsFilename string) (cmd *exec.Cmd) {
 	if os.Getenv("INTERACTIVE_SHELL") == "true" {
 		cmd = exec.Command("bash", "-c", "-i", "-l", command)
 	} else {
@@ -534,6 +619,8 @@ func createCmd(ctx context.Context, command string, in *api.Command) (cmd *exec.
 
 	env = append(env, "BRISK_NODE_INDEX="+strconv.Itoa(int(in.WorkerNumber)))
 	env = append(env, "BRISK_NODE_TOTAL="+strconv.Itoa(int(in.TotalWorkerCount)))
+	// a test run can write its results here in any format we can parse e.g. JUnit XML or TAP
+	env = append(env, "BRISK_RESULTS_FILE="+resultsFilename)
 	if len(viper.GetString("HTTP_PROXY")) > 0 {
 		Logger(ctx).Warnf("Using http_proxy for command with value %v", viper.GetString("HTTP_PROXY"))
 		env = append([]string{"http_proxy=" + viper.GetString("HTTP_PROXY")}, env...)
@@ -546,6 +633,13 @@ func createCmd(ctx context.Context, command string, in *api.Command) (cmd *exec.
 
 func setCommand(in *api.Command, ctx context.Context, resultsFilename string, errChan chan error) (string, error) {
 
+	in, err := expandCommandTemplate(in, resultsFilename)
+	if err != nil {
+		Logger(ctx).Errorf("Error filling in the placeholders in %v %v", in.Commandline, err)
+		errChan <- err
+		return "", err
+	}
+
 	var command string
 	if in.NoTestFiles {
 		Logger(ctx).Debug("NoTestFiles is true so we are just running things without an output file")
@@ -557,36 +651,16 @@ func setCommand(in *api.Command, ctx context.Context, resultsFilename string, er
 
 	if in.IsTestRun {
 
-		switch in.TestFramework {
-		case string(types.Jest):
-
-			Logger(ctx).Info("TestFramework says this is a Jest run")
-
-			command = in.Commandline + " --forceExit" + " --outputFile=" + resultsFilename + " " + strings.Join(in.Args, " ")
-		case string(types.Cypress):
-			Logger(ctx).Info("TestFramework says this is a Cypress run")
-
-			command = in.Commandline + " --spec " + strings.Join(in.Args, ",")
-		case string(types.Rails):
-			Logger(ctx).Info("TestFramework says this is a Rails run")
-
-			command = in.Commandline + " " + strings.Join(in.Args, " ")
-
-		case string(types.Rspec):
-			command = in.Commandline + " -o " + resultsFilename + " " + strings.Join(in.Args, " ")
-		case string(types.Python):
-			Logger(ctx).Info("TestFramework says this is a Python run")
-			command = in.Commandline
-		case string(types.Raw):
-			Logger(ctx).Info("TestFramework says this is a Raw run")
-			command = in.Commandline
-		default:
+		framework, err := GetFramework(in.TestFramework)
+		if err != nil {
 			Logger(ctx).Error("Framework not recognized")
 
 			Logger(ctx).Errorf("RunCommands framework \"%v\" not recognized", in.TestFramework)
-			errChan <- errors.Errorf("framework \"%v\" not recognized", in.TestFramework)
-			return "", errors.Errorf("framework \"%v\" not recognized", in.TestFramework)
+			errChan <- err
+			return "", err
 		}
+		Logger(ctx).Infof("TestFramework says this is a %v run", framework.Name())
+		command = framework.TestCommand(in, resultsFilename)
 	} else if in.IsListTest {
 		Logger(ctx).Debugf("IsListTest is true so we are just running things without an output file")
 		command = in.Commandline + " " + strings.Join(in.Args, " ")
@@ -598,7 +672,155 @@ func setCommand(in *api.Command, ctx context.Context, resultsFilename string, er
 	return command, nil
 }
 
-func scanStdErr(ctx context.Context, wg *sync.WaitGroup, stderr *bufio.Reader, in *api.Command, commandNumber int32, stream pb.CommandRunner_RunCommandsServer) {
+// expandCommandTemplate fills in the placeholders in the commandline, when the commandline places the test files
+// itself they aren't appended to it as well
+func expandCommandTemplate(in *api.Command, resultsFilename string) (*api.Command, error) {
+	if !strings.Contains(in.Commandline, "{{") {
+		return in, nil
+	}
+	template := CommandTemplate{Files: in.Args, ResultsFile: resultsFilename, WorkerIndex: int(in.WorkerNumber), WorkerTotal: int(in.TotalWorkerCount)}
+	if UsesPlaceholder(in.Commandline, PlaceholderFilesFile) {
+		template.FilesFile = filesFilename(resultsFilename)
+		if err := os.WriteFile(template.FilesFile, []byte(strings.Join(in.Args, "\n")+"\n"), 0644); err != nil {
+			return in, err
+		}
+	}
+	expanded := proto.Clone(in).(*api.Command)
+	expanded.Commandline = template.Expand(in.Commandline)
+	if UsesFilesPlaceholder(in.Commandline) {
+		expanded.Args = nil
+	}
+	return expanded, nil
+}
+
+// filesFilename is where the test files are listed for a commandline using the files file placeholder
+func filesFilename(resultsFilename string) string {
+	return resultsFilename + "-files"
+}
+
+// writesResultsFile is true when the command is a test run whose framework writes its results to the results file
+func writesResultsFile(in *api.Command) bool {
+	if !in.IsTestRun || in.NoTestFiles {
+		return false
+	}
+	framework, err := GetFramework(in.TestFramework)
+	return err == nil && framework.WritesResultsFile()
+}
+
+// optionalResultsFile is the results file a test run chose to write through BRISK_RESULTS_FILE
+// when its framework doesn't write one itself
+func optionalResultsFile(in *api.Command, resultsFilename string) []byte {
+	if !in.IsTestRun || writesResultsFile(in) {
+		return nil
+	}
+	results, err := os.ReadFile(resultsFilename)
+	if err != nil {
+		return nil
+	}
+	return results
+}
+
+// parseTestResults parses the results file here on the worker so the supervisor gets a result for each test
+func parseTestResults(ctx context.Context, in *api.Command, results []byte) []*pb.TestResult {
+	if len(results) == 0 || !in.IsTestRun {
+		return nil
+	}
+	framework, err := GetFramework(in.TestFramework)
+	if err != nil {
+		return nil
+	}
+	testResults, err := framework.ParseResults(ctx, string(results))
+	if err != nil {
+		Logger(ctx).Errorf("Error parsing the test results of %v %v", in.Commandline, err)
+		return nil
+	}
+	for i := range testResults {
+		testResults[i].WorkerNumber = in.WorkerNumber
+		testResults[i].WorkerUid = nomad.GetSmallNomadAllocId()
+	}
+	return TestResultsToProto(testResults)
+}
+
+// waitUntilReady holds back the next command until the readyCheck of the background command passes, the supervisor is
+// told the command failed if it doesn't
+func waitUntilReady(ctx context.Context, stream pb.CommandRunner_RunCommandsServer, in *api.Command, commandNumber int32, ready *ReadyWaiter, exited <-chan error, tail *OutputTail) error {
+	timeout := ReadyTimeout(in)
+	Logger(ctx).Debugf("Waiting up to %v for %v to be ready", timeout, in.Commandline)
+	streamErr := stream.Send(&pb.Output{Response: fmt.Sprintf("Waiting for %v to be ready", in.Commandline), Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+	if streamErr != nil {
+		return streamErr
+	}
+	probe := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, readyProbeTimeout)
+		defer cancel()
+		_, err := TermCombinedOutput(ctx, createCmd(ctx, in.ReadyCheck.Command, in, ""))
+		return err
+	}
+	readyErr := ready.Wait(ctx, timeout, exited, probe)
+	if readyErr == nil {
+		return nil
+	}
+	message := fmt.Sprintf("%v: %v", in.Commandline, readyErr)
+	if lastOutput := tail.String(); lastOutput != "" {
+		message += ", last output was:\n" + lastOutput
+	}
+	Logger(ctx).Errorf("Background command not ready %v", message)
+	streamErr = stream.Send(&pb.Output{Control: types.FAILED, Command: in, Response: "command failed :- " + message, Stderr: message, Exitcode: 1, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+	if streamErr != nil {
+		Logger(ctx).Errorf("executeCommandStream Got an Error %v", streamErr)
+	}
+	return readyErr
+}
+
+// sendCoverage parses the coverage file the command wrote and streams it back in chunks, the paths are made relative
+// to the work directory so the coverage from each worker can be merged
+func sendCoverage(ctx context.Context, stream pb.CommandRunner_RunCommandsServer, in *api.Command, commandNumber int32) {
+	if in.CoverageFile == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(in.WorkDirectory, in.CoverageFile))
+	if err != nil {
+		Logger(ctx).Errorf("Error reading coverage file %v %v", in.CoverageFile, err)
+		return
+	}
+	report, err := ParseCoverage(data)
+	if err != nil {
+		Logger(ctx).Errorf("Error parsing coverage file %v %v", in.CoverageFile, err)
+		return
+	}
+	report.Relative(in.WorkDirectory)
+	for _, coverage := range CoverageToProto(report, CoverageChunkSize) {
+		if sendErr := stream.Send(&pb.Output{Coverage: coverage, Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()}); sendErr != nil {
+			Logger(ctx).Errorf("Error sending coverage %v", sendErr)
+			return
+		}
+	}
+}
+
+// sendArtifacts streams the files matching the artifacts globs back in chunks, a problem with them doesn't fail the command
+func sendArtifacts(ctx context.Context, stream pb.CommandRunner_RunCommandsServer, in *api.Command, commandNumber int32) {
+	root := in.WorkDirectory
+	if root == "" {
+		root = "."
+	}
+	files, err := FindArtifacts(root, in.Artifacts)
+	if err != nil {
+		Logger(ctx).Errorf("Error finding artifacts %v", err)
+		stream.Send(&pb.Output{Response: "Error finding artifacts " + err.Error(), Stderr: "Error finding artifacts " + err.Error(), Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+	}
+	Logger(ctx).Debugf("Sending %v artifacts matching %v", len(files), in.Artifacts)
+	for _, file := range files {
+		sendErr := SendArtifact(root, file, func(artifact *pb.Artifact) error {
+			return stream.Send(&pb.Output{Artifact: artifact, Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+		})
+		if sendErr != nil {
+			Logger(ctx).Errorf("Error sending artifact %v %v", file, sendErr)
+			stream.Send(&pb.Output{Response: fmt.Sprintf("Error sending artifact %v: %v", file, sendErr), Stderr: fmt.Sprintf("Error sending artifact %v: %v", file, sendErr), Command: in, CmdSqNum: commandNumber, Worker: &pb.Worker{Number: in.WorkerNumber, Uid: nomad.GetSmallNomadAllocId()}, Stage: in.Stage, Created: timestamppb.Now()})
+		}
+	}
+}
+
+func scanStdErr(ctx context.Context, wg *sync.WaitGroup, stderr *bufio.Reader, in *api.Command, commandNumber int32, stream pb.CommandRunner_RunCommandsServer, tail *OutputTail, ready *ReadyWaiter) {
 
 	defer bugsnag.AutoNotify(ctx)
 
@@ -611,6 +833,8 @@ func scanStdErr(ctx context.Context, wg *sync.WaitGroup, stderr *bufio.Reader, i
 
 		m := scanner.Text()
 		Logger(ctx).Debugf("StdErr: %v", m)
+		tail.Add(m)
+		ready.Line(m)
 		rs := pb.Output{
 			Response:       string(m),
 			Stdout:         "",
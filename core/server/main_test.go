@@ -15,9 +15,12 @@
 package main
 
 import (
+	"brisk-supervisor/api"
 	pb "brisk-supervisor/brisk-supervisor"
 
 	"context"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -139,6 +142,46 @@ func Test_mapHash(t *testing.T) {
 	}
 }
 
+func Test_setCommandTemplate(t *testing.T) {
+	resultsFilename := filepath.Join(t.TempDir(), "results")
+	tests := []struct {
+		name string
+		in   *api.Command
+		want string
+	}{
+		{name: "files in the middle", in: &api.Command{Commandline: "mytool --in {{files}} --out {{results_file}}", Args: []string{"a.test", "b c.test"}}, want: "mytool --in a.test 'b c.test' --out " + resultsFilename + " "},
+		{name: "files as csv for a raw test run", in: &api.Command{Commandline: "mytool --only={{files_csv}} --shard {{worker_index}}/{{worker_total}}", Args: []string{"a", "b"}, IsTestRun: true, TestFramework: "Raw", WorkerNumber: 1, TotalWorkerCount: 4}, want: "mytool --only=a,b --shard 1/4"},
+		{name: "jest keeps its output file", in: &api.Command{Commandline: "yarn test {{files}} --json", Args: []string{"a.test.js"}, IsTestRun: true, TestFramework: "Jest"}, want: "yarn test a.test.js --json --forceExit --outputFile=" + resultsFilename + " "},
+		{name: "no placeholders appends the files", in: &api.Command{Commandline: "run", Args: []string{"a", "b"}}, want: "run a b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setCommand(tt.in, context.Background(), resultsFilename, make(chan error, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("setCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	in := &api.Command{Commandline: "mytool --list {{files_file}}", Args: []string{"a", "b"}}
+	got, err := setCommand(in, context.Background(), resultsFilename, make(chan error, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "mytool --list "+resultsFilename+"-files " {
+		t.Errorf("setCommand() = %q", got)
+	}
+	if contents, _ := os.ReadFile(resultsFilename + "-files"); string(contents) != "a\nb\n" {
+		t.Errorf("expected the files to be listed in the files file got %q", contents)
+	}
+	if in.Commandline != "mytool --list {{files_file}}" || len(in.Args) != 2 {
+		t.Errorf("expected the command to be left alone got %+v", in)
+	}
+}
+
 func Test_getRebuildHash(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -512,7 +512,8 @@ func connectAndGetSplitterClient(ctx context.Context) (api.SplittingClient, *grp
 func LogRun(ctx context.Context, ri *api.RunInfo, logger *BriskLogger, command *api.Command) error {
 	ctx, span := otel.Tracer(name).Start(ctx, "LogRun")
 	defer span.End()
-	logger.Debugf("Logging run %v - %+v", ri, ri)
+	// not the whole run info as it has the log encryption key
+	logger.Debugf("Logging run %v for worker %v", ri.Uid, ri.WorkerId)
 	client, conn, err := connectAndGetProjectClient(ctx)
 	if err != nil {
 		return err
@@ -1047,30 +1048,52 @@ func DrainClient(ctx context.Context, client *nomadapi.Node) error {
 
 //now split for some people with this
 
-func SplitTests(ctx context.Context, numServers int32, files []string) ([][]string, string, error) {
+// SplitTests asks the API to split the files, if the splitting service is unavailable (or not there when self
+// hosting) we fall back to splitting on the supervisor with the timings we have recorded
+func SplitTests(ctx context.Context, numServers int32, files []string, algorithm string, history *SplitHistory) ([][]string, string, error) {
 	ctx, span := otel.Tracer(name).Start(ctx, "SplitTests")
 	defer span.End()
 
-	c, conn, err := connectAndGetSplitterClient(ctx)
-	if err != nil {
-		return nil, "", err
-	}
-	defer conn.Close()
-
 	if numServers > int32(len(files)) {
 		Logger(ctx).Infof("Num servers is greater than num files, setting num servers to num files: %v", len(files))
 		numServers = int32(len(files))
 	}
-	response, err := c.SplitForProject(ctx, &api.SplitRequest{NumBuckets: numServers, Filenames: files}, grpc_retry.WithMax(5))
+	request := &api.SplitRequest{Alogorithm: algorithm, NumBuckets: numServers, Filenames: files}
+
+	response, err := splitTestsRemote(ctx, request)
 	if err != nil {
-		Logger(ctx).Errorf("Could not split tests: %v", err)
-		return nil, "", err
+		Logger(ctx).Errorf("Could not split tests with the splitting service, splitting locally: %v", err)
+		localSplitter := LocalSplitter{History: history, WorkDir: os.Getenv("REMOTE_DIR")}
+		response, err = localSplitter.SplitForProject(ctx, request)
+		if err != nil {
+			Logger(ctx).Errorf("Could not split tests locally: %v", err)
+			return nil, "", err
+		}
 	}
 	Logger(ctx).Debugf("Split tests %v", response)
 	for v := range response.FileLists {
 		Logger(ctx).Debugf("Bucket %v has %v files", v, len(response.FileLists[v].Filenames))
 	}
-	return convertSplitResponseTo2DArray(response), response.SplitMethod, nil
+	tests := convertSplitResponseTo2DArray(response)
+	if history != nil {
+		history.SetLastSplit(tests)
+	}
+	return tests, response.SplitMethod, nil
+}
+
+func splitTestsRemote(ctx context.Context, request *api.SplitRequest) (*api.SplitResponse, error) {
+	c, conn, err := connectAndGetSplitterClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	response, err := c.SplitForProject(ctx, request, grpc_retry.WithMax(5))
+	if err != nil {
+		Logger(ctx).Errorf("Could not split tests: %v", err)
+		return nil, err
+	}
+	return response, nil
 }
 
 func convertSplitResponseTo2DArray(response *api.SplitResponse) [][]string {
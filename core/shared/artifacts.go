@@ -0,0 +1,201 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ArtifactChunkSize keeps each message well under the 4MB gRPC limit
+const ArtifactChunkSize = 1024 * 1024
+
+// ArtifactsDirectory is where the CLI writes the artifacts, under a directory for each run
+const ArtifactsDirectory = "brisk-artifacts"
+
+var ArtifactPathError = errors.New("artifact path is outside of the project")
+
+// ValidateArtifactGlob checks a glob is relative to the project and that each part of it is a valid path.Match pattern
+func ValidateArtifactGlob(glob string) error {
+	if err := checkArtifactPath(glob); err != nil {
+		return err
+	}
+	for _, segment := range strings.Split(glob, "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("artifacts glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+func checkArtifactPath(p string) error {
+	clean := path.Clean(p)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("%w: %q", ArtifactPathError, p)
+	}
+	return nil
+}
+
+// FindArtifacts gives the files under root matching the globs as slash separated paths relative to root,
+// ** matches any number of directories
+func FindArtifacts(root string, globs []string) ([]string, error) {
+	found := map[string]bool{}
+	var files []string
+	for _, glob := range globs {
+		segments := strings.Split(path.Clean(glob), "/")
+		// we only walk from the part of the glob without any wildcards
+		base := 0
+		for base < len(segments)-1 && !strings.ContainsAny(segments[base], `*?[\`) {
+			base++
+		}
+		start := filepath.Join(root, filepath.FromSlash(path.Join(segments[:base]...)))
+		err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if !found[rel] && matchGlob(segments, strings.Split(rel, "/")) {
+				found[rel] = true
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return files, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func matchGlob(glob []string, name []string) bool {
+	if len(glob) == 0 {
+		return len(name) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(glob[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	matched, _ := path.Match(glob[0], name[0])
+	return matched && matchGlob(glob[1:], name[1:])
+}
+
+// SendArtifact reads the file at rel under root and calls send with each chunk of it in order
+func SendArtifact(root string, rel string, send func(*pb.Artifact) error) error {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, ArtifactChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(f, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		last := n < len(buf) || offset+int64(n) >= info.Size()
+		if sendErr := send(&pb.Artifact{Path: rel, Offset: offset, Data: append([]byte(nil), buf[:n]...), Last: last}); sendErr != nil {
+			return sendErr
+		}
+		offset += int64(n)
+		if last {
+			return nil
+		}
+	}
+}
+
+// ArtifactWriter writes the artifact chunks from the workers under Dir, with a directory for each worker
+type ArtifactWriter struct {
+	Dir   string
+	Files []string
+}
+
+func (w *ArtifactWriter) Write(output *pb.Output) error {
+	artifact := output.Artifact
+	// the worker is trusted to run our tests but not to write outside of the artifacts directory
+	if err := checkArtifactPath(artifact.Path); err != nil {
+		return err
+	}
+	dest := filepath.Join(w.Dir, artifactWorkerDirectory(output), filepath.FromSlash(path.Clean(artifact.Path)))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY
+	if artifact.Offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(dest, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteAt(artifact.Data, artifact.Offset); err != nil {
+		return err
+	}
+	// each batch of a test run sends its artifacts again, the later ones overwrite the earlier ones
+	if artifact.Last && !slices.Contains(w.Files, dest) {
+		w.Files = append(w.Files, dest)
+	}
+	return nil
+}
+
+// the workers of each command are kept apart when there is more than one command
+func artifactWorkerDirectory(output *pb.Output) string {
+	var worker string
+	if output.Worker != nil {
+		worker = fmt.Sprintf("worker-%v", output.Worker.Number)
+	} else {
+		worker = "worker"
+	}
+	if output.Command != nil && output.Command.CommandId != "" {
+		return strings.NewReplacer("/", "_", `\`, "_", "..", "_").Replace(output.Command.CommandId) + "-" + worker
+	}
+	return worker
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, root string, name string, data []byte) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindArtifacts(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"coverage/lcov.info", "coverage/html/index.html", "tmp/screenshots/a/fail.png", "tmp/screenshots/b.png", "junit.xml", "src/app.js"} {
+		writeTestFile(t, root, name, []byte(name))
+	}
+
+	files, err := FindArtifacts(root, []string{"coverage/**", "tmp/screenshots/**/*.png", "*.xml", "coverage/lcov.info", "missing/*"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []string{"coverage/html/index.html", "coverage/lcov.info", "junit.xml", "tmp/screenshots/a/fail.png", "tmp/screenshots/b.png"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v got %v", want, files)
+	}
+
+	for _, glob := range []string{"/etc/passwd", "../other/*", "coverage/[.info"} {
+		if ValidateArtifactGlob(glob) == nil {
+			t.Errorf("expected %q to be rejected", glob)
+		}
+	}
+	if err := ValidateArtifactGlob("coverage/**/*.info"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSendArtifactRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789"), ArtifactChunkSize/4)
+	writeTestFile(t, root, "coverage/lcov.info", data)
+	writeTestFile(t, root, "empty.log", nil)
+
+	writer := &ArtifactWriter{Dir: t.TempDir()}
+	for _, file := range []string{"coverage/lcov.info", "empty.log"} {
+		var chunks int
+		err := SendArtifact(root, file, func(artifact *pb.Artifact) error {
+			chunks++
+			if len(artifact.Data) > ArtifactChunkSize {
+				t.Errorf("chunk of %v bytes is too big", len(artifact.Data))
+			}
+			return writer.Write(&pb.Output{Artifact: artifact, Worker: &pb.Worker{Number: 2}, Command: &api.Command{CommandId: "unit"}})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if file == "coverage/lcov.info" && chunks != 3 {
+			t.Errorf("expected 3 chunks got %v", chunks)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(writer.Dir, "unit-worker-2", "coverage", "lcov.info"))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("the artifact didn't come back the same, error %v", err)
+	}
+	if len(writer.Files) != 2 {
+		t.Errorf("expected 2 files got %v", writer.Files)
+	}
+
+	err = writer.Write(&pb.Output{Artifact: &pb.Artifact{Path: "../../.bashrc", Data: []byte("oops"), Last: true}})
+	if !errors.Is(err, ArtifactPathError) {
+		t.Errorf("expected a path outside of the artifacts directory to be rejected got %v", err)
+	}
+}
@@ -29,6 +29,7 @@ var ProjectInUseError = status.Error(codes.Unavailable, "project is in use")
 var ProjectTokenError = status.Error(codes.InvalidArgument, "project token is invalid")
 var RSyncError = status.Error(codes.Internal, "rsync error")
 var TestFailedError = errors.New("test run failed")
+var CommandFailedError = errors.New("command failed")
 
 func CancelledError(err error) bool {
 	return errors.Is(err, context.Canceled) || grpc_status.Code(err) == codes.Canceled
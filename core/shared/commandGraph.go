@@ -0,0 +1,188 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var CommandGraphError = errors.New("invalid dependsOn")
+
+// the StageStatus sent to the CLI as each command in the pipeline moves along
+const (
+	StageStarted = "started"
+	StagePassed  = "passed"
+	StageFailed  = "failed"
+	StageSkipped = "skipped"
+)
+
+// CommandGraph is the pipeline made by the dependsOn of each command, commands are referred to by their position
+// in the list of commands
+type CommandGraph struct {
+	ids        []string
+	dependsOn  [][]int
+	dependents [][]int
+	stages     []int
+}
+
+// NewCommandGraph checks the dependsOn of the commands refer to other commands by commandId and don't make a cycle
+func NewCommandGraph(commands []*api.Command) (*CommandGraph, error) {
+	ids := make([]string, len(commands))
+	dependsOn := make([][]string, len(commands))
+	for i, c := range commands {
+		ids[i] = c.CommandId
+		dependsOn[i] = c.DependsOn
+	}
+	return newCommandGraph(ids, dependsOn)
+}
+
+func validateCommandGraph(commands []Command) error {
+	ids := make([]string, len(commands))
+	dependsOn := make([][]string, len(commands))
+	for i, c := range commands {
+		ids[i] = c.CommandId
+		dependsOn[i] = c.DependsOn
+	}
+	_, err := newCommandGraph(ids, dependsOn)
+	return err
+}
+
+func newCommandGraph(ids []string, dependsOn [][]string) (*CommandGraph, error) {
+	g := &CommandGraph{ids: ids, dependsOn: make([][]int, len(ids)), dependents: make([][]int, len(ids)), stages: make([]int, len(ids))}
+
+	byId := map[string]int{}
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := byId[id]; ok && dependedOn(id, dependsOn) {
+			return nil, fmt.Errorf("%w: commandId %q is used by more than one command", CommandGraphError, id)
+		}
+		byId[id] = i
+	}
+
+	for i, deps := range dependsOn {
+		for _, dep := range deps {
+			j, ok := byId[dep]
+			if !ok {
+				return nil, fmt.Errorf("%w: command %v depends on unknown commandId %q", CommandGraphError, g.Name(i), dep)
+			}
+			if j == i {
+				return nil, fmt.Errorf("%w: command %v depends on itself", CommandGraphError, g.Name(i))
+			}
+			g.dependsOn[i] = append(g.dependsOn[i], j)
+			g.dependents[j] = append(g.dependents[j], i)
+		}
+	}
+
+	// the stage of a command is one more than the latest stage it depends on, working it out also finds any cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(ids))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, g.Name(i))
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("%w: commands depend on each other %v", CommandGraphError, strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, j := range g.dependsOn[i] {
+			if err := visit(j, path); err != nil {
+				return err
+			}
+			g.stages[i] = max(g.stages[i], g.stages[j]+1)
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range ids {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// a commandId can only be repeated when nothing depends on it
+func dependedOn(id string, dependsOn [][]string) bool {
+	for _, deps := range dependsOn {
+		for _, dep := range deps {
+			if dep == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasDependencies is true when any command has a dependsOn, without one every command runs at once
+func (g *CommandGraph) HasDependencies() bool {
+	for _, deps := range g.dependsOn {
+		if len(deps) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Name is the commandId of command i or its position when it doesn't have one
+func (g *CommandGraph) Name(i int) string {
+	if g.ids[i] == "" {
+		return fmt.Sprintf("#%v", i+1)
+	}
+	return g.ids[i]
+}
+
+// DependsOn are the commands that have to pass before command i runs
+func (g *CommandGraph) DependsOn(i int) []int {
+	return g.dependsOn[i]
+}
+
+// Downstream is every command that can't run if command i fails
+func (g *CommandGraph) Downstream(i int) []int {
+	var downstream []int
+	seen := map[int]bool{}
+	var walk func(i int)
+	walk = func(i int) {
+		for _, j := range g.dependents[i] {
+			if !seen[j] {
+				seen[j] = true
+				downstream = append(downstream, j)
+				walk(j)
+			}
+		}
+	}
+	walk(i)
+	return downstream
+}
+
+// Stage is the position of command i in the pipeline, commands without a dependsOn are in stage 1
+func (g *CommandGraph) Stage(i int) int {
+	return g.stages[i] + 1
+}
+
+// StageName is put in the Stage of the command so the output from each worker shows where it is in the pipeline
+func (g *CommandGraph) StageName(i int) string {
+	return fmt.Sprintf("Stage %v", g.Stage(i))
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCommandGraph(t *testing.T) {
+	commands := []*api.Command{
+		{CommandId: "lint"},
+		{CommandId: "unit"},
+		{CommandId: "integration", DependsOn: []string{"unit"}},
+		{CommandId: "e2e", DependsOn: []string{"integration", "lint"}},
+		{},
+	}
+	graph, err := NewCommandGraph(commands)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !graph.HasDependencies() {
+		t.Error("expected the graph to have dependencies")
+	}
+	var stages []int
+	for i := range commands {
+		stages = append(stages, graph.Stage(i))
+	}
+	if want := []int{1, 1, 2, 3, 1}; !reflect.DeepEqual(stages, want) {
+		t.Errorf("expected stages %v got %v", want, stages)
+	}
+	if got := graph.Downstream(1); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("expected integration and e2e downstream of unit got %v", got)
+	}
+	if got := graph.Downstream(3); len(got) != 0 {
+		t.Errorf("expected nothing downstream of e2e got %v", got)
+	}
+	if graph.Name(4) != "#5" || graph.StageName(3) != "Stage 3" {
+		t.Errorf("unexpected names %v %v", graph.Name(4), graph.StageName(3))
+	}
+
+	// repeated ids are fine as long as nothing depends on them
+	if graph, err := NewCommandGraph([]*api.Command{{CommandId: "a"}, {CommandId: "a"}}); err != nil || graph.HasDependencies() {
+		t.Errorf("expected repeated ids without dependsOn to be allowed got %v", err)
+	}
+}
+
+func TestCommandGraphInvalid(t *testing.T) {
+	for name, commands := range map[string][]Command{
+		"unknown":   {{CommandId: "a", DependsOn: []string{"b"}}},
+		"self":      {{CommandId: "a", DependsOn: []string{"a"}}},
+		"cycle":     {{CommandId: "a", DependsOn: []string{"c"}}, {CommandId: "b", DependsOn: []string{"a"}}, {CommandId: "c", DependsOn: []string{"b"}}},
+		"duplicate": {{CommandId: "a"}, {CommandId: "a"}, {CommandId: "b", DependsOn: []string{"a"}}},
+	} {
+		if err := validateCommandGraph(commands); !errors.Is(err, CommandGraphError) {
+			t.Errorf("%v: expected a dependsOn error got %v", name, err)
+		}
+		if err := validateConfig(Config{WorkerImage: "node-lts", Commands: commands}); err == nil {
+			t.Errorf("%v: expected the config to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the placeholders that can be used in a commandline, they are filled in on the worker
+const (
+	PlaceholderFiles       = "files"
+	PlaceholderFilesCsv    = "files_csv"
+	PlaceholderFilesFile   = "files_file"
+	PlaceholderResultsFile = "results_file"
+	PlaceholderWorkerIndex = "worker_index"
+	PlaceholderWorkerTotal = "worker_total"
+)
+
+var UnknownPlaceholderError = errors.New("unknown placeholder")
+
+var knownPlaceholders = []string{PlaceholderFiles, PlaceholderFilesCsv, PlaceholderFilesFile, PlaceholderResultsFile, PlaceholderWorkerIndex, PlaceholderWorkerTotal}
+
+// go template keywords that can appear on their own in braces e.g. in a go list -f template
+var goTemplateKeywords = []string{"end", "else", "break", "continue", "nil"}
+
+// only {{name}} is a placeholder, anything else in braces (like a go list -f template) is left alone
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// CommandTemplate is what the placeholders in a commandline are replaced with
+type CommandTemplate struct {
+	Files       []string
+	FilesFile   string
+	ResultsFile string
+	WorkerIndex int
+	WorkerTotal int
+}
+
+// ValidateCommandTemplate checks every placeholder in commandline is one we know
+func ValidateCommandTemplate(commandline string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(commandline, -1) {
+		if !isPlaceholder(match[1]) && !slices.Contains(goTemplateKeywords, match[1]) {
+			return fmt.Errorf("%w {{%v}} in %q - use one of {{%v}}", UnknownPlaceholderError, match[1], commandline, strings.Join(knownPlaceholders, "}}, {{"))
+		}
+	}
+	return nil
+}
+
+// UsesPlaceholder is true when commandline contains {{name}}
+func UsesPlaceholder(commandline string, name string) bool {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(commandline, -1) {
+		if match[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesFilesPlaceholder is true when the commandline places the test files itself, so they shouldn't be appended
+func UsesFilesPlaceholder(commandline string) bool {
+	return UsesPlaceholder(commandline, PlaceholderFiles) || UsesPlaceholder(commandline, PlaceholderFilesCsv) || UsesPlaceholder(commandline, PlaceholderFilesFile)
+}
+
+// Expand replaces the placeholders in commandline, unknown ones are left as they are
+func (t CommandTemplate) Expand(commandline string) string {
+	return placeholderPattern.ReplaceAllStringFunc(commandline, func(placeholder string) string {
+		switch placeholderPattern.FindStringSubmatch(placeholder)[1] {
+		case PlaceholderFiles:
+			return strings.Join(shellQuoteAll(t.Files), " ")
+		case PlaceholderFilesCsv:
+			return shellQuote(strings.Join(t.Files, ","))
+		case PlaceholderFilesFile:
+			return shellQuote(t.FilesFile)
+		case PlaceholderResultsFile:
+			return shellQuote(t.ResultsFile)
+		case PlaceholderWorkerIndex:
+			return strconv.Itoa(t.WorkerIndex)
+		case PlaceholderWorkerTotal:
+			return strconv.Itoa(t.WorkerTotal)
+		}
+		return placeholder
+	})
+}
+
+func isPlaceholder(name string) bool {
+	return slices.Contains(knownPlaceholders, name)
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+,-]+$`)
+
+// shellQuote single quotes s unless it is safe to pass to bash as it is
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellQuoteAll(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return quoted
+}
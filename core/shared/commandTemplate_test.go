@@ -0,0 +1,48 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateCommandTemplate(t *testing.T) {
+	for _, commandline := range []string{"yarn test", "run {{files}} {{ files_csv }} {{files_file}} {{results_file}} {{worker_index}} {{worker_total}}", GoListTestCommand} {
+		if err := ValidateCommandTemplate(commandline); err != nil {
+			t.Errorf("expected %q to be valid got %v", commandline, err)
+		}
+	}
+	if err := ValidateCommandTemplate("run {{file}}"); !errors.Is(err, UnknownPlaceholderError) {
+		t.Errorf("expected an unknown placeholder error got %v", err)
+	}
+
+	config := Config{WorkerImage: "node-lts", Commands: []Command{{Commandline: "yarn test {{worker_count}}"}}}
+	if err := validateConfig(config); err == nil {
+		t.Error("expected the config to be rejected")
+	}
+}
+
+func TestCommandTemplateExpand(t *testing.T) {
+	template := CommandTemplate{Files: []string{"spec/a_spec.rb[1:2]", "it's.rb"}, FilesFile: "/tmp/files", ResultsFile: "/tmp/results", WorkerIndex: 2, WorkerTotal: 3}
+	got := template.Expand("run {{files}} -- {{files_csv}} {{files_file}} {{results_file}} {{worker_index}}/{{worker_total}} {{unknown}}")
+	want := `run 'spec/a_spec.rb[1:2]' 'it'\''s.rb' -- 'spec/a_spec.rb[1:2],it'\''s.rb' /tmp/files /tmp/results 2/3 {{unknown}}`
+	if got != want {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	if !UsesFilesPlaceholder("run {{ files_file }}") || UsesFilesPlaceholder("run {{results_file}}") {
+		t.Error("expected only the file placeholders to place the files")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TimedOutExitCode is the exit code of a command that was killed for running past its timeout, the same as timeout(1)
+const TimedOutExitCode = 124
+
+// how many lines of output we keep to show when a command times out
+const timedOutTailLines = 10
+
+// CommandTimeout is how long the worker lets a command run, the timeout on the command or RUNNER_COMMAND_TIMEOUT
+func CommandTimeout(command *api.Command) time.Duration {
+	if command.Timeout != nil && command.Timeout.AsDuration() > 0 {
+		return command.Timeout.AsDuration()
+	}
+	return viper.GetDuration("RUNNER_COMMAND_TIMEOUT")
+}
+
+// OutputTail keeps the last lines of stdout and stderr so a timeout can say what the command was doing
+type OutputTail struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+func (t *OutputTail) Add(line string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.lines = append(t.lines, line)
+	if len(t.lines) > timedOutTailLines {
+		t.lines = t.lines[len(t.lines)-timedOutTailLines:]
+	}
+}
+
+func (t *OutputTail) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return strings.Join(t.lines, "\n")
+}
+
+// TimedOutMessage is the error sent back to the CLI and recorded in the ExecutionInfo when a command times out
+func TimedOutMessage(command *api.Command, timeout time.Duration, tail *OutputTail) string {
+	message := fmt.Sprintf("%v timed out after %v", command.Commandline, timeout)
+	if output := tail.String(); output != "" {
+		return message + ", last output was:\n" + output
+	}
+	return message + " without any output"
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	viper.Set("RUNNER_COMMAND_TIMEOUT", 10*time.Minute)
+	defer viper.Set("RUNNER_COMMAND_TIMEOUT", nil)
+
+	if got := CommandTimeout(&api.Command{}); got != 10*time.Minute {
+		t.Errorf("expected the runner timeout got %v", got)
+	}
+	if got := CommandTimeout(&api.Command{Timeout: durationpb.New(90 * time.Second)}); got != 90*time.Second {
+		t.Errorf("expected the command timeout got %v", got)
+	}
+
+	for _, timeout := range []string{"15", "-1m", "0s"} {
+		config := Config{WorkerImage: "node-lts", BuildCommands: []Command{{Commandline: "yarn install", Timeout: timeout}}}
+		if err := validateConfig(config); err == nil {
+			t.Errorf("expected timeout %q to be rejected", timeout)
+		}
+	}
+	if timeout, err := (Command{Timeout: "1h30m"}).TimeoutDuration(); err != nil || timeout != 90*time.Minute {
+		t.Errorf("expected 1h30m got %v %v", timeout, err)
+	}
+}
+
+func TestTimedOutMessage(t *testing.T) {
+	command := &api.Command{Commandline: "yarn test"}
+	tail := &OutputTail{}
+	if got := TimedOutMessage(command, time.Minute, tail); got != "yarn test timed out after 1m0s without any output" {
+		t.Errorf("unexpected message %q", got)
+	}
+
+	for i := 0; i < 15; i++ {
+		tail.Add(fmt.Sprintf("line %v", i))
+	}
+	got := TimedOutMessage(command, time.Minute, tail)
+	if !strings.HasPrefix(got, "yarn test timed out after 1m0s, last output was:\nline 5\n") || !strings.HasSuffix(got, "\nline 14") {
+		t.Errorf("expected the last 10 lines got %q", got)
+	}
+}
@@ -16,13 +16,12 @@ package shared
 
 import (
 	. "brisk-supervisor/shared/logger"
-	"brisk-supervisor/shared/types"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/go-errors/errors"
 
@@ -55,6 +54,18 @@ type Config struct {
 	Verbose            bool              `json:"verbose"`
 	NoFailFast         bool              `json:"noFailFast"`
 	AutomaticSplitting bool              `json:"automaticSplitting"`
+	JunitOutput        string            `json:"junitOutput,omitempty"`
+	Retries            int               `json:"retries,omitempty"`
+	DynamicSplitting   bool              `json:"dynamicSplitting,omitempty"`
+	BatchSize          int               `json:"batchSize,omitempty"`
+	SplitAlgorithm     string            `json:"splitAlgorithm,omitempty"`
+	Artifacts          []string          `json:"artifacts,omitempty"`
+	CoverageFile       string            `json:"coverageFile,omitempty"`
+	CoverageOutput     string            `json:"coverageOutput,omitempty"`
+	// ResetCommands run on each worker at the end of the run, the worker is reused for the next one
+	ResetCommands []string `json:"resetCommands,omitempty"`
+	// ExampleSplitThreshold is in seconds, rspec files slower than this are split by example id
+	ExampleSplitThreshold int `json:"exampleSplitThreshold,omitempty"`
 }
 
 type Server struct {
@@ -70,6 +81,28 @@ type Command struct {
 	CommandConcurrency int      `json:"commandConcurrency"`
 	CommandId          string   `json:"commandId"`
 	NoTestFiles        bool     `json:"noTestFiles"`
+	Retries            int      `json:"retries,omitempty"`
+	DependsOn          []string `json:"dependsOn,omitempty"`
+	Timeout            string   `json:"timeout,omitempty"`
+	// Limits are the memory, cpus and pids the worker lets the command use
+	Limits *ResourceLimits `json:"limits,omitempty"`
+	// ReadyCheck is how the worker knows a background command is up
+	ReadyCheck *ReadyCheck `json:"readyCheck,omitempty"`
+}
+
+// TimeoutDuration is the timeout of the command, 0 when it doesn't have one
+func (c Command) TimeoutDuration() (time.Duration, error) {
+	if c.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, errors.Errorf("timeout %q of %v is not a duration like 90s or 15m", c.Timeout, c.Commandline)
+	}
+	if timeout <= 0 {
+		return 0, errors.Errorf("timeout %q of %v has to be more than 0", c.Timeout, c.Commandline)
+	}
+	return timeout, nil
 }
 
 func (config Config) WriteConfig(ctx context.Context) error {
@@ -163,27 +196,53 @@ func validateConfig(config Config) error {
 	if config.WorkerImage == "" {
 		return errors.New("config error: image can not be blank")
 	}
+	if !ValidSplitAlgorithm(config.SplitAlgorithm) {
+		return errors.Errorf("config error: unknown splitAlgorithm %v - use one of %v, %v, %v or %v", config.SplitAlgorithm, SplitRoundRobin, SplitFileSize, SplitTimingPartition, SplitLPT)
+	}
+	if config.ExampleSplitThreshold < 0 {
+		return errors.Errorf("config error: exampleSplitThreshold %v can not be negative", config.ExampleSplitThreshold)
+	}
+	for _, c := range append(append([]Command{}, config.BuildCommands...), config.Commands...) {
+		if err := ValidateCommandTemplate(c.Commandline); err != nil {
+			return errors.Errorf("config error: %w", err)
+		}
+		if _, err := c.TimeoutDuration(); err != nil {
+			return errors.Errorf("config error: %w", err)
+		}
+		if _, err := c.Limits.Proto(); err != nil {
+			return errors.Errorf("config error: limits of %v %w", c.Commandline, err)
+		}
+		if c.ReadyCheck != nil && !c.Background {
+			return errors.Errorf("config error: %v has a readyCheck but only background commands can have one", c.Commandline)
+		}
+		if _, err := c.ReadyCheck.Proto(); err != nil {
+			return errors.Errorf("config error: %v %w", c.Commandline, err)
+		}
+	}
+	for _, glob := range config.Artifacts {
+		if err := ValidateArtifactGlob(glob); err != nil {
+			return errors.Errorf("config error: %w", err)
+		}
+	}
+	if config.CoverageFile != "" {
+		if err := checkArtifactPath(config.CoverageFile); err != nil {
+			return errors.Errorf("config error: coverageFile %w", err)
+		}
+	}
+	if err := validateCommandGraph(config.Commands); err != nil {
+		return errors.Errorf("config error: %w", err)
+	}
 	return nil
 }
 
 func cleanConfig(ctx context.Context, config Config) Config {
 
-	switch strings.ToUpper(config.Framework) {
-	case "JEST":
-		config.Framework = string(types.Jest)
-	case "RSPEC":
-		config.Framework = string(types.Rspec)
-	case "CYPRESS":
-		config.Framework = string(types.Cypress)
-	case "RAILS":
-		config.Framework = string(types.Rails)
-	case "RAW":
-		config.Framework = string(types.Raw)
-	case "PYTHON":
-		config.Framework = string(types.Python)
-	default:
+	framework, ok := LookupFramework(config.Framework)
+	if !ok {
 		fmt.Printf("Config error - unrecognized framework : %v", config.Framework)
 		Logger(ctx).Fatalf("Unrecognized framework %v", config.Framework)
+	} else {
+		config.Framework = string(framework.Name())
 	}
 
 	return config
@@ -32,6 +32,9 @@ const DEFAULT_REBUILD_HASH_FILE = ".rebuild_hash"
 
 const DEFAULT_SERVER_HASH_FILE = "/tmp/.rebuild"
 
+// the project is synced to this directory on the workers
+const DEFAULT_REMOTE_DIR = "/tmp/remote_dir/"
+
 const DEFAULT_LOCK_TIMEOUT = 40
 
 const DEFAULT_PROJECT_CONFIG_FILE = "brisk.json"
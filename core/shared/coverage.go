@@ -0,0 +1,407 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var UnknownCoverageFormatError = errors.New("unknown coverage format - use lcov, Cobertura XML or Istanbul JSON")
+
+// FileCoverage is how many times each line and branch of a file was hit, branches are keyed by an id from the report
+type FileCoverage struct {
+	Lines    map[int]int64
+	Branches map[string]int64
+}
+
+// CoverageReport is the coverage of a run, each worker only covers the tests it ran so they are merged into one
+type CoverageReport struct {
+	Files map[string]*FileCoverage
+}
+
+type CoverageTotals struct {
+	LinesFound    int
+	LinesHit      int
+	BranchesFound int
+	BranchesHit   int
+}
+
+func NewCoverageReport() *CoverageReport {
+	return &CoverageReport{Files: map[string]*FileCoverage{}}
+}
+
+func (r *CoverageReport) file(path string) *FileCoverage {
+	f, ok := r.Files[path]
+	if !ok {
+		f = &FileCoverage{Lines: map[int]int64{}, Branches: map[string]int64{}}
+		r.Files[path] = f
+	}
+	return f
+}
+
+// ParseCoverage works out the format of the report from what it starts with
+func ParseCoverage(data []byte) (*CoverageReport, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCobertura(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return parseIstanbul(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return parseLcov(trimmed)
+	}
+	return nil, UnknownCoverageFormatError
+}
+
+func parseLcov(data []byte) (*CoverageReport, error) {
+	report := NewCoverageReport()
+	var current *FileCoverage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		record, value, _ := strings.Cut(line, ":")
+		switch record {
+		case "SF":
+			current = report.file(value)
+		case "DA":
+			// DA:<line>,<hits>[,<checksum>]
+			parts := strings.Split(value, ",")
+			if current == nil || len(parts) < 2 {
+				continue
+			}
+			number, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("bad lcov line %q: %w", line, err)
+			}
+			hits, _ := strconv.ParseInt(parts[1], 10, 64)
+			current.Lines[number] += hits
+		case "BRDA":
+			// BRDA:<line>,<block>,<branch>,<taken> where taken is - when the line was never run
+			parts := strings.Split(value, ",")
+			if current == nil || len(parts) < 4 {
+				continue
+			}
+			taken, _ := strconv.ParseInt(parts[3], 10, 64)
+			current.Branches[strings.Join(parts[:3], ":")] += taken
+		case "end_of_record":
+			current = nil
+		}
+	}
+	return report, scanner.Err()
+}
+
+type coberturaReport struct {
+	Sources []string         `xml:"sources>source"`
+	Classes []coberturaClass `xml:"packages>package>classes>class"`
+}
+
+type coberturaClass struct {
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr"`
+}
+
+// condition-coverage looks like "50% (1/2)"
+var conditionCoveragePattern = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+// Cobertura only has a count of the branches taken on each line, so the first ones are counted as the ones taken
+func parseCobertura(data []byte) (*CoverageReport, error) {
+	var cobertura coberturaReport
+	if err := xml.Unmarshal(data, &cobertura); err != nil {
+		return nil, err
+	}
+	report := NewCoverageReport()
+	for _, class := range cobertura.Classes {
+		filename := class.Filename
+		// the filenames are relative to the source when there is one e.g. from coverage.py
+		if len(cobertura.Sources) == 1 && !filepath.IsAbs(filename) {
+			filename = filepath.Join(strings.TrimSpace(cobertura.Sources[0]), filename)
+		}
+		f := report.file(filename)
+		for _, line := range class.Lines {
+			f.Lines[line.Number] += line.Hits
+			if match := conditionCoveragePattern.FindStringSubmatch(line.ConditionCoverage); line.Branch && match != nil {
+				covered, _ := strconv.Atoi(match[1])
+				total, _ := strconv.Atoi(match[2])
+				for i := 0; i < total; i++ {
+					var taken int64
+					if i < covered {
+						taken = 1
+					}
+					f.Branches[fmt.Sprintf("%v:%v", line.Number, i)] += taken
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+type istanbulLocation struct {
+	Start struct {
+		Line int `json:"line"`
+	} `json:"start"`
+}
+
+type istanbulFile struct {
+	Path         string                      `json:"path"`
+	StatementMap map[string]istanbulLocation `json:"statementMap"`
+	S            map[string]int64            `json:"s"`
+	BranchMap    map[string]struct {
+		Line int              `json:"line"`
+		Loc  istanbulLocation `json:"loc"`
+	} `json:"branchMap"`
+	B map[string][]int64 `json:"b"`
+}
+
+// parseIstanbul reads a coverage-final.json, like istanbul the hits for a line are the most of any statement starting on it
+func parseIstanbul(data []byte) (*CoverageReport, error) {
+	var files map[string]istanbulFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, err
+	}
+	report := NewCoverageReport()
+	for key, file := range files {
+		path := file.Path
+		if path == "" {
+			path = key
+		}
+		f := report.file(path)
+		lines := map[int]int64{}
+		for id, location := range file.StatementMap {
+			line := location.Start.Line
+			if hits, seen := lines[line]; !seen || file.S[id] > hits {
+				lines[line] = file.S[id]
+			}
+		}
+		for line, hits := range lines {
+			f.Lines[line] += hits
+		}
+		for id, taken := range file.B {
+			line := file.BranchMap[id].Line
+			if line == 0 {
+				line = file.BranchMap[id].Loc.Start.Line
+			}
+			for i, hits := range taken {
+				f.Branches[fmt.Sprintf("%v:%v:%v", line, id, i)] += hits
+			}
+		}
+	}
+	return report, nil
+}
+
+// Merge adds the hits from other, a line or branch is covered if any worker covered it
+func (r *CoverageReport) Merge(other *CoverageReport) {
+	if other == nil {
+		return
+	}
+	for path, f := range other.Files {
+		r.file(path).merge(f)
+	}
+}
+
+// Relative makes the paths relative to dir, the workers have the project in a different place to the developer
+func (r *CoverageReport) Relative(dir string) {
+	if dir == "" {
+		return
+	}
+	for path, f := range r.Files {
+		if !filepath.IsAbs(path) {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			delete(r.Files, path)
+			r.file(rel).merge(f)
+		}
+	}
+}
+
+func (f *FileCoverage) merge(other *FileCoverage) {
+	for line, hits := range other.Lines {
+		f.Lines[line] += hits
+	}
+	for branch, hits := range other.Branches {
+		f.Branches[branch] += hits
+	}
+}
+
+func (r *CoverageReport) Totals() CoverageTotals {
+	var totals CoverageTotals
+	for _, f := range r.Files {
+		for _, hits := range f.Lines {
+			totals.LinesFound++
+			if hits > 0 {
+				totals.LinesHit++
+			}
+		}
+		for _, hits := range f.Branches {
+			totals.BranchesFound++
+			if hits > 0 {
+				totals.BranchesHit++
+			}
+		}
+	}
+	return totals
+}
+
+func coveragePercent(hit int, found int) float64 {
+	if found == 0 {
+		return 100
+	}
+	return float64(hit) * 100 / float64(found)
+}
+
+func (t CoverageTotals) String() string {
+	summary := fmt.Sprintf("lines %.2f%% (%v/%v)", coveragePercent(t.LinesHit, t.LinesFound), t.LinesHit, t.LinesFound)
+	if t.BranchesFound > 0 {
+		summary += fmt.Sprintf(", branches %.2f%% (%v/%v)", coveragePercent(t.BranchesHit, t.BranchesFound), t.BranchesHit, t.BranchesFound)
+	}
+	return summary
+}
+
+// WriteLcov writes the merged report as lcov which most coverage tools can read
+func (r *CoverageReport) WriteLcov(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f := r.Files[path]
+		fmt.Fprintf(out, "SF:%v\n", path)
+
+		branches := make([]string, 0, len(f.Branches))
+		for branch := range f.Branches {
+			branches = append(branches, branch)
+		}
+		sort.Strings(branches)
+		branchesHit := 0
+		for i, branch := range branches {
+			line, _, _ := strings.Cut(branch, ":")
+			if f.Branches[branch] > 0 {
+				branchesHit++
+			}
+			// the branch ids from the different formats are flattened to a block and branch number
+			fmt.Fprintf(out, "BRDA:%v,0,%v,%v\n", line, i, f.Branches[branch])
+		}
+		if len(branches) > 0 {
+			fmt.Fprintf(out, "BRF:%v\nBRH:%v\n", len(branches), branchesHit)
+		}
+
+		lines := make([]int, 0, len(f.Lines))
+		for line := range f.Lines {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		linesHit := 0
+		for _, line := range lines {
+			if f.Lines[line] > 0 {
+				linesHit++
+			}
+			fmt.Fprintf(out, "DA:%v,%v\n", line, f.Lines[line])
+		}
+		fmt.Fprintf(out, "LF:%v\nLH:%v\nend_of_record\n", len(lines), linesHit)
+	}
+	return out.Flush()
+}
+
+// CoverageChunkSize keeps each message of coverage well under the 4MB gRPC limit
+const CoverageChunkSize = 1024 * 1024
+
+// CoverageToProto splits the report into messages of at most around chunkSize bytes, a file with more coverage than
+// fits in one is split across them. Merging the messages back together gives the report.
+func CoverageToProto(r *CoverageReport, chunkSize int) []*pb.Coverage {
+	if r == nil || len(r.Files) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var chunks []*pb.Coverage
+	chunk := &pb.Coverage{}
+	var file *pb.FileCoverage
+	size := 0
+	// add makes room for an entry of size n in the file being added, each entry is counted at the most it can encode to
+	add := func(path string, n int) {
+		if size > 0 && size+n > chunkSize {
+			chunks = append(chunks, chunk)
+			chunk = &pb.Coverage{}
+			file = nil
+			size = 0
+		}
+		if file == nil {
+			file = &pb.FileCoverage{Path: path}
+			chunk.Files = append(chunk.Files, file)
+			size += len(path) + 16
+		}
+		size += n
+	}
+	for _, path := range paths {
+		f := r.Files[path]
+		file = nil
+		add(path, 0)
+		for line, hits := range f.Lines {
+			add(path, 20)
+			file.Lines = append(file.Lines, int32(line))
+			file.LineHits = append(file.LineHits, hits)
+		}
+		for branch, hits := range f.Branches {
+			add(path, len(branch)+16)
+			file.Branches = append(file.Branches, branch)
+			file.BranchHits = append(file.BranchHits, hits)
+		}
+	}
+	return append(chunks, chunk)
+}
+
+func CoverageFromProto(coverage *pb.Coverage) *CoverageReport {
+	report := NewCoverageReport()
+	for _, file := range coverage.GetFiles() {
+		f := report.file(file.Path)
+		for i, line := range file.Lines {
+			if i < len(file.LineHits) {
+				f.Lines[int(line)] += file.LineHits[i]
+			}
+		}
+		for i, branch := range file.Branches {
+			if i < len(file.BranchHits) {
+				f.Branches[branch] += file.BranchHits[i]
+			}
+		}
+	}
+	return report
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const workerOneLcov = `TN:
+SF:/app/src/sum.js
+DA:1,1
+DA:2,1
+DA:3,0
+BRDA:2,0,0,1
+BRDA:2,0,1,-
+end_of_record
+`
+
+const workerTwoCobertura = `<?xml version="1.0" ?>
+<coverage>
+  <sources><source>/app</source></sources>
+  <packages><package><classes>
+    <class filename="src/sum.js">
+      <lines>
+        <line number="1" hits="2"/>
+        <line number="3" hits="1"/>
+      </lines>
+    </class>
+    <class filename="src/other.js">
+      <lines>
+        <line number="1" hits="1" branch="true" condition-coverage="50% (1/2)"/>
+        <line number="2" hits="0"/>
+      </lines>
+    </class>
+  </classes></package></packages>
+</coverage>`
+
+const workerThreeIstanbul = `{"/app/src/app.js": {
+  "path": "/app/src/app.js",
+  "statementMap": {"0": {"start": {"line": 1}}, "1": {"start": {"line": 1}}, "2": {"start": {"line": 4}}},
+  "s": {"0": 0, "1": 3, "2": 0},
+  "branchMap": {"0": {"line": 4}},
+  "b": {"0": [0, 2]}
+}}`
+
+func parseTestCoverage(t *testing.T, data string) *CoverageReport {
+	t.Helper()
+	report, err := ParseCoverage([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	report.Relative("/app")
+	return report
+}
+
+func TestParseCoverage(t *testing.T) {
+	lcov := parseTestCoverage(t, workerOneLcov)
+	if want := (CoverageTotals{LinesFound: 3, LinesHit: 2, BranchesFound: 2, BranchesHit: 1}); lcov.Totals() != want {
+		t.Errorf("expected %+v got %+v", want, lcov.Totals())
+	}
+	if _, ok := lcov.Files["src/sum.js"]; !ok {
+		t.Errorf("expected the path to be relative to the work directory got %v", lcov.Files)
+	}
+
+	cobertura := parseTestCoverage(t, workerTwoCobertura)
+	if want := (CoverageTotals{LinesFound: 4, LinesHit: 3, BranchesFound: 2, BranchesHit: 1}); cobertura.Totals() != want {
+		t.Errorf("expected %+v got %+v", want, cobertura.Totals())
+	}
+
+	istanbul := parseTestCoverage(t, workerThreeIstanbul)
+	if got := istanbul.Files["src/app.js"].Lines; !reflect.DeepEqual(got, map[int]int64{1: 3, 4: 0}) {
+		t.Errorf("expected the most hits of the statements on each line got %v", got)
+	}
+	if want := (CoverageTotals{LinesFound: 2, LinesHit: 1, BranchesFound: 2, BranchesHit: 1}); istanbul.Totals() != want {
+		t.Errorf("expected %+v got %+v", want, istanbul.Totals())
+	}
+
+	if _, err := ParseCoverage([]byte("mode: set\n")); !errors.Is(err, UnknownCoverageFormatError) {
+		t.Errorf("expected an unknown format error got %v", err)
+	}
+}
+
+func TestMergeCoverage(t *testing.T) {
+	merged := NewCoverageReport()
+	for _, data := range []string{workerOneLcov, workerTwoCobertura, workerThreeIstanbul} {
+		// each worker's coverage goes through the proto on its way to the supervisor
+		for _, chunk := range CoverageToProto(parseTestCoverage(t, data), CoverageChunkSize) {
+			merged.Merge(CoverageFromProto(chunk))
+		}
+	}
+
+	if got := merged.Files["src/sum.js"].Lines; !reflect.DeepEqual(got, map[int]int64{1: 3, 2: 1, 3: 1}) {
+		t.Errorf("expected the hits of both workers got %v", got)
+	}
+	totals := merged.Totals()
+	if want := (CoverageTotals{LinesFound: 7, LinesHit: 5, BranchesFound: 6, BranchesHit: 3}); totals != want {
+		t.Errorf("expected %+v got %+v", want, totals)
+	}
+	if got := totals.String(); got != "lines 71.43% (5/7), branches 50.00% (3/6)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	var lcov bytes.Buffer
+	if err := merged.WriteLcov(&lcov); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reread, err := ParseCoverage(lcov.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if reread.Totals() != totals {
+		t.Errorf("expected the lcov to have the same totals %+v got %+v", totals, reread.Totals())
+	}
+}
+
+func TestCoverageChunks(t *testing.T) {
+	report := NewCoverageReport()
+	for i := 0; i < 3; i++ {
+		f := report.file(fmt.Sprintf("src/file%v.js", i))
+		for line := 1; line <= 500; line++ {
+			f.Lines[line] = int64(line % 3)
+			f.Branches[fmt.Sprintf("%v:%v", line, 0)] = int64(line % 2)
+		}
+	}
+
+	chunks := CoverageToProto(report, 4096)
+	if len(chunks) < 2 {
+		t.Fatalf("expected the coverage to be split got %v chunks", len(chunks))
+	}
+	merged := NewCoverageReport()
+	for _, chunk := range chunks {
+		if size := proto.Size(chunk); size > 4096 {
+			t.Errorf("expected each chunk to fit in 4096 bytes got %v", size)
+		}
+		merged.Merge(CoverageFromProto(chunk))
+	}
+	if !reflect.DeepEqual(merged, report) {
+		t.Errorf("expected the chunks to merge back into the report")
+	}
+
+	if chunks := CoverageToProto(NewCoverageReport(), CoverageChunkSize); chunks != nil {
+		t.Errorf("expected no chunks for an empty report got %v", chunks)
+	}
+}
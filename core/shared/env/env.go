@@ -42,6 +42,7 @@ func initDefaultValues(ctx context.Context) {
 	viper.SetDefault("HTTP_PROXY", "")
 	viper.SetDefault("RSYNC_TIMEOUT", 10)
 	viper.SetDefault("MEMORY_CHECK_INTERVAL", 5*time.Second)
+	viper.SetDefault("SPLIT_HISTORY_FILE", "/tmp/brisk-split-history.json")
 }
 
 func InitServerViper(ctx context.Context) {
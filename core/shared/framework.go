@@ -0,0 +1,104 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TestCounts are the running totals for a test run
+type TestCounts struct {
+	PassCount int
+	FailCount int
+	SkipCount int
+	// goPackagesWithTests are the go packages a test has been counted in, a package that fails without one is counted
+	// as a failure as it didn't build
+	goPackagesWithTests map[string]bool
+}
+
+// TestFramework is everything the supervisor and the workers need to know about a test framework
+// to add a framework implement this in a new file and register it from that file's init
+type TestFramework interface {
+	Name() types.Framework
+	// TestCommand is the command line that runs the test files in command.Args, writing results to resultsFile
+	TestCommand(command *api.Command, resultsFile string) string
+	// WritesResultsFile is true when the test command writes resultsFile for the worker to send back as JsonResults
+	WritesResultsFile() bool
+	// TakesFiles is false when TestCommand leaves out the files in command.Args as the command picks its own tests
+	TakesFiles(command *api.Command) bool
+	// ListCommand is run on a worker to list the tests of command, false means the framework can't list its tests
+	ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool)
+	// ParseListing turns the JsonResults of the list command into the test files
+	ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error)
+	// ParseResults turns the JsonResults of a test command into a record for each test
+	ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error)
+	// UpdateCounts adds the output of a test command to the totals
+	UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error
+}
+
+var frameworksMu sync.RWMutex
+var frameworks = map[string]TestFramework{}
+
+// RegisterFramework makes a framework available by its name, names are matched ignoring case
+func RegisterFramework(framework TestFramework) {
+	frameworksMu.Lock()
+	defer frameworksMu.Unlock()
+	frameworks[strings.ToUpper(string(framework.Name()))] = framework
+}
+
+// LookupFramework finds a registered framework by name ignoring case
+func LookupFramework(name string) (TestFramework, bool) {
+	frameworksMu.RLock()
+	defer frameworksMu.RUnlock()
+	framework, ok := frameworks[strings.ToUpper(name)]
+	return framework, ok
+}
+
+// GetFramework is LookupFramework with an error for frameworks we don't know
+func GetFramework(name string) (TestFramework, error) {
+	framework, ok := LookupFramework(name)
+	if !ok {
+		return nil, fmt.Errorf("framework \"%v\" not recognized", name)
+	}
+	return framework, nil
+}
+
+// CheckOnlyFiles is an error when the command can't be told which files to run, so --only-failed can't be used
+func CheckOnlyFiles(frameworkName string, command *api.Command) error {
+	framework, err := GetFramework(frameworkName)
+	if err != nil {
+		return err
+	}
+	if command.NoTestFiles || framework.TakesFiles(command) {
+		return nil
+	}
+	return fmt.Errorf("can't run only the failed test files with %v as %q picks its own tests", framework.Name(), command.Commandline)
+}
+
+// listCommandFromStdout is the list command for frameworks whose list command prints a JSON array of files
+func listCommandFromStdout(config *pb.Config, workDir string) api.Command {
+	return api.Command{Commandline: config.ListTestCommand, WorkDirectory: workDir, IsListTest: true, IsTestRun: false, Environment: config.Environment, TestFramework: config.Framework}
+}
+
+// testCommandWithArgs is the test command with the files appended
+func testCommandWithArgs(command *api.Command) string {
+	return command.Commandline + " " + strings.Join(command.Args, " ")
+}
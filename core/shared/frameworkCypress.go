@@ -0,0 +1,63 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"strings"
+)
+
+type cypressFramework struct{}
+
+func init() {
+	RegisterFramework(cypressFramework{})
+}
+
+func (cypressFramework) Name() types.Framework {
+	return types.Cypress
+}
+
+func (cypressFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return command.Commandline + " --spec " + strings.Join(command.Args, ",")
+}
+
+func (cypressFramework) WritesResultsFile() bool {
+	return false
+}
+
+func (cypressFramework) TakesFiles(command *api.Command) bool {
+	return true
+}
+
+func (cypressFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	return listCommandFromStdout(config, workDir), true
+}
+
+func (cypressFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	return SplitForJest(ctx, jsonResults)
+}
+
+func (cypressFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return nil, nil
+}
+
+func (cypressFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	Logger(ctx).Debug("No countrstruct for cypress")
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// GoListTestCommand prints the packages that have tests as a JSON array on a single line
+const GoListTestCommand = `echo "[$(go list -f '{{if or .TestGoFiles .XTestGoFiles}}"{{.ImportPath}}"{{end}}' ./... | grep . | paste -sd, -)]"`
+
+// goFramework splits by package and runs go test -json, the events are streamed as they happen and also written to
+// the results file so we have the whole run at the end
+type goFramework struct{}
+
+func init() {
+	RegisterFramework(goFramework{})
+}
+
+func (goFramework) Name() types.Framework {
+	return types.Go
+}
+
+func (goFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return "set -o pipefail; " + command.Commandline + " -json " + strings.Join(command.Args, " ") + " | tee " + resultsFile
+}
+
+func (goFramework) WritesResultsFile() bool {
+	return true
+}
+
+func (goFramework) TakesFiles(command *api.Command) bool {
+	return true
+}
+
+func (goFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	if config.ListTestCommand == "" {
+		return api.Command{Commandline: GoListTestCommand, WorkDirectory: workDir, IsListTest: true, Environment: config.Environment, TestFramework: config.Framework}, true
+	}
+	return listCommandFromStdout(config, workDir), true
+}
+
+// ParseListing takes the packages as a JSON array or one per line as go list prints them
+func (goFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	var packages []string
+	if err := json.Unmarshal([]byte(jsonResults), &packages); err != nil {
+		packages = nil
+		for _, line := range strings.Split(jsonResults, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				packages = append(packages, line)
+			}
+		}
+	}
+	packages = RemoveDuplicatesFromSlice(ctx, packages)
+	return PreTestInfo{Filenames: packages, TotalTestCount: len(packages)}, nil
+}
+
+// goTestEvent is a line of go test -json output, see go doc test2json
+type goTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+func (goFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return parseGoTestResults(jsonResults)
+}
+
+func parseGoTestResults(jsonResults string) ([]TestResult, error) {
+	var testCases []TestResult
+	output := map[string]*strings.Builder{}
+	testsRun := map[string]bool{}
+
+	scanner := bufio.NewScanner(strings.NewReader(jsonResults))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// go test prints build failures as plain text
+			continue
+		}
+		key := event.Package + "\x00" + event.Test
+		switch event.Action {
+		case "output":
+			if output[key] == nil {
+				output[key] = &strings.Builder{}
+			}
+			output[key].WriteString(event.Output)
+		case "pass", "fail", "skip":
+			tc := TestResult{Name: event.Test, File: event.Package, Duration: time.Duration(event.Elapsed * float64(time.Second))}
+			switch event.Action {
+			case "pass":
+				tc.Status = ResultPassed
+			case "fail":
+				tc.Status = ResultFailed
+				if output[key] != nil {
+					tc.FailureMessage = output[key].String()
+				}
+			default:
+				tc.Status = ResultSkipped
+			}
+			if event.Test != "" {
+				testsRun[event.Package] = true
+				testCases = append(testCases, tc)
+			} else if tc.Status == ResultFailed && !testsRun[event.Package] {
+				// the package failed without running a test e.g. it didn't compile
+				tc.Name = event.Package
+				testCases = append(testCases, tc)
+			}
+		}
+	}
+	return testCases, scanner.Err()
+}
+
+// go test streams an event per line, each test is counted as it finishes like ParseResults records them
+func (goFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	line := strings.TrimSpace(out.Stdout)
+	// the whole results file is sent back when a run fails, those tests have already been counted
+	if !strings.HasPrefix(line, "{") || strings.Contains(line, "\n") {
+		return nil
+	}
+	var event goTestEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		return nil
+	}
+	if event.Test == "" {
+		if event.Action == "fail" && !counts.goPackagesWithTests[event.Package] {
+			// the package failed without running a test e.g. it didn't compile
+			counts.FailCount++
+		}
+		return nil
+	}
+	switch event.Action {
+	case "pass":
+		counts.PassCount++
+	case "fail":
+		counts.FailCount++
+	case "skip":
+		counts.SkipCount++
+	default:
+		return nil
+	}
+	if counts.goPackagesWithTests == nil {
+		counts.goPackagesWithTests = map[string]bool{}
+	}
+	counts.goPackagesWithTests[event.Package] = true
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+	"strings"
+)
+
+type jestFramework struct{}
+
+func init() {
+	RegisterFramework(jestFramework{})
+}
+
+func (jestFramework) Name() types.Framework {
+	return types.Jest
+}
+
+func (jestFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return command.Commandline + " --forceExit" + " --outputFile=" + resultsFile + " " + strings.Join(command.Args, " ")
+}
+
+func (jestFramework) WritesResultsFile() bool {
+	return true
+}
+
+func (jestFramework) TakesFiles(command *api.Command) bool {
+	return true
+}
+
+func (jestFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	return listCommandFromStdout(config, workDir), true
+}
+
+func (jestFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	return SplitForJest(ctx, jsonResults)
+}
+
+func (jestFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return parseJestResults(jsonResults)
+}
+
+// the counts come from the results jest writes to --outputFile, sent back when each worker finishes, so test names
+// and log lines with PASS or FAIL in them don't change them
+func (jestFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	if out.Command == nil || !out.Command.IsTestRun {
+		return nil
+	}
+	return countOutputResults(out, counts, parseJestResults)
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PytestListTestCommand collects the test node ids and prints them as a JSON array on a single line
+const PytestListTestCommand = `pytest --collect-only -q | python3 -c 'import json,sys; print(json.dumps([l.strip() for l in sys.stdin if "::" in l]))'`
+
+// pythonFramework collects the tests with pytest --collect-only, splits them by file and reads the results from pytest's JUnit XML
+// projects that split with pytest-split, or whose listTestCommand is an older placeholder, are left to split the tests
+// themselves on each worker
+type pythonFramework struct{}
+
+func init() {
+	RegisterFramework(pythonFramework{})
+}
+
+func (pythonFramework) Name() types.Framework {
+	return types.Python
+}
+
+// xunit1 puts the file on each testcase so failed tests map back to the files we split
+func (pythonFramework) TestCommand(command *api.Command, resultsFile string) string {
+	if usesPytestSplit(command.Commandline) {
+		// pytest-split picks the tests for this worker itself
+		return command.Commandline + " --junitxml=" + resultsFile + " -o junit_family=xunit1"
+	}
+	return command.Commandline + " --junitxml=" + resultsFile + " -o junit_family=xunit1 " + strings.Join(command.Args, " ")
+}
+
+// usesPytestSplit is true when the command passes pytest-split's --splits or --group
+func usesPytestSplit(commandline string) bool {
+	for _, field := range strings.Fields(commandline) {
+		if field == "--splits" || field == "--group" || strings.HasPrefix(field, "--splits=") || strings.HasPrefix(field, "--group=") {
+			return true
+		}
+	}
+	return false
+}
+
+func (pythonFramework) WritesResultsFile() bool {
+	return true
+}
+
+func (pythonFramework) TakesFiles(command *api.Command) bool {
+	return !usesPytestSplit(command.Commandline)
+}
+
+// ListCommand only lists with the built-in collection, or another listTestCommand when splitAlgorithm asks for the
+// tests to be split, so older configs with a placeholder listTestCommand keep working
+func (pythonFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	if command != nil && usesPytestSplit(command.Commandline) {
+		return api.Command{}, false
+	}
+	if config.ListTestCommand == "" || config.ListTestCommand == PytestListTestCommand {
+		return api.Command{Commandline: PytestListTestCommand, WorkDirectory: workDir, IsListTest: true, Environment: config.Environment, TestFramework: config.Framework}, true
+	}
+	if config.SplitAlgorithm != "" {
+		return listCommandFromStdout(config, workDir), true
+	}
+	return api.Command{}, false
+}
+
+// ParseListing takes the node ids as a JSON array or one per line as pytest --collect-only -q prints them
+// the tests are split by file so the node ids are reduced to their files
+func (pythonFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	var nodeIds []string
+	if err := json.Unmarshal([]byte(jsonResults), &nodeIds); err != nil {
+		nodeIds = nil
+		for _, line := range strings.Split(jsonResults, "\n") {
+			if line = strings.TrimSpace(line); strings.Contains(line, "::") {
+				nodeIds = append(nodeIds, line)
+			}
+		}
+		if len(nodeIds) == 0 {
+			return PreTestInfo{}, fmt.Errorf("error parsing pytest collection: %v", err)
+		}
+	}
+	var files []string
+	for _, nodeId := range nodeIds {
+		file, _, _ := strings.Cut(nodeId, "::")
+		files = append(files, file)
+	}
+	return PreTestInfo{Filenames: RemoveDuplicatesFromSlice(ctx, files), TotalTestCount: len(nodeIds)}, nil
+}
+
+func (pythonFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return parseJunitXmlResults(jsonResults)
+}
+
+// the counts come from the JUnit XML sent back when each worker finishes
+func (pythonFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	if out.Command == nil || !out.Command.IsTestRun {
+		return nil
+	}
+	return countOutputResults(out, counts, parseJunitXmlResults)
+}
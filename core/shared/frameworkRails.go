@@ -0,0 +1,62 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+)
+
+// railsFramework runs the files with the configured command and no results file
+type railsFramework struct{}
+
+func init() {
+	RegisterFramework(railsFramework{})
+}
+
+func (railsFramework) Name() types.Framework {
+	return types.Rails
+}
+
+func (railsFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return testCommandWithArgs(command)
+}
+
+func (railsFramework) WritesResultsFile() bool {
+	return false
+}
+
+func (railsFramework) TakesFiles(command *api.Command) bool {
+	return true
+}
+
+func (railsFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	return listCommandFromStdout(config, workDir), true
+}
+
+func (railsFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	return SplitForJest(ctx, jsonResults)
+}
+
+// if the command does produce rspec json we can still use it
+func (railsFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return parseRspecResults(jsonResults)
+}
+
+func (railsFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	return updateCountsRspec(ctx, out, counts)
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// rawFramework runs the command as given, it picks its share of the tests with BRISK_NODE_INDEX and BRISK_NODE_TOTAL
+// and can report its results by writing JUnit XML or TAP to BRISK_RESULTS_FILE
+type rawFramework struct{}
+
+func init() {
+	RegisterFramework(rawFramework{})
+}
+
+func (rawFramework) Name() types.Framework {
+	return types.Raw
+}
+
+func (rawFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return command.Commandline
+}
+
+func (rawFramework) WritesResultsFile() bool {
+	return false
+}
+
+// the command picks its own share of the tests
+func (rawFramework) TakesFiles(command *api.Command) bool {
+	return false
+}
+
+func (rawFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	return listCommandFromStdout(config, workDir), true
+}
+
+func (rawFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	Logger(ctx).Debug("splitForRaw++")
+	Logger(ctx).Debugf("the json string is %v", jsonResults)
+	var files []string
+
+	err := json.Unmarshal([]byte(jsonResults), &files)
+	if err != nil {
+		Logger(ctx).Debugf("Error parsing json %v", err)
+		return PreTestInfo{}, fmt.Errorf("error parsing json: %v", err)
+	}
+	Logger(ctx).Debug("splitForRaw--")
+	return PreTestInfo{Filenames: RemoveDuplicatesFromSlice(ctx, files), TotalTestCount: len(files), TotalSkipCount: 0}, nil
+}
+
+func (rawFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return ParseTestResults("", jsonResults)
+}
+
+func (rawFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	if out.Command == nil || !out.Command.IsTestRun {
+		return nil
+	}
+	return countOutputResults(out, counts, func(results string) ([]TestResult, error) {
+		return ParseTestResults("", results)
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"os"
+	"strings"
+	"time"
+)
+
+type rspecFramework struct{}
+
+func init() {
+	RegisterFramework(rspecFramework{})
+}
+
+func (rspecFramework) Name() types.Framework {
+	return types.Rspec
+}
+
+// example ids are quoted so the shell doesn't treat the brackets as a glob
+func (rspecFramework) TestCommand(command *api.Command, resultsFile string) string {
+	return command.Commandline + " -o " + resultsFile + " " + strings.Join(shellQuoteAll(command.Args), " ")
+}
+
+// RspecExampleFile is the file an example id like ./spec/foo_spec.rb[1:2:3] belongs to, a file is returned as is
+func RspecExampleFile(id string) string {
+	if i := strings.LastIndex(id, "["); i > 0 && strings.HasSuffix(id, "]") {
+		return id[:i]
+	}
+	return id
+}
+
+// SplitSlowRspecFiles replaces the files that have taken longer than threshold with their example ids
+// so one big spec file can be spread across the workers instead of pinning one of them
+func SplitSlowRspecFiles(ctx context.Context, files []string, exampleIds map[string][]string, threshold time.Duration, history *SplitHistory) []string {
+	if threshold <= 0 || history == nil || len(exampleIds) == 0 {
+		return files
+	}
+	var result []string
+	for _, file := range files {
+		ids := exampleIds[file]
+		duration, ok := history.Duration(file, ids)
+		if !ok || duration <= threshold || len(ids) < 2 {
+			result = append(result, file)
+			continue
+		}
+		Logger(ctx).Debugf("Splitting %v into %v examples as it takes %v", file, len(ids), duration)
+		history.EstimateParts(file, ids)
+		result = append(result, ids...)
+	}
+	return result
+}
+
+func (rspecFramework) WritesResultsFile() bool {
+	return true
+}
+
+func (rspecFramework) TakesFiles(command *api.Command) bool {
+	return true
+}
+
+// rspec lists with a dry run so the listing comes back in the results file like a test run
+func (rspecFramework) ListCommand(config *pb.Config, command *api.Command, workDir string) (api.Command, bool) {
+	listTestCommand := config.ListTestCommand
+	if len(listTestCommand) == 0 {
+		listTestCommand = os.Getenv("RSPEC_LIST_TEST_CMD")
+	}
+	return api.Command{Commandline: listTestCommand, WorkDirectory: workDir, IsTestRun: true, Environment: config.Environment, TestFramework: config.Framework}, true
+}
+
+func (rspecFramework) ParseListing(ctx context.Context, jsonResults string) (PreTestInfo, error) {
+	return SplitForRSpec(ctx, jsonResults)
+}
+
+func (rspecFramework) ParseResults(ctx context.Context, jsonResults string) ([]TestResult, error) {
+	return parseRspecResults(jsonResults)
+}
+
+func (rspecFramework) UpdateCounts(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	return updateCountsRspec(ctx, out, counts)
+}
+
+// figure out exactly what an rspec output says
+// a file can be split across workers by example so each output only adds the examples it ran
+func updateCountsRspec(ctx context.Context, out *pb.Output, counts *TestCounts) error {
+	if out.JsonResults == "" && len(out.TestResults) == 0 {
+		Logger(ctx).Debug("updateCountsRspec no JSON results!")
+		return nil
+	}
+	err := countOutputResults(out, counts, parseRspecResults)
+	if err != nil {
+		Logger(ctx).Errorf("Error parsing rspec json results %v", err)
+	}
+	return err
+}
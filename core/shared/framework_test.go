@@ -0,0 +1,253 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFrameworkTestCommands(t *testing.T) {
+	command := &api.Command{Commandline: "run", Args: []string{"a", "b"}}
+	tests := []struct {
+		framework string
+		want      string
+	}{
+		{"jest", "run --forceExit --outputFile=/tmp/out a b"},
+		{"Rspec", "run -o /tmp/out a b"},
+		{"CYPRESS", "run --spec a,b"},
+		{"Rails", "run a b"},
+		{"Python", "run --junitxml=/tmp/out -o junit_family=xunit1 a b"},
+		{"Raw", "run"},
+	}
+	for _, tt := range tests {
+		framework, err := GetFramework(tt.framework)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := framework.TestCommand(command, "/tmp/out"); got != tt.want {
+			t.Errorf("%v: expected %q got %q", tt.framework, tt.want, got)
+		}
+	}
+	if _, err := GetFramework("mocha"); err == nil {
+		t.Error("expected an error for an unknown framework")
+	}
+}
+
+func TestFrameworkListing(t *testing.T) {
+	ctx := context.Background()
+	config := &pb.Config{ListTestCommand: "list", Framework: string(types.Rspec)}
+
+	rspec, _ := GetFramework(string(types.Rspec))
+	listCommand, ok := rspec.ListCommand(config, &api.Command{Commandline: "rspec"}, "/remote")
+	if !ok || !listCommand.IsTestRun || listCommand.WorkDirectory != "/remote" {
+		t.Errorf("expected rspec to list with a test run got %v in %v", listCommand.IsTestRun, listCommand.WorkDirectory)
+	}
+	python, _ := GetFramework(string(types.Python))
+	listCommand, ok = python.ListCommand(&pb.Config{Framework: string(types.Python)}, &api.Command{Commandline: "pytest"}, "/remote")
+	if !ok || listCommand.Commandline != PytestListTestCommand || !listCommand.IsListTest {
+		t.Errorf("expected python to collect its tests got %q", listCommand.Commandline)
+	}
+	if _, ok := python.ListCommand(&pb.Config{Framework: string(types.Python), ListTestCommand: "echo 'list tests'"}, &api.Command{Commandline: "pytest"}, "/remote"); ok {
+		t.Error("expected python not to list with a placeholder listTestCommand")
+	}
+	if _, ok := python.ListCommand(&pb.Config{Framework: string(types.Python), ListTestCommand: "./collect.sh", SplitAlgorithm: SplitLPT}, &api.Command{Commandline: "pytest"}, "/remote"); !ok {
+		t.Error("expected python to list with its own listTestCommand when splitAlgorithm is set")
+	}
+	// the CLI doesn't send the commands in the config so it is the command being run that says pytest-split is used
+	if _, ok := python.ListCommand(&pb.Config{Framework: string(types.Python)}, &api.Command{Commandline: "pytest --splits 4 --group 1"}, "/remote"); ok {
+		t.Error("expected python not to list when pytest-split splits the tests")
+	}
+	splitCommand := python.TestCommand(&api.Command{Commandline: "pytest --splits $BRISK_NODE_TOTAL --group 1", Args: []string{"a.py"}}, "/tmp/out")
+	if splitCommand != "pytest --splits $BRISK_NODE_TOTAL --group 1 --junitxml=/tmp/out -o junit_family=xunit1" {
+		t.Errorf("expected pytest-split to pick its own tests got %q", splitCommand)
+	}
+	info, err := python.ParseListing(ctx, `["tests/test_a.py::test_one","tests/test_a.py::TestB::test_two[1]","tests/test_c.py::test_three"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Filenames) != 2 || info.TotalTestCount != 3 {
+		t.Errorf("expected 2 files from 3 tests got %+v", info)
+	}
+
+	raw, _ := GetFramework(string(types.Raw))
+	info, err = raw.ParseListing(ctx, `["a.sh","b.sh","a.sh"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Filenames) != 2 || info.TotalTestCount != 3 {
+		t.Errorf("expected 2 files from 3 tests got %+v", info)
+	}
+}
+
+func TestFrameworkUpdateCounts(t *testing.T) {
+	ctx := context.Background()
+	counts := TestCounts{}
+	rspec, _ := GetFramework(string(types.Rspec))
+	err := rspec.UpdateCounts(ctx, &pb.Output{JsonResults: `{"examples":[{"status":"passed"},{"status":"failed"},{"status":"passed"}],"summary":{"failure_count":1,"pending_count":0}}`}, &counts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.PassCount != 2 || counts.FailCount != 1 {
+		t.Errorf("expected 2 passes and 1 failure got %+v", counts)
+	}
+
+	jest, _ := GetFramework(string(types.Jest))
+	jest.UpdateCounts(ctx, &pb.Output{Stderr: "FAIL src/a.test.js", Stdout: "it PASSES", Command: &api.Command{IsTestRun: true}}, &counts)
+	if counts.FailCount != 1 || counts.PassCount != 2 {
+		t.Errorf("expected jest's output not to be counted got %+v", counts)
+	}
+	err = jest.UpdateCounts(ctx, &pb.Output{JsonResults: `{"testResults":[{"name":"/src/a.test.js","assertionResults":[{"fullName":"a FAILS","status":"passed"},{"fullName":"b","status":"failed"}]}]}`, Command: &api.Command{IsTestRun: true}}, &counts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.FailCount != 2 || counts.PassCount != 3 {
+		t.Errorf("expected jest's results to be counted got %+v", counts)
+	}
+}
+
+func TestGoFramework(t *testing.T) {
+	ctx := context.Background()
+	golang, err := GetFramework("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := &api.Command{Commandline: "go test", Args: []string{"./a", "./b"}}
+	if got := golang.TestCommand(command, "/tmp/out"); got != "set -o pipefail; go test -json ./a ./b | tee /tmp/out" {
+		t.Errorf("unexpected test command %q", got)
+	}
+	listCommand, _ := golang.ListCommand(&pb.Config{Framework: "Go"}, command, "/remote")
+	if listCommand.Commandline != GoListTestCommand || !listCommand.IsListTest {
+		t.Errorf("expected the default go list command got %q", listCommand.Commandline)
+	}
+
+	info, err := golang.ParseListing(ctx, `["example.com/a","example.com/b"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Filenames) != 2 || info.TotalTestCount != 2 {
+		t.Errorf("expected 2 packages got %+v", info)
+	}
+	info, _ = golang.ParseListing(ctx, "example.com/a\nexample.com/b\n")
+	if len(info.Filenames) != 2 {
+		t.Errorf("expected 2 packages from go list output got %+v", info)
+	}
+
+	results := `{"Action":"run","Package":"example.com/a","Test":"TestOk"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOk","Elapsed":0.5}
+{"Action":"run","Package":"example.com/a","Test":"TestBad"}
+{"Action":"output","Package":"example.com/a","Test":"TestBad","Output":"    a_test.go:10: boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad","Elapsed":0.1}
+{"Action":"skip","Package":"example.com/a","Test":"TestLater","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.7}
+# example.com/b
+{"Action":"fail","Package":"example.com/b","Elapsed":0}`
+	cases, err := golang.ParseResults(ctx, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 4 {
+		t.Fatalf("expected 4 test cases got %+v", cases)
+	}
+	if cases[1].Status != ResultFailed || cases[1].FailureMessage != "    a_test.go:10: boom\n" || cases[1].File != "example.com/a" {
+		t.Errorf("unexpected failed case %+v", cases[1])
+	}
+	if cases[2].Status != ResultSkipped || cases[3].Name != "example.com/b" || cases[3].Status != ResultFailed {
+		t.Errorf("unexpected cases %+v", cases)
+	}
+
+	counts := TestCounts{}
+	for _, line := range []string{
+		`{"Action":"run","Package":"example.com/a","Test":"TestOk"}`,
+		`{"Action":"pass","Package":"example.com/a","Test":"TestOk"}`,
+		`{"Action":"pass","Package":"example.com/a","Test":"TestOther"}`,
+		`{"Action":"skip","Package":"example.com/a","Test":"TestLater"}`,
+		`{"Action":"pass","Package":"example.com/a"}`,
+		`{"Action":"fail","Package":"example.com/c","Test":"TestBroken"}`,
+		`{"Action":"fail","Package":"example.com/c"}`,
+		`{"Action":"fail","Package":"example.com/b"}`,
+		"Output file contents:",
+		results,
+	} {
+		golang.UpdateCounts(ctx, &pb.Output{Stdout: line}, &counts)
+	}
+	// the failed test and the package that didn't build, not the package of the failed test
+	if counts.PassCount != 2 || counts.SkipCount != 1 || counts.FailCount != 2 {
+		t.Errorf("expected each test and the package that didn't build to be counted got %+v", counts)
+	}
+}
+
+func TestRspecExampleSplitting(t *testing.T) {
+	ctx := context.Background()
+	rspec, _ := GetFramework(string(types.Rspec))
+	command := &api.Command{Commandline: "bundle exec rspec", Args: []string{"./spec/a_spec.rb", "./spec/big_spec.rb[1:2]"}}
+	if got := rspec.TestCommand(command, "/tmp/out"); got != "bundle exec rspec -o /tmp/out ./spec/a_spec.rb './spec/big_spec.rb[1:2]'" {
+		t.Errorf("expected the example id to be quoted got %q", got)
+	}
+
+	info, err := rspec.ParseListing(ctx, `{"examples":[{"id":"./spec/a_spec.rb[1:1]","file_path":"./spec/a_spec.rb"},
+		{"id":"./spec/big_spec.rb[1:1]","file_path":"./spec/big_spec.rb"},{"id":"./spec/big_spec.rb[1:2]","file_path":"./spec/big_spec.rb"},
+		{"id":"./spec/big_spec.rb[2:1]","file_path":"./spec/big_spec.rb"}],"summary":{"example_count":4}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.ExampleIds["./spec/big_spec.rb"]) != 3 {
+		t.Fatalf("expected 3 examples in the big file got %v", info.ExampleIds)
+	}
+
+	history := &SplitHistory{Timings: map[string]FileTiming{"./spec/a_spec.rb": {Duration: time.Second}, "./spec/big_spec.rb": {Duration: 90 * time.Second}}}
+	files := SplitSlowRspecFiles(ctx, []string{"./spec/a_spec.rb", "./spec/big_spec.rb"}, info.ExampleIds, time.Minute, history)
+	if len(files) != 4 || files[0] != "./spec/a_spec.rb" || files[1] != "./spec/big_spec.rb[1:1]" {
+		t.Errorf("expected the big file to be split by example got %v", files)
+	}
+	if history.Timings["./spec/big_spec.rb[2:1]"].Duration != 30*time.Second {
+		t.Errorf("expected each example to get a share of the file got %v", history.Timings)
+	}
+	if files := SplitSlowRspecFiles(ctx, []string{"./spec/big_spec.rb"}, info.ExampleIds, 0, history); len(files) != 1 {
+		t.Errorf("expected no splitting without a threshold got %v", files)
+	}
+
+	// two workers each ran some of the examples of the big file
+	counts := TestCounts{}
+	rspec.UpdateCounts(ctx, &pb.Output{JsonResults: `{"examples":[{"id":"./spec/big_spec.rb[1:1]","status":"passed"},{"id":"./spec/big_spec.rb[1:2]","status":"pending"}],"summary":{"pending_count":1}}`}, &counts)
+	rspec.UpdateCounts(ctx, &pb.Output{JsonResults: `{"examples":[{"id":"./spec/big_spec.rb[2:1]","status":"failed"}],"summary":{"failure_count":1}}`}, &counts)
+	if counts.PassCount != 1 || counts.FailCount != 1 || counts.SkipCount != 1 {
+		t.Errorf("expected the examples from both workers to be counted once got %+v", counts)
+	}
+}
+
+func TestCheckOnlyFiles(t *testing.T) {
+	tests := []struct {
+		framework types.Framework
+		command   *api.Command
+		wantErr   bool
+	}{
+		{types.Jest, &api.Command{Commandline: "yarn test"}, false},
+		{types.Rspec, &api.Command{Commandline: "bundle exec rspec"}, false},
+		{types.Python, &api.Command{Commandline: "pytest"}, false},
+		{types.Python, &api.Command{Commandline: "pytest --splits $BRISK_NODE_TOTAL --group 1"}, true},
+		{types.Raw, &api.Command{Commandline: "./run_tests.sh"}, true},
+		{types.Raw, &api.Command{Commandline: "./build.sh", NoTestFiles: true}, false},
+	}
+	for _, tt := range tests {
+		if err := CheckOnlyFiles(string(tt.framework), tt.command); (err != nil) != tt.wantErr {
+			t.Errorf("CheckOnlyFiles(%v, %q) error = %v, wantErr %v", tt.framework, tt.command.Commandline, err, tt.wantErr)
+		}
+	}
+}
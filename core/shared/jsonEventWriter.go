@@ -0,0 +1,100 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// OutputEvent is the machine readable form of a pb.Output, one per line when using --output=json
+type OutputEvent struct {
+	Timestamp      string `json:"timestamp"`
+	WorkerNumber   *int32 `json:"workerNumber,omitempty"`
+	WorkerUid      string `json:"workerUid,omitempty"`
+	Stage          string `json:"stage,omitempty"`
+	StageStatus    string `json:"stageStatus,omitempty"`
+	CommandId      string `json:"commandId,omitempty"`
+	Commandline    string `json:"commandline,omitempty"`
+	Response       string `json:"response,omitempty"`
+	Stdout         string `json:"stdout,omitempty"`
+	Stderr         string `json:"stderr,omitempty"`
+	Control        string `json:"control,omitempty"`
+	ExitCode       int32  `json:"exitCode"`
+	Error          string `json:"error,omitempty"`
+	TotalTestCount int32  `json:"totalTestCount"`
+	TotalTestPass  int32  `json:"totalTestPass"`
+	TotalTestFail  int32  `json:"totalTestFail"`
+	TotalTestSkip  int32  `json:"totalTestSkip"`
+	TotalTestFlaky int32  `json:"totalTestFlaky"`
+}
+
+func NewOutputEvent(output *pb.Output) OutputEvent {
+	event := OutputEvent{
+		Response:       output.Response,
+		Stdout:         output.Stdout,
+		Stderr:         output.Stderr,
+		Control:        output.Control,
+		ExitCode:       output.Exitcode,
+		Stage:          output.Stage,
+		StageStatus:    output.StageStatus,
+		TotalTestCount: output.TotalTestCount,
+		TotalTestPass:  output.TotalTestPass,
+		TotalTestFail:  output.TotalTestFail,
+		TotalTestSkip:  output.TotalTestSkip,
+		TotalTestFlaky: output.TotalTestFlaky,
+	}
+	if output.Created != nil {
+		event.Timestamp = output.Created.AsTime().Format(time.RFC3339Nano)
+	} else {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if output.Worker != nil {
+		number := output.Worker.Number
+		event.WorkerNumber = &number
+		event.WorkerUid = output.Worker.Uid
+	}
+	if output.Command != nil {
+		event.CommandId = output.Command.CommandId
+		event.Commandline = output.Command.Commandline
+		if event.Stage == "" {
+			event.Stage = output.Command.Stage
+		}
+	}
+	if output.BriskError != nil {
+		event.Error = output.BriskError.Error
+	}
+	return event
+}
+
+// JsonEventWriter writes each output as a single line of JSON (NDJSON)
+type JsonEventWriter struct {
+	Output io.Writer
+	mu     sync.Mutex
+}
+
+func (j *JsonEventWriter) WriteEvent(output *pb.Output) error {
+	line, err := json.Marshal(NewOutputEvent(output))
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.Output.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJsonEventWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := JsonEventWriter{Output: buf}
+
+	err := writer.WriteEvent(&pb.Output{Stdout: "PASS a.test.js", Worker: &pb.Worker{Number: 0, Uid: "w1"}, Command: &api.Command{CommandId: "unit", Stage: "Run"}, TotalTestPass: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.WriteEvent(&pb.Output{Control: "Finished", Exitcode: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines got %v", len(lines))
+	}
+	var first OutputEvent
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.WorkerNumber == nil || *first.WorkerNumber != 0 || first.WorkerUid != "w1" || first.CommandId != "unit" || first.Stage != "Run" || first.TotalTestPass != 4 {
+		t.Errorf("unexpected event %+v", first)
+	}
+	var second OutputEvent
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if second.WorkerNumber != nil || second.Control != "Finished" || second.ExitCode != 1 || second.Timestamp == "" {
+		t.Errorf("unexpected event %+v", second)
+	}
+}
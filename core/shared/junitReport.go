@@ -0,0 +1,247 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JunitReport collects the test results from every worker in a run so we can write a single JUnit XML file at the end
+type JunitReport struct {
+	mu        sync.Mutex
+	TestCases []TestResult
+	index     map[string]int
+}
+
+func NewJunitReport() *JunitReport {
+	return &JunitReport{index: map[string]int{}}
+}
+
+// AddOutput records the test results carried in the JsonResults of a test run output
+// outputs without results (or from list commands) are ignored
+func (r *JunitReport) AddOutput(ctx context.Context, framework string, out *pb.Output) error {
+	testCases, err := OutputTestResults(ctx, framework, out)
+	if err != nil {
+		Logger(ctx).Errorf("Error parsing test results for JUnit report %v", err)
+		return err
+	}
+	if len(testCases) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.index == nil {
+		r.index = map[string]int{}
+	}
+	before := len(r.TestCases)
+	for _, tc := range testCases {
+		key := tc.File + "\x00" + tc.Name
+		// a test we have already seen in an earlier output is a retry so the latest result wins
+		if i, ok := r.index[key]; ok && i < before {
+			tc.Flaky = r.TestCases[i].Flaky || (r.TestCases[i].Status == ResultFailed && tc.Status == ResultPassed)
+			r.TestCases[i] = tc
+			continue
+		}
+		r.index[key] = len(r.TestCases)
+		r.TestCases = append(r.TestCases, tc)
+	}
+	return nil
+}
+
+type junitXmlTestSuites struct {
+	XMLName  xml.Name            `xml:"testsuites"`
+	Name     string              `xml:"name,attr"`
+	Tests    int                 `xml:"tests,attr"`
+	Failures int                 `xml:"failures,attr"`
+	Skipped  int                 `xml:"skipped,attr"`
+	Time     string              `xml:"time,attr"`
+	Suites   []junitXmlTestSuite `xml:"testsuite"`
+}
+
+type junitXmlTestSuite struct {
+	Name      string             `xml:"name,attr"`
+	Tests     int                `xml:"tests,attr"`
+	Failures  int                `xml:"failures,attr"`
+	Skipped   int                `xml:"skipped,attr"`
+	Time      string             `xml:"time,attr"`
+	Hostname  string             `xml:"hostname,attr,omitempty"`
+	TestCases []junitXmlTestCase `xml:"testcase"`
+}
+
+type junitXmlTestCase struct {
+	Name       string              `xml:"name,attr"`
+	Classname  string              `xml:"classname,attr"`
+	File       string              `xml:"file,attr"`
+	Time       string              `xml:"time,attr"`
+	Properties *junitXmlProperties `xml:"properties,omitempty"`
+	Failure    *junitXmlFailure    `xml:"failure,omitempty"`
+	Error      *junitXmlFailure    `xml:"error,omitempty"`
+	Skipped    *struct{}           `xml:"skipped,omitempty"`
+}
+
+type junitXmlProperties struct {
+	Properties []junitXmlProperty `xml:"property"`
+}
+
+type junitXmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitXmlFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Marshal builds the JUnit XML for all the test cases we have collected, with one testsuite per test file
+func (r *JunitReport) Marshal() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suites := map[string]*junitXmlTestSuite{}
+	suiteDurations := map[string]time.Duration{}
+	root := junitXmlTestSuites{Name: "brisk"}
+	var total time.Duration
+
+	for _, tc := range r.TestCases {
+		suite, ok := suites[tc.File]
+		if !ok {
+			suite = &junitXmlTestSuite{Name: tc.File, Hostname: tc.WorkerUid}
+			suites[tc.File] = suite
+		}
+		classname := tc.Suite
+		if classname == "" {
+			classname = tc.File
+		}
+		xmlCase := junitXmlTestCase{
+			Name:      tc.Name,
+			Classname: classname,
+			File:      tc.File,
+			Time:      junitSeconds(tc.Duration),
+			Properties: &junitXmlProperties{Properties: []junitXmlProperty{
+				{Name: "worker", Value: fmt.Sprint(tc.WorkerNumber)},
+				{Name: "worker_uid", Value: tc.WorkerUid},
+			}},
+		}
+		if tc.Attempt > 0 {
+			xmlCase.Properties.Properties = append(xmlCase.Properties.Properties, junitXmlProperty{Name: "attempt", Value: fmt.Sprint(tc.Attempt)})
+		}
+		if tc.Flaky {
+			xmlCase.Properties.Properties = append(xmlCase.Properties.Properties, junitXmlProperty{Name: "flaky", Value: "true"})
+		}
+		switch tc.Status {
+		case ResultFailed:
+			xmlCase.Failure = &junitXmlFailure{Message: firstLine(tc.FailureMessage), Content: tc.FailureMessage}
+			suite.Failures++
+			root.Failures++
+		case ResultSkipped:
+			xmlCase.Skipped = &struct{}{}
+			suite.Skipped++
+			root.Skipped++
+		}
+		suite.Tests++
+		root.Tests++
+		suiteDurations[tc.File] += tc.Duration
+		total += tc.Duration
+		suite.TestCases = append(suite.TestCases, xmlCase)
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		suite := suites[name]
+		suite.Time = junitSeconds(suiteDurations[name])
+		root.Suites = append(root.Suites, *suite)
+	}
+	root.Time = junitSeconds(total)
+
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// WriteFile writes the aggregated report to filename, creating any missing directories
+func (r *JunitReport) WriteFile(ctx context.Context, filename string) error {
+	out, err := r.Marshal()
+	if err != nil {
+		Logger(ctx).Errorf("Error building JUnit report %v", err)
+		return err
+	}
+	if dir := filepath.Dir(filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	Logger(ctx).Debugf("Writing JUnit report with %v test cases to %v", len(r.TestCases), filename)
+	return os.WriteFile(filename, out, 0644)
+}
+
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// FailedFiles gives back the sorted, de-duplicated list of test files that had at least one failure
+func (r *JunitReport) FailedFiles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := map[string]bool{}
+	files := []string{}
+	for _, tc := range r.TestCases {
+		if tc.Status == ResultFailed && tc.File != "" && !seen[tc.File] {
+			seen[tc.File] = true
+			files = append(files, tc.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// FlakyTests gives back the tests that only passed after being retried
+func (r *JunitReport) FlakyTests() []TestResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	flaky := []TestResult{}
+	for _, tc := range r.TestCases {
+		if tc.Flaky {
+			flaky = append(flaky, tc)
+		}
+	}
+	return flaky
+}
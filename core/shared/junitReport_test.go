@@ -0,0 +1,154 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJunitReportJest(t *testing.T) {
+	ctx := context.Background()
+	jestJson := `{"testResults":[{"name":"/src/a.test.js","status":"failed","assertionResults":[
+		{"fullName":"a works","status":"passed","duration":12,"failureMessages":[]},
+		{"fullName":"a breaks","status":"failed","duration":3,"failureMessages":["expected 1\nreceived 2"]},
+		{"fullName":"a later","status":"pending","duration":null,"failureMessages":[]}]}]}`
+
+	report := NewJunitReport()
+	err := report.AddOutput(ctx, "Jest", &pb.Output{JsonResults: jestJson, Command: &api.Command{IsTestRun: true}, Worker: &pb.Worker{Number: 3, Uid: "abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// list test output should be ignored
+	err = report.AddOutput(ctx, "Jest", &pb.Output{JsonResults: `["/src/a.test.js"]`, Command: &api.Command{IsListTest: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.TestCases) != 3 {
+		t.Fatalf("expected 3 test cases got %v", len(report.TestCases))
+	}
+
+	if failed := report.FailedFiles(); len(failed) != 1 || failed[0] != "/src/a.test.js" {
+		t.Errorf("expected only /src/a.test.js to have failed got %v", failed)
+	}
+
+	out, err := report.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(out)
+	for _, want := range []string{`tests="3" failures="1" skipped="1"`, `<property name="worker" value="3">`, `message="expected 1"`, `file="/src/a.test.js"`} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("expected report to contain %v got %v", want, xml)
+		}
+	}
+}
+
+func TestJunitReportRetry(t *testing.T) {
+	ctx := context.Background()
+	firstRun := `{"testResults":[{"name":"/src/b.test.js","status":"failed","assertionResults":[
+		{"fullName":"b passes","status":"passed","duration":1,"failureMessages":[]},
+		{"fullName":"b flakes","status":"failed","duration":1,"failureMessages":["timeout"]}]}]}`
+	retry := `{"testResults":[{"name":"/src/b.test.js","status":"passed","assertionResults":[
+		{"fullName":"b passes","status":"passed","duration":1,"failureMessages":[]},
+		{"fullName":"b flakes","status":"passed","duration":1,"failureMessages":[]}]}]}`
+
+	report := NewJunitReport()
+	for _, results := range []string{firstRun, retry} {
+		err := report.AddOutput(ctx, "Jest", &pb.Output{JsonResults: results, Command: &api.Command{IsTestRun: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(report.TestCases) != 2 {
+		t.Fatalf("expected the retry to replace the earlier results but have %v test cases", len(report.TestCases))
+	}
+	if len(report.FailedFiles()) != 0 {
+		t.Errorf("expected no failed files after the retry got %v", report.FailedFiles())
+	}
+	flaky := report.FlakyTests()
+	if len(flaky) != 1 || flaky[0].Name != "b flakes" {
+		t.Errorf("expected b flakes to be flaky got %+v", flaky)
+	}
+}
+
+func TestJunitReportRspec(t *testing.T) {
+	ctx := context.Background()
+	rspecJson := `{"examples":[{"full_description":"User is valid","status":"passed","file_path":"./spec/user_spec.rb","run_time":0.5},
+		{"full_description":"User is invalid","status":"failed","file_path":"./spec/user_spec.rb","run_time":0.25,"exception":{"class":"RSpec::Expectations::ExpectationNotMetError","message":"expected true"}}]}`
+
+	report := NewJunitReport()
+	err := report.AddOutput(ctx, "Rspec", &pb.Output{JsonResults: rspecJson, Command: &api.Command{IsTestRun: true}, Worker: &pb.Worker{Number: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := report.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(out)
+	for _, want := range []string{`tests="2" failures="1" skipped="0" time="0.750"`, `RSpec::Expectations::ExpectationNotMetError: expected true`} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("expected report to contain %v got %v", want, xml)
+		}
+	}
+}
+
+func TestJunitReportPytest(t *testing.T) {
+	ctx := context.Background()
+	pytestXml := `<?xml version="1.0" encoding="utf-8"?><testsuites><testsuite name="pytest" errors="0" failures="1" skipped="1" tests="4" time="1.2">
+<testcase classname="tests.test_user" name="test_valid" file="tests/test_user.py" line="3" time="0.5" />
+<testcase classname="tests.test_user.TestUser" name="test_invalid" time="0.25"><failure message="assert False">def test_invalid():
+&gt;       assert False</failure></testcase>
+<testcase classname="tests.test_user.TestUser" name="test_later" time="0.0"><skipped message="later" /></testcase>
+<testcase classname="tests.test_db" name="test_setup" time="0.1"><error message="fixture failed" /></testcase>
+</testsuite></testsuites>`
+
+	report := NewJunitReport()
+	err := report.AddOutput(ctx, "Python", &pb.Output{JsonResults: pytestXml, Command: &api.Command{IsTestRun: true}, Worker: &pb.Worker{Number: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.TestCases) != 4 || report.TestCases[1].File != "tests/test_user.py" || report.TestCases[3].File != "tests/test_db.py" {
+		t.Fatalf("unexpected test cases %+v", report.TestCases)
+	}
+	if report.TestCases[3].Status != ResultFailed || report.TestCases[2].Status != ResultSkipped {
+		t.Errorf("expected the error to fail and the skip to skip got %+v", report.TestCases)
+	}
+	failed := report.FailedFiles()
+	if len(failed) != 2 {
+		t.Errorf("expected 2 failed files got %v", failed)
+	}
+
+	counts := TestCounts{}
+	python, _ := GetFramework("Python")
+	python.UpdateCounts(ctx, &pb.Output{JsonResults: pytestXml, Command: &api.Command{IsTestRun: true}}, &counts)
+	if counts.PassCount != 1 || counts.FailCount != 2 || counts.SkipCount != 1 {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	// older pytest versions write a single testsuite as the root
+	cases, err := parseJunitXmlResults(`<testsuite name="pytest"><testcase classname="test_a" name="test_one" time="1"/></testsuite>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || cases[0].File != "test_a.py" || cases[0].Duration != time.Second {
+		t.Errorf("unexpected cases %+v", cases)
+	}
+}
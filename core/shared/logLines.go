@@ -0,0 +1,227 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// each line of a stored log says when it was output and where it came from so the logs of the workers can be merged
+// and filtered
+//
+//	2024-05-01T10:00:01.123456Z worker 3/a1b2c3d4 command "unit" : PASS src/a.test.js
+//
+// lines from the supervisor have no worker and lines of a command without an id have no command
+
+// fixed width so the lines of a log sort by time
+const logTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// LogLine is a line of a stored log, Line is the line as it was stored
+type LogLine struct {
+	Time time.Time
+	// Worker is -1 for lines from the supervisor
+	Worker    int32
+	WorkerUid string
+	CommandId string
+	Text      string
+	Line      string
+}
+
+// OutputLogLines are the lines an output is stored as, stderr then stdout then the response when it is something else
+func OutputLogLines(output *pb.Output) []LogLine {
+	var texts []string
+	if output.Stderr != "" {
+		texts = append(texts, output.Stderr)
+	}
+	if output.Stdout != "" {
+		texts = append(texts, output.Stdout)
+	}
+	// only want to share when it's not the same as stderr or stdout
+	if output.Response != "" && output.Response != output.Stderr && output.Response != output.Stdout {
+		texts = append(texts, output.Response)
+	}
+
+	line := LogLine{Time: time.Now(), Worker: -1}
+	if output.Created != nil {
+		line.Time = output.Created.AsTime()
+	}
+	line.Time = line.Time.UTC().Truncate(time.Microsecond)
+	if output.Worker != nil {
+		line.Worker = output.Worker.Number
+		line.WorkerUid = output.Worker.Uid
+	}
+	if output.Command != nil {
+		line.CommandId = output.Command.CommandId
+	}
+
+	var lines []LogLine
+	for _, text := range texts {
+		for _, t := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+			l := line
+			l.Text = t
+			l.Line = formatLogLine(l)
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func formatLogLine(l LogLine) string {
+	var b strings.Builder
+	b.WriteString(l.Time.Format(logTimeFormat))
+	if l.Worker >= 0 {
+		fmt.Fprintf(&b, " worker %v/%v", l.Worker, l.WorkerUid)
+	}
+	if l.CommandId != "" {
+		b.WriteString(" command ")
+		b.WriteString(strconv.Quote(l.CommandId))
+	}
+	b.WriteString(" : ")
+	b.WriteString(l.Text)
+	return b.String()
+}
+
+// WriteLogLines writes the output to a log being stored
+func WriteLogLines(w io.Writer, output *pb.Output) error {
+	for _, line := range OutputLogLines(output) {
+		if _, err := io.WriteString(w, line.Line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseLogLine is false for a line that wasn't written by WriteLogLines
+func ParseLogLine(line string) (LogLine, bool) {
+	timeText, rest, _ := strings.Cut(line, " ")
+	t, err := time.Parse(logTimeFormat, timeText)
+	if err != nil {
+		return LogLine{}, false
+	}
+	l := LogLine{Time: t, Worker: -1, Line: line}
+	if after, ok := strings.CutPrefix(rest, "worker "); ok {
+		var worker string
+		worker, rest, _ = strings.Cut(after, " ")
+		number, uid, _ := strings.Cut(worker, "/")
+		n, err := strconv.ParseInt(number, 10, 32)
+		if err != nil {
+			return LogLine{}, false
+		}
+		l.Worker = int32(n)
+		l.WorkerUid = uid
+	}
+	if after, ok := strings.CutPrefix(rest, "command "); ok {
+		quoted, err := strconv.QuotedPrefix(after)
+		if err != nil {
+			return LogLine{}, false
+		}
+		l.CommandId, _ = strconv.Unquote(quoted)
+		rest = strings.TrimPrefix(after[len(quoted):], " ")
+	}
+	text, ok := strings.CutPrefix(rest, ": ")
+	if !ok {
+		return LogLine{}, false
+	}
+	l.Text = text
+	return l, true
+}
+
+// ReadLogLines reads a stored log. Lines that don't parse, from logs stored before lines said where they came from,
+// are taken to be from the same place and time as the line before.
+func ReadLogLines(r io.Reader) ([]LogLine, error) {
+	var lines []LogLine
+	previous := LogLine{Worker: -1}
+	br := bufio.NewReader(r)
+	for {
+		text, err := br.ReadString('\n')
+		if text != "" {
+			text = strings.TrimSuffix(text, "\n")
+			line, ok := ParseLogLine(text)
+			if !ok {
+				line = previous
+				line.Text = text
+				line.Line = text
+			}
+			lines = append(lines, line)
+			previous = line
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+// MergeLogLines puts the lines of the logs in the order they were output, lines output at the same time keep the
+// order of the logs they came from
+func MergeLogLines(logs ...[]LogLine) []LogLine {
+	var merged []LogLine
+	for _, log := range logs {
+		merged = append(merged, log...)
+	}
+	slices.SortStableFunc(merged, func(a, b LogLine) int {
+		return a.Time.Compare(b.Time)
+	})
+	return merged
+}
+
+// LogFilter picks the lines of a log to show, a Worker of -1 is every worker. Lines from the supervisor are left out
+// when only some workers or commands are wanted.
+type LogFilter struct {
+	Worker    int32
+	CommandId string
+	Grep      *regexp.Regexp
+}
+
+// FromWorker is whether lines from the worker and command are wanted, before looking at what they say
+func (f LogFilter) FromWorker(worker int32, commandId string) bool {
+	if (f.Worker >= 0 || f.CommandId != "") && worker < 0 {
+		return false
+	}
+	if f.Worker >= 0 && worker != f.Worker {
+		return false
+	}
+	return f.CommandId == "" || commandId == f.CommandId
+}
+
+func (f LogFilter) Matches(line LogLine) bool {
+	if !f.FromWorker(line.Worker, line.CommandId) {
+		return false
+	}
+	return f.Grep == nil || f.Grep.MatchString(line.Line)
+}
+
+// WorkerLog is one worker's log for one command of a run, the index of them is stored with the run
+type WorkerLog struct {
+	Uid       string `json:"uid"`
+	Worker    int32  `json:"worker"`
+	CommandId string `json:"commandId,omitempty"`
+}
+
+// WorkerLogIndexUid is where the index of the worker logs of a run is stored
+func WorkerLogIndexUid(runId string) string {
+	return runId + ".workers"
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestLogLines(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(ms int) *timestamppb.Timestamp {
+		return timestamppb.New(start.Add(time.Duration(ms) * time.Millisecond))
+	}
+
+	buf := &bytes.Buffer{}
+	err := WriteLogLines(buf, &pb.Output{Stderr: "oops", Stdout: "PASS a.test.js\nPASS b.test.js\n", Response: "PASS a.test.js\nPASS b.test.js\n", Worker: &pb.Worker{Number: 3, Uid: "w3"}, Command: &api.Command{CommandId: `unit : "fast"`}, Created: at(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `2024-05-01T10:00:00.001000Z worker 3/w3 command "unit : \"fast\"" : oops
+2024-05-01T10:00:00.001000Z worker 3/w3 command "unit : \"fast\"" : PASS a.test.js
+2024-05-01T10:00:00.001000Z worker 3/w3 command "unit : \"fast\"" : PASS b.test.js
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected log\n%v", buf.String())
+	}
+	lines, err := ReadLogLines(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || lines[2].Worker != 3 || lines[2].WorkerUid != "w3" || lines[2].CommandId != `unit : "fast"` || lines[2].Text != "PASS b.test.js" || !lines[2].Time.Equal(at(1).AsTime()) {
+		t.Errorf("unexpected lines %+v", lines)
+	}
+
+	supervisor := OutputLogLines(&pb.Output{Response: "error from test run", Created: at(2)})
+	if len(supervisor) != 1 || supervisor[0].Line != "2024-05-01T10:00:00.002000Z : error from test run" {
+		t.Errorf("unexpected supervisor line %+v", supervisor)
+	}
+	if line, ok := ParseLogLine(supervisor[0].Line); !ok || line.Worker != -1 || line.CommandId != "" || line.Text != "error from test run" {
+		t.Errorf("unexpected parse %+v %v", line, ok)
+	}
+
+	// logs stored before lines said where they came from
+	old, err := ReadLogLines(strings.NewReader("w1 : PASS a.test.js \nw1 : PASS b.test.js "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old) != 2 || old[0].Worker != -1 || old[1].Text != "w1 : PASS b.test.js " {
+		t.Errorf("unexpected old lines %+v", old)
+	}
+
+	worker0 := append(OutputLogLines(&pb.Output{Stdout: "first", Worker: &pb.Worker{Number: 0}, Created: at(10)}), OutputLogLines(&pb.Output{Stdout: "third", Worker: &pb.Worker{Number: 0}, Created: at(30)})...)
+	worker1 := append(OutputLogLines(&pb.Output{Stdout: "second", Worker: &pb.Worker{Number: 1}, Command: &api.Command{CommandId: "lint"}, Created: at(20)}), OutputLogLines(&pb.Output{Stdout: "also third", Worker: &pb.Worker{Number: 1}, Command: &api.Command{CommandId: "lint"}, Created: at(30)})...)
+	var texts []string
+	for _, line := range MergeLogLines(supervisor, worker0, worker1) {
+		texts = append(texts, line.Text)
+	}
+	if strings.Join(texts, ",") != "error from test run,first,second,third,also third" {
+		t.Errorf("unexpected order %v", texts)
+	}
+
+	filters := []struct {
+		filter   LogFilter
+		expected string
+	}{
+		{LogFilter{Worker: -1}, "error from test run,first,second,third,also third"},
+		{LogFilter{Worker: 1}, "second,also third"},
+		{LogFilter{Worker: -1, CommandId: "lint"}, "second,also third"},
+		{LogFilter{Worker: 0, CommandId: "lint"}, ""},
+		{LogFilter{Worker: -1, Grep: regexp.MustCompile("third$")}, "third,also third"},
+		{LogFilter{Worker: -1, Grep: regexp.MustCompile("worker 0/")}, "first,third"},
+	}
+	for _, f := range filters {
+		var matched []string
+		for _, line := range MergeLogLines(supervisor, worker0, worker1) {
+			if f.filter.Matches(line) {
+				matched = append(matched, line.Text)
+			}
+		}
+		if strings.Join(matched, ",") != f.expected {
+			t.Errorf("expected %q for %+v got %v", f.expected, f.filter, matched)
+		}
+	}
+}
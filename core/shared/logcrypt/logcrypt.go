@@ -0,0 +1,211 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// stored logs are encrypted with a key made for each run. They are split into chunks sealed with AES-256-GCM so they
+// can be streamed, the nonce of each chunk is a random prefix, the chunk number and whether it is the last chunk so
+// chunks can't be reordered, dropped or cut off.
+//
+//	magic | nonce prefix | (chunk length uint32 | sealed chunk)...
+
+var logMagic = []byte("BRISKLOG1")
+
+const (
+	logNoncePrefixSize = 7
+	logChunkSize       = 64 * 1024
+)
+
+var LogDecryptionError = errors.New("can't decrypt the log")
+
+// NewLogEncryptionKey makes the key for the logs of a run, base64 so it can go in RunInfo
+func NewLogEncryptionKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func logCipher(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("a log encryption key is 32 bytes in base64")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func logNonce(prefix []byte, chunk uint32, last bool) []byte {
+	nonce := make([]byte, 0, logNoncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, chunk)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// IsEncryptedLog is true when the log starts like one written by LogEncrypter, logs stored before they were encrypted
+// are plain text
+func IsEncryptedLog(start []byte) bool {
+	return bytes.HasPrefix(start, logMagic)
+}
+
+// LogEncrypter encrypts everything written to it, Close has to be called to write the last chunk. Nothing is written
+// until the first chunk is sealed so it can be made before anything reads what it writes.
+type LogEncrypter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	chunk   uint32
+	buf     []byte
+	started bool
+}
+
+func NewLogEncrypter(w io.Writer, key string) (*LogEncrypter, error) {
+	aead, err := logCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, logNoncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	return &LogEncrypter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, logChunkSize)}, nil
+}
+
+func (e *LogEncrypter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// a full chunk is only sealed once there is more, so the last chunk is never empty unless the log is
+		if len(e.buf) == logChunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):logChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *LogEncrypter) seal(last bool) error {
+	if !e.started {
+		if _, err := e.w.Write(append(append([]byte{}, logMagic...), e.prefix...)); err != nil {
+			return err
+		}
+		e.started = true
+	}
+	sealed := e.aead.Seal(nil, logNonce(e.prefix, e.chunk, last), e.buf, nil)
+	if _, err := e.w.Write(binary.BigEndian.AppendUint32(nil, uint32(len(sealed)))); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.chunk++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Close writes the last chunk, it doesn't close the writer underneath
+func (e *LogEncrypter) Close() error {
+	return e.seal(true)
+}
+
+// NewLogDecrypter reads a log written by LogEncrypter, a log that has been changed or cut short gives
+// LogDecryptionError
+func NewLogDecrypter(r io.Reader, key string) (io.Reader, error) {
+	aead, err := logCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	header := make([]byte, len(logMagic)+logNoncePrefixSize)
+	if _, err := io.ReadFull(br, header); err != nil || !IsEncryptedLog(header) {
+		return nil, fmt.Errorf("%w: it isn't an encrypted log", LogDecryptionError)
+	}
+	return &logDecrypter{r: br, aead: aead, prefix: header[len(logMagic):]}, nil
+}
+
+type logDecrypter struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	chunk  uint32
+	plain  []byte
+	done   bool
+}
+
+func (d *logDecrypter) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *logDecrypter) open() error {
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		return fmt.Errorf("%w: it was cut short", LogDecryptionError)
+	}
+	length := binary.BigEndian.Uint32(size[:])
+	if length > logChunkSize+uint32(d.aead.Overhead()) {
+		return fmt.Errorf("%w: chunk %v is too big", LogDecryptionError, d.chunk)
+	}
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("%w: it was cut short", LogDecryptionError)
+	}
+	// only the last chunk is sealed as the last, so whether there is more to read says which nonce it has
+	_, peekErr := d.r.Peek(1)
+	if peekErr != nil && peekErr != io.EOF {
+		return peekErr
+	}
+	last := peekErr == io.EOF
+	plain, err := d.aead.Open(nil, logNonce(d.prefix, d.chunk, last), sealed, nil)
+	if err != nil {
+		return fmt.Errorf("%w: the key is wrong or chunk %v has been changed", LogDecryptionError, d.chunk)
+	}
+	d.chunk++
+	d.plain = plain
+	d.done = last
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcrypt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func encryptLog(t *testing.T, key string, log []byte) []byte {
+	var encrypted bytes.Buffer
+	encrypter, err := NewLogEncrypter(&encrypted, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encrypter.Write(log); err != nil {
+		t.Fatal(err)
+	}
+	if err := encrypter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return encrypted.Bytes()
+}
+
+func decryptLog(key string, encrypted []byte) ([]byte, error) {
+	decrypter, err := NewLogDecrypter(bytes.NewReader(encrypted), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decrypter)
+}
+
+func TestLogEncryption(t *testing.T) {
+	key, err := NewLogEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, log := range [][]byte{nil, []byte("12:00:01 worker 1 : PASS src/app.test.js\n"), bytes.Repeat([]byte("customer@example.com\n"), 2*logChunkSize/21+7), bytes.Repeat([]byte("x"), logChunkSize)} {
+		encrypted := encryptLog(t, key, log)
+		if len(log) > 0 && bytes.Contains(encrypted, log[:16]) {
+			t.Error("expected the log not to be in plain text")
+		}
+		if !IsEncryptedLog(encrypted) {
+			t.Error("expected the log to look encrypted")
+		}
+		got, err := decryptLog(key, encrypted)
+		if err != nil || !bytes.Equal(got, log) {
+			t.Errorf("expected %v bytes back got %v %v", len(log), len(got), err)
+		}
+	}
+
+	log := bytes.Repeat([]byte("line of output\n"), logChunkSize/15*3)
+	encrypted := encryptLog(t, key, log)
+	otherKey, _ := NewLogEncryptionKey()
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)/2] ^= 1
+	firstChunk := len(logMagic) + logNoncePrefixSize + 4 + logChunkSize + 16
+	for name, bad := range map[string][]byte{
+		"wrong key":      nil,
+		"tampered":       tampered,
+		"cut short":      encrypted[:len(encrypted)-10],
+		"cut at a chunk": encrypted[:firstChunk],
+		"plain text":     []byte("12:00:01 worker 1 : PASS\n"),
+	} {
+		decryptKey := key
+		if bad == nil {
+			bad, decryptKey = encrypted, otherKey
+		}
+		if _, err := decryptLog(decryptKey, bad); !errors.Is(err, LogDecryptionError) {
+			t.Errorf("%v: expected LogDecryptionError got %v", name, err)
+		}
+	}
+
+	if _, err := NewLogEncrypter(io.Discard, "not a key"); err == nil || !strings.Contains(err.Error(), "32 bytes") {
+		t.Errorf("expected a bad key to be rejected got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DefaultReadyTimeout is how long a background command has to be ready when its readyCheck doesn't have a timeout
+const DefaultReadyTimeout = 60 * time.Second
+
+// how often the port, url and probe command are tried
+const readyCheckInterval = 250 * time.Millisecond
+
+var NotReadyError = errors.New("background command isn't ready")
+
+// ReadyCheck is how the worker knows a background command such as a dev server or a database is up, every check that
+// is set has to pass before the next command runs
+type ReadyCheck struct {
+	Port int `json:"port,omitempty"`
+	// Url has to give a 200
+	Url string `json:"url,omitempty"`
+	// Log is a regex matched against each line of stdout and stderr
+	Log     string `json:"log,omitempty"`
+	Command string `json:"command,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Proto checks the readyCheck and converts it for the worker
+func (r *ReadyCheck) Proto() (*api.ReadyCheck, error) {
+	if r == nil {
+		return nil, nil
+	}
+	if r.Port == 0 && r.Url == "" && r.Log == "" && r.Command == "" {
+		return nil, errors.New("readyCheck needs a port, url, log or command")
+	}
+	if r.Port < 0 || r.Port > 65535 {
+		return nil, fmt.Errorf("readyCheck port %v is not a port", r.Port)
+	}
+	if r.Url != "" {
+		u, err := url.Parse(r.Url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("readyCheck url %q is not an http or https url", r.Url)
+		}
+	}
+	if r.Log != "" {
+		if _, err := regexp.Compile(r.Log); err != nil {
+			return nil, fmt.Errorf("readyCheck log %q: %w", r.Log, err)
+		}
+	}
+	check := &api.ReadyCheck{Port: int32(r.Port), Url: r.Url, Log: r.Log, Command: r.Command}
+	if r.Timeout != "" {
+		timeout, err := time.ParseDuration(r.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("readyCheck timeout %q is not a duration like 30s or 2m", r.Timeout)
+		}
+		check.Timeout = durationpb.New(timeout)
+	}
+	return check, nil
+}
+
+// ReadyTimeout is how long the worker waits for a background command to be ready, 0 when it doesn't wait
+func ReadyTimeout(command *api.Command) time.Duration {
+	if !command.Background || command.ReadyCheck == nil {
+		return 0
+	}
+	if command.ReadyCheck.Timeout != nil && command.ReadyCheck.Timeout.AsDuration() > 0 {
+		return command.ReadyCheck.Timeout.AsDuration()
+	}
+	return DefaultReadyTimeout
+}
+
+// ReadyWaiter is given the output of a background command and waits for its readyCheck to pass
+type ReadyWaiter struct {
+	check   *api.ReadyCheck
+	pattern *regexp.Regexp
+	// logged is closed once a line matches the log regex
+	logged chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// NewReadyWaiter is nil when the command doesn't have a readyCheck
+func NewReadyWaiter(command *api.Command) *ReadyWaiter {
+	if !command.Background || command.ReadyCheck == nil {
+		return nil
+	}
+	w := &ReadyWaiter{check: command.ReadyCheck, logged: make(chan struct{})}
+	if command.ReadyCheck.Log != "" {
+		// a bad regex is the same as never being ready
+		w.pattern, w.err = regexp.Compile(command.ReadyCheck.Log)
+	}
+	return w
+}
+
+// Line is called with each line of output from the command
+func (w *ReadyWaiter) Line(line string) {
+	if w == nil || w.pattern == nil {
+		return
+	}
+	if w.pattern.MatchString(line) {
+		w.once.Do(func() { close(w.logged) })
+	}
+}
+
+// Wait returns once every check has passed. It gives up when the timeout is up or the command fails, the error says
+// which checks didn't pass. exited gets the command's error from Wait, a command that exits cleanly can have started
+// a daemon so the checks carry on. probe runs the readyCheck command and is only called when there is one.
+func (w *ReadyWaiter) Wait(ctx context.Context, timeout time.Duration, exited <-chan error, probe func(ctx context.Context) error) error {
+	if w.err != nil {
+		return fmt.Errorf("%w: readyCheck log %q %w", NotReadyError, w.check.Log, w.err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var waiting []string
+	for {
+		waiting = w.notReady(ctx, probe)
+		if len(waiting) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w after %v waiting for %v", NotReadyError, timeout, strings.Join(waiting, ", "))
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("%w, it exited with %v while waiting for %v", NotReadyError, err, strings.Join(waiting, ", "))
+			}
+			exited = nil
+		case <-time.After(readyCheckInterval):
+		}
+	}
+}
+
+// notReady tries each check and gives the ones that haven't passed
+func (w *ReadyWaiter) notReady(ctx context.Context, probe func(ctx context.Context) error) []string {
+	var waiting []string
+	if w.check.Port > 0 {
+		address := net.JoinHostPort("localhost", strconv.Itoa(int(w.check.Port)))
+		conn, err := (&net.Dialer{Timeout: time.Second}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			waiting = append(waiting, fmt.Sprintf("port %v to be open", w.check.Port))
+		} else {
+			conn.Close()
+		}
+	}
+	if w.check.Url != "" && !urlReady(ctx, w.check.Url) {
+		waiting = append(waiting, fmt.Sprintf("%v to give a 200", w.check.Url))
+	}
+	if w.pattern != nil {
+		select {
+		case <-w.logged:
+		default:
+			waiting = append(waiting, fmt.Sprintf("a line matching %q", w.check.Log))
+		}
+	}
+	// the probe is left until everything else is ready as it is the slowest
+	if w.check.Command != "" && len(waiting) == 0 && probe(ctx) != nil {
+		waiting = append(waiting, fmt.Sprintf("%v to succeed", w.check.Command))
+	}
+	return waiting
+}
+
+func urlReady(ctx context.Context, u string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
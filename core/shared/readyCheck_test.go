@@ -0,0 +1,121 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadyCheckConfig(t *testing.T) {
+	check, err := (&ReadyCheck{Port: 3000, Url: "http://localhost:3000/health", Log: "listening on \\d+", Timeout: "2m"}).Proto()
+	if err != nil || check.Port != 3000 || check.Timeout.AsDuration() != 2*time.Minute {
+		t.Errorf("unexpected readyCheck %v %v", check, err)
+	}
+	if got := ReadyTimeout(&api.Command{Background: true, ReadyCheck: &api.ReadyCheck{Port: 5432}}); got != DefaultReadyTimeout {
+		t.Errorf("expected the default timeout got %v", got)
+	}
+	if got := ReadyTimeout(&api.Command{ReadyCheck: check}); got != 0 {
+		t.Errorf("expected a command that isn't in the background not to wait got %v", got)
+	}
+
+	for _, bad := range []ReadyCheck{{}, {Port: 70000}, {Url: "localhost:3000"}, {Log: "("}, {Port: 3000, Timeout: "soon"}} {
+		if _, err := bad.Proto(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+	config := Config{WorkerImage: "node-lts", BuildCommands: []Command{{Commandline: "yarn start", ReadyCheck: &ReadyCheck{Port: 3000}}}}
+	if validateConfig(config) == nil {
+		t.Error("expected a readyCheck on a command that isn't in the background to be rejected")
+	}
+}
+
+func TestReadyWaiter(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var probes atomic.Int32
+	probe := func(ctx context.Context) error {
+		probes.Add(1)
+		return nil
+	}
+
+	command := &api.Command{Background: true, ReadyCheck: &api.ReadyCheck{Port: int32(port), Url: server.URL, Log: `listening on \d+`, Command: "pg_isready"}}
+	waiter := NewReadyWaiter(command)
+	go func() {
+		waiter.Line("starting up")
+		time.Sleep(300 * time.Millisecond)
+		waiter.Line("listening on 3000")
+		healthy.Store(true)
+	}()
+	start := time.Now()
+	if err := waiter.Wait(context.Background(), 5*time.Second, nil, probe); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if time.Since(start) < 300*time.Millisecond {
+		t.Error("expected to wait for the log line and the url")
+	}
+	if probes.Load() != 1 {
+		t.Errorf("expected the probe to only run once everything else was ready got %v", probes.Load())
+	}
+
+	healthy.Store(false)
+	err = NewReadyWaiter(command).Wait(context.Background(), 600*time.Millisecond, nil, probe)
+	if !errors.Is(err, NotReadyError) || !strings.Contains(err.Error(), server.URL+" to give a 200") || !strings.Contains(err.Error(), `a line matching "listening on \\d+"`) {
+		t.Errorf("expected the error to say what wasn't ready got %v", err)
+	}
+
+	exited := make(chan error, 1)
+	exited <- errors.New("exit status 1")
+	err = NewReadyWaiter(&api.Command{Background: true, ReadyCheck: &api.ReadyCheck{Command: "pg_isready"}}).Wait(context.Background(), time.Minute, exited, func(ctx context.Context) error { return errors.New("no") })
+	if !errors.Is(err, NotReadyError) || !strings.Contains(err.Error(), "exited with exit status 1") {
+		t.Errorf("expected to give up when the command fails got %v", err)
+	}
+
+	// a service that daemonizes exits cleanly and is ready a little later
+	exited = make(chan error, 1)
+	exited <- nil
+	checks := 0
+	err = NewReadyWaiter(&api.Command{Background: true, ReadyCheck: &api.ReadyCheck{Command: "pg_isready"}}).Wait(context.Background(), time.Minute, exited, func(ctx context.Context) error {
+		checks++
+		if checks < 3 {
+			return errors.New("no")
+		}
+		return nil
+	})
+	if err != nil || checks != 3 {
+		t.Errorf("expected to keep checking after a clean exit got %v after %v probes", err, checks)
+	}
+}
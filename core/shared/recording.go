@@ -0,0 +1,114 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var NotARecordingError = errors.New("not a brisk recording")
+
+// Recorder writes every output of a run to a file so it can be replayed later, see RecordingHeader for the format
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	gz    *gzip.Writer
+	start time.Time
+}
+
+func NewRecorder(path string, header *pb.RecordingHeader) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{file: f, gz: gzip.NewWriter(f), start: time.Now()}
+	if _, err := protodelim.MarshalTo(r.gz, header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record adds the output, recordings get passed around so the log encryption key of the run is left out
+func (r *Recorder) Record(output *pb.Output) error {
+	if output.LogEncryptionKey != "" {
+		output = &pb.Output{RunId: output.RunId, Created: output.Created}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := protodelim.MarshalTo(r.gz, &pb.RecordedOutput{Offset: durationpb.New(time.Since(r.start)), Output: output})
+	return err
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.gz.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RecordingReader reads back a recording made by Recorder
+type RecordingReader struct {
+	Header *pb.RecordingHeader
+	file   *os.File
+	gz     *gzip.Reader
+	r      *bufio.Reader
+}
+
+func OpenRecording(path string) (*RecordingReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w %v: %w", NotARecordingError, path, err)
+	}
+	r := &RecordingReader{Header: &pb.RecordingHeader{}, file: f, gz: gz, r: bufio.NewReader(gz)}
+	if err := protodelim.UnmarshalFrom(r.r, r.Header); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("%w %v: %w", NotARecordingError, path, err)
+	}
+	return r, nil
+}
+
+// Next gives the outputs in the order they were received, io.EOF once they have all been read. A recording cut short
+// by the CLI being killed ends with io.ErrUnexpectedEOF.
+func (r *RecordingReader) Next() (*pb.RecordedOutput, error) {
+	recorded := &pb.RecordedOutput{}
+	if err := protodelim.UnmarshalFrom(r.r, recorded); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+func (r *RecordingReader) Close() error {
+	r.gz.Close()
+	return r.file.Close()
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.brisklog")
+	header := &pb.RecordingHeader{Version: "1.0.0", Framework: "Jest", CommandIds: []string{"lint", "test"}}
+	recorder, err := NewRecorder(path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := []*pb.Output{{Stdout: "PASS src/app.test.js"}, {Control: "Finished", Exitcode: 1, TotalTestCount: 3}}
+	for _, output := range outputs {
+		if err := recorder.Record(output); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := OpenRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recording.Close()
+	if !proto.Equal(recording.Header, header) {
+		t.Errorf("unexpected header %v", recording.Header)
+	}
+	var last *pb.RecordedOutput
+	for _, want := range outputs {
+		got, err := recording.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got.Output, want) {
+			t.Errorf("expected %v got %v", want, got.Output)
+		}
+		if last != nil && got.Offset.AsDuration() < last.Offset.AsDuration() {
+			t.Errorf("expected the offsets to go forward got %v then %v", last.Offset.AsDuration(), got.Offset.AsDuration())
+		}
+		last = got
+	}
+	if _, err := recording.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF at the end got %v", err)
+	}
+
+	notRecording := filepath.Join(t.TempDir(), "brisk.json")
+	os.WriteFile(notRecording, []byte(`{"framework": "Jest"}`), 0600)
+	if _, err := OpenRecording(notRecording); !errors.Is(err, NotARecordingError) {
+		t.Errorf("expected NotARecordingError got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// MemoryKiller only looks after our own heap, these limits stop the tests a worker runs from taking down the other
+// workers on the same host. They are only enforced with cgroup v2, rlimits can't do it as RLIMIT_NPROC counts every
+// process of the user and is ignored for root, and an address space limit breaks runtimes like V8 and the JVM that
+// reserve far more than they use.
+
+const (
+	MemoryLimit = "memory"
+	PidsLimit   = "pids"
+)
+
+var InvalidResourceLimitError = errors.New("invalid resource limit")
+
+// ResourceLimits are the limits in brisk.json, memory is in bytes or with a K, M or G suffix
+type ResourceLimits struct {
+	Memory string  `json:"memory,omitempty"`
+	Cpus   float64 `json:"cpus,omitempty"`
+	Pids   int     `json:"pids,omitempty"`
+}
+
+// ParseMemoryLimit reads a size like 512M or 2G, the suffixes are powers of 1024 like docker
+func ParseMemoryLimit(memory string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(memory))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := int64(1)
+	if len(s) > 0 {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	size, err := strconv.ParseFloat(s, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("%w: memory %q is not a size like 512M or 2G", InvalidResourceLimitError, memory)
+	}
+	return int64(size * float64(multiplier)), nil
+}
+
+// Proto checks the limits and converts them for the worker, nil when there aren't any
+func (l *ResourceLimits) Proto() (*api.ResourceLimits, error) {
+	if l == nil {
+		return nil, nil
+	}
+	limits := &api.ResourceLimits{Cpus: l.Cpus, Pids: int64(l.Pids)}
+	if l.Memory != "" {
+		memory, err := ParseMemoryLimit(l.Memory)
+		if err != nil {
+			return nil, err
+		}
+		limits.MemoryBytes = memory
+	}
+	if l.Cpus < 0 {
+		return nil, fmt.Errorf("%w: cpus %v can not be negative", InvalidResourceLimitError, l.Cpus)
+	}
+	if l.Pids < 0 {
+		return nil, fmt.Errorf("%w: pids %v can not be negative", InvalidResourceLimitError, l.Pids)
+	}
+	if limits.MemoryBytes == 0 && limits.Cpus == 0 && limits.Pids == 0 {
+		return nil, nil
+	}
+	return limits, nil
+}
+
+// CommandLimiter keeps track of the limits put on a command so we can tell if it was killed for going over them
+type CommandLimiter struct {
+	limits *api.ResourceLimits
+	cgroup *commandCgroup
+}
+
+// LimitCommand puts the limits on cmd before it is started in its own cgroup, when cgroup v2 isn't available the
+// command runs without them. Close has to be called once the command has finished.
+func LimitCommand(ctx context.Context, cmd *exec.Cmd, limits *api.ResourceLimits, name string) *CommandLimiter {
+	limiter := &CommandLimiter{limits: limits}
+	if limits == nil {
+		return limiter
+	}
+	cgroup, err := newCommandCgroup(ctx, name, limits)
+	if err == nil {
+		limiter.cgroup = cgroup
+		cgroup.attach(cmd)
+		return limiter
+	}
+	Logger(ctx).Warnf("Can't use a cgroup for %v so it runs without its limits - %v", name, err)
+	return limiter
+}
+
+// Exceeded is the limit the command was killed for going over, only cgroups can tell us this
+func (l *CommandLimiter) Exceeded(ctx context.Context) string {
+	if l.cgroup == nil {
+		return ""
+	}
+	return l.cgroup.exceeded(ctx)
+}
+
+// BriskError says which limit the command went over instead of leaving the user with an exit code from a SIGKILL
+func (l *CommandLimiter) BriskError(in *api.Command, limit string, exitCode int32) *pb.BriskError {
+	var message string
+	switch limit {
+	case MemoryLimit:
+		message = fmt.Sprintf("%v was killed for going over its memory limit of %v MiB", in.Commandline, l.limits.GetMemoryBytes()/(1<<20))
+	case PidsLimit:
+		message = fmt.Sprintf("%v went over its limit of %v processes", in.Commandline, l.limits.GetPids())
+	default:
+		message = fmt.Sprintf("%v went over its %v limit", in.Commandline, limit)
+	}
+	return &pb.BriskError{Error: message, ExitCode: exitCode, LimitExceeded: limit, AdditionalMessage: "resource limit exceeded"}
+}
+
+func (l *CommandLimiter) Close(ctx context.Context) {
+	if l.cgroup != nil {
+		l.cgroup.remove(ctx)
+	}
+}
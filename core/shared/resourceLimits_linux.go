@@ -0,0 +1,206 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	. "brisk-supervisor/shared/logger"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const cgroupMount = "/sys/fs/cgroup"
+
+// cpu.max is a quota of microseconds in each period
+const cgroupCpuPeriod = 100000
+
+var cgroupRoot struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// commandCgroup is the cgroup of a single command, the processes are started in it so nothing they fork escapes
+type commandCgroup struct {
+	path string
+	dir  *os.File
+}
+
+func newCommandCgroup(ctx context.Context, name string, limits *api.ResourceLimits) (*commandCgroup, error) {
+	cgroupRoot.once.Do(func() {
+		cgroupRoot.path, cgroupRoot.err = setupCgroupRoot(ctx)
+	})
+	if cgroupRoot.err != nil {
+		return nil, cgroupRoot.err
+	}
+
+	path, err := os.MkdirTemp(cgroupRoot.path, "brisk-"+name+"-")
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]string{}
+	if limits.MemoryBytes > 0 {
+		settings["memory.max"] = fmt.Sprint(limits.MemoryBytes)
+		// kill all of the processes together rather than leave the test runner half working
+		settings["memory.oom.group"] = "1"
+	}
+	if limits.Cpus > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%v %v", max(int64(limits.Cpus*cgroupCpuPeriod), 1000), cgroupCpuPeriod)
+	}
+	if limits.Pids > 0 {
+		settings["pids.max"] = fmt.Sprint(limits.Pids)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(path, file), []byte(value), 0644); err != nil {
+			os.Remove(path)
+			return nil, err
+		}
+	}
+	if limits.MemoryBytes > 0 {
+		// without this the command just swaps instead of hitting the limit, not every host has swap accounting
+		os.WriteFile(filepath.Join(path, "memory.swap.max"), []byte("0"), 0644)
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	Logger(ctx).Debugf("Created cgroup %v with %v", path, settings)
+	return &commandCgroup{path: path, dir: dir}, nil
+}
+
+// setupCgroupRoot finds our own cgroup and turns on the controllers for the command cgroups under it. A cgroup with
+// processes in it can't have children with controllers so we move ourselves into a leaf first.
+func setupCgroupRoot(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not available %w", err)
+	}
+	self, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	var root string
+	for _, line := range strings.Split(strings.TrimSpace(string(self)), "\n") {
+		if rel, ok := strings.CutPrefix(line, "0::"); ok {
+			root = filepath.Join(cgroupMount, rel)
+		}
+	}
+	if root == "" {
+		return "", errors.New("not in a cgroup v2 hierarchy")
+	}
+
+	enable := []byte("+memory +cpu +pids")
+	err = os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), enable, 0644)
+	if errors.Is(err, syscall.EBUSY) {
+		leaf := filepath.Join(root, "brisk-worker")
+		if mkErr := os.Mkdir(leaf, 0755); mkErr != nil && !errors.Is(mkErr, os.ErrExist) {
+			return "", mkErr
+		}
+		procs, readErr := os.ReadFile(filepath.Join(root, "cgroup.procs"))
+		if readErr != nil {
+			return "", readErr
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(procs))
+		for scanner.Scan() {
+			// processes can exit while we are moving them
+			os.WriteFile(filepath.Join(leaf, "cgroup.procs"), scanner.Bytes(), 0644)
+		}
+		err = os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), enable, 0644)
+	}
+	if err != nil {
+		return "", fmt.Errorf("can't enable the cgroup controllers in %v %w", root, err)
+	}
+	if err := checkCgroupStart(root); err != nil {
+		return "", err
+	}
+	Logger(ctx).Infof("Limiting commands with cgroups under %v", root)
+	return root, nil
+}
+
+// checkCgroupStart starts a process in a cgroup the way the commands are. Without delegation of the cgroup, or with it
+// busy, that fails and a command that failed to start can't be started again as its pipes have been closed.
+func checkCgroupStart(root string) error {
+	path, err := os.MkdirTemp(root, "brisk-check-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	cmd := exec.Command("/bin/sh", "-c", "exit 0")
+	(&commandCgroup{path: path, dir: dir}).attach(cmd)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("can't start commands in a cgroup under %v %w", root, err)
+	}
+	return nil
+}
+
+func (c *commandCgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(c.dir.Fd())
+}
+
+func (c *commandCgroup) exceeded(ctx context.Context) string {
+	if cgroupEventCount(ctx, filepath.Join(c.path, "memory.events"), "oom_kill") > 0 {
+		return MemoryLimit
+	}
+	if cgroupEventCount(ctx, filepath.Join(c.path, "pids.events"), "max") > 0 {
+		return PidsLimit
+	}
+	return ""
+}
+
+// cgroupEventCount reads a counter from an events file made up of lines like "oom_kill 1"
+func cgroupEventCount(ctx context.Context, file string, event string) int64 {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		Logger(ctx).Debugf("Can't read %v %v", file, err)
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		var name string
+		var count int64
+		if _, err := fmt.Sscan(line, &name, &count); err == nil && name == event {
+			return count
+		}
+	}
+	return 0
+}
+
+// remove kills anything left in the cgroup, a cgroup can only be removed once it is empty
+func (c *commandCgroup) remove(ctx context.Context) {
+	c.dir.Close()
+	os.WriteFile(filepath.Join(c.path, "cgroup.kill"), []byte("1"), 0644)
+	if err := os.Remove(c.path); err != nil {
+		Logger(ctx).Warnf("Error removing cgroup %v %v", c.path, err)
+	}
+}
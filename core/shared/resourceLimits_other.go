@@ -0,0 +1,37 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// the workers run on linux, everywhere else runs commands without their limits
+type commandCgroup struct{}
+
+func newCommandCgroup(ctx context.Context, name string, limits *api.ResourceLimits) (*commandCgroup, error) {
+	return nil, errors.New("cgroups are only available on linux")
+}
+
+func (c *commandCgroup) attach(cmd *exec.Cmd) {}
+
+func (c *commandCgroup) exceeded(ctx context.Context) string { return "" }
+
+func (c *commandCgroup) remove(ctx context.Context) {}
@@ -0,0 +1,55 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"errors"
+	"testing"
+)
+
+func TestResourceLimits(t *testing.T) {
+	for memory, want := range map[string]int64{"512M": 512 << 20, "2G": 2 << 30, "1.5GiB": 3 << 29, "64kb": 64 << 10, "1048576": 1 << 20} {
+		if got, err := ParseMemoryLimit(memory); err != nil || got != want {
+			t.Errorf("expected %v to be %v got %v %v", memory, want, got, err)
+		}
+	}
+
+	limits, err := (&ResourceLimits{Memory: "1G", Cpus: 1.5, Pids: 256}).Proto()
+	if err != nil || limits.MemoryBytes != 1<<30 || limits.Cpus != 1.5 || limits.Pids != 256 {
+		t.Errorf("unexpected limits %v %v", limits, err)
+	}
+	if limits, err := (&ResourceLimits{}).Proto(); limits != nil || err != nil {
+		t.Errorf("expected no limits got %v %v", limits, err)
+	}
+
+	for _, bad := range []ResourceLimits{{Memory: "lots"}, {Memory: "-1G"}, {Cpus: -1}, {Pids: -5}} {
+		if _, err := bad.Proto(); !errors.Is(err, InvalidResourceLimitError) {
+			t.Errorf("expected %+v to be rejected got %v", bad, err)
+		}
+		config := Config{WorkerImage: "node-lts", Commands: []Command{{Commandline: "yarn test", Limits: &bad}}}
+		if validateConfig(config) == nil {
+			t.Errorf("expected the config with %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestLimiterBriskError(t *testing.T) {
+	limiter := &CommandLimiter{limits: &api.ResourceLimits{MemoryBytes: 1 << 30}}
+	briskError := limiter.BriskError(&api.Command{Commandline: "yarn test"}, MemoryLimit, 137)
+	if briskError.LimitExceeded != MemoryLimit || briskError.Error != "yarn test was killed for going over its memory limit of 1024 MiB" {
+		t.Errorf("unexpected error %v", briskError)
+	}
+}
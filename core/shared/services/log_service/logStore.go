@@ -0,0 +1,128 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+var LogNotFoundError = errors.New("log not found")
+
+// LogStore is where the output of runs is kept once they finish, the keys are <project token>/<uid>
+type LogStore interface {
+	// Put stores the content and gives where it went
+	Put(ctx context.Context, key string, content io.Reader) (string, error)
+	// Get gives LogNotFoundError when there is nothing stored for the key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// NewLogStoreFromConfig picks the store from the supervisor config, LOG_STORE is s3, file or none. Logs aren't kept
+// in dev unless LOG_STORE is set.
+func NewLogStoreFromConfig(dev bool) (LogStore, error) {
+	kind := viper.GetString("LOG_STORE")
+	if kind == "" && dev {
+		kind = "none"
+	}
+	switch kind {
+	case "", "s3":
+		return NewS3LogStore(S3Config{
+			Bucket:          viper.GetString("LOG_BUCKET"),
+			Region:          viper.GetString("LOG_REGION"),
+			Endpoint:        viper.GetString("LOG_ENDPOINT"),
+			PathStyle:       viper.GetBool("LOG_PATH_STYLE"),
+			AccessKeyId:     viper.GetString("LOG_ACCESS_KEY_ID"),
+			SecretAccessKey: viper.GetString("LOG_SECRET_ACCESS_KEY"),
+		})
+	case "file":
+		dir := viper.GetString("LOG_STORE_DIR")
+		if dir == "" {
+			return nil, errors.New("LOG_STORE_DIR is needed to keep logs on the filesystem")
+		}
+		return &FileLogStore{Dir: dir}, nil
+	case "none":
+		return NoopLogStore{}, nil
+	default:
+		return nil, fmt.Errorf("unknown LOG_STORE %v - use s3, file or none", kind)
+	}
+}
+
+// FileLogStore keeps the logs under Dir, for a self hosted supervisor with a volume for them
+type FileLogStore struct {
+	Dir string
+}
+
+func (s *FileLogStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("log key %q is not a relative path", key)
+	}
+	return filepath.Join(s.Dir, key), nil
+}
+
+func (s *FileLogStore) Put(ctx context.Context, key string, content io.Reader) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	// written to the side and renamed so Get never sees half a log
+	f, err := os.CreateTemp(filepath.Dir(path), ".log-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return "file://" + path, nil
+}
+
+func (s *FileLogStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", LogNotFoundError, key)
+	}
+	return f, err
+}
+
+// NoopLogStore drops the logs
+type NoopLogStore struct{}
+
+func (NoopLogStore) Put(ctx context.Context, key string, content io.Reader) (string, error) {
+	return "", nil
+}
+
+func (NoopLogStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("%w: logs are not kept on this supervisor", LogNotFoundError)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log_service
+
+import (
+	"brisk-supervisor/shared/logcrypt"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestFileLogStore(t *testing.T) {
+	ctx := context.Background()
+	store := &FileLogStore{Dir: t.TempDir()}
+	ls := New(store, "project", "run-1")
+	location, err := ls.UploadContent(ctx, strings.NewReader("12:00:01 worker 1 : PASS\n"), Logger(ctx))
+	if err != nil || !strings.HasPrefix(location, "file://") {
+		t.Fatalf("unexpected location %v %v", location, err)
+	}
+	log, err := New(store, "project", "run-1").Download(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(log)
+	log.Close()
+	if string(content) != "12:00:01 worker 1 : PASS\n" {
+		t.Errorf("unexpected log %q", content)
+	}
+
+	for _, uid := range []string{"run-2", "..", "../other-project/run-1", ""} {
+		if _, err := New(store, "project", uid).Download(ctx); !errors.Is(err, LogNotFoundError) {
+			t.Errorf("expected LogNotFoundError for %q got %v", uid, err)
+		}
+	}
+	if _, err := New(store, "other-project", "run-1").Download(ctx); !errors.Is(err, LogNotFoundError) {
+		t.Errorf("expected another project not to see the log got %v", err)
+	}
+}
+
+func TestEncryptedUpload(t *testing.T) {
+	ctx := context.Background()
+	store := &FileLogStore{Dir: t.TempDir()}
+	key, err := logcrypt.NewLogEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEncrypted(store, "project", "run-1", key).UploadContent(ctx, strings.NewReader("12:00:01 worker 1 : PASS\n"), Logger(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	log, err := New(store, "project", "run-1").Download(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	stored, _ := io.ReadAll(log)
+	if !logcrypt.IsEncryptedLog(stored) || strings.Contains(string(stored), "PASS") {
+		t.Fatalf("expected the stored log to be encrypted got %q", stored)
+	}
+	decrypter, err := logcrypt.NewLogDecrypter(strings.NewReader(string(stored)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(decrypter)
+	if err != nil || string(content) != "12:00:01 worker 1 : PASS\n" {
+		t.Errorf("unexpected log %q %v", content, err)
+	}
+
+	if _, err := NewEncrypted(store, "project", "run-2", "not a key").UploadContent(ctx, strings.NewReader("PASS"), Logger(ctx)); err == nil {
+		t.Error("expected a bad key not to store the log")
+	}
+}
+
+func TestNewLogStoreFromConfig(t *testing.T) {
+	defer viper.Reset()
+	if store, err := NewLogStoreFromConfig(true); err != nil || store != (NoopLogStore{}) {
+		t.Errorf("expected logs not to be kept in dev got %v %v", store, err)
+	}
+	viper.Set("LOG_STORE", "file")
+	if _, err := NewLogStoreFromConfig(false); err == nil {
+		t.Error("expected the file store to need a directory")
+	}
+	viper.Set("LOG_STORE_DIR", t.TempDir())
+	if store, err := NewLogStoreFromConfig(true); err != nil {
+		t.Errorf("unexpected error %v", err)
+	} else if _, ok := store.(*FileLogStore); !ok {
+		t.Errorf("expected a file store got %T", store)
+	}
+	viper.Set("LOG_STORE", "s3")
+	viper.Set("LOG_ENDPOINT", "http://localhost:9000")
+	viper.Set("LOG_PATH_STYLE", true)
+	viper.Set("LOG_ACCESS_KEY_ID", "minio")
+	if _, err := NewLogStoreFromConfig(false); err == nil {
+		t.Error("expected a key id without a secret to be rejected")
+	}
+	viper.Set("LOG_SECRET_ACCESS_KEY", "minio123")
+	if store, err := NewLogStoreFromConfig(false); err != nil {
+		t.Errorf("unexpected error %v", err)
+	} else if s3Store := store.(*S3LogStore); s3Store.bucket != "brisk-output-logs" || *s3Store.client.Config.Endpoint != "http://localhost:9000" || !*s3Store.client.Config.S3ForcePathStyle {
+		t.Errorf("unexpected s3 store %+v", s3Store.client.Config)
+	}
+	viper.Set("LOG_STORE", "gcs")
+	if _, err := NewLogStoreFromConfig(false); err == nil {
+		t.Error("expected an unknown store to be rejected")
+	}
+}
@@ -15,83 +15,98 @@
 package log_service
 
 import (
-	"brisk-supervisor/shared/constants"
+	"brisk-supervisor/shared/logcrypt"
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	. "brisk-supervisor/shared/logger"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 func (s *LogService) PrintConfig() {
-	fmt.Printf("LogService Config: %+v", s)
+	// not the whole struct as it has the encryption key
+	fmt.Printf("LogService Config: store %T key %v encrypted %v", s.store, s.key, s.encryptionKey != "")
 }
 
+// LogService keeps the log of one run or worker run in the store
 type LogService struct {
-	awsSession   *session.Session
-	uploader     *s3manager.Uploader
-	bucket       string
+	store        LogStore
+	uid          string
 	key          string
-	awsConfig    *aws.Config
 	Location     string
 	projectToken string
+	// encryptionKey is the key of the run, the log is stored in plain text without one
+	encryptionKey string
 }
 
-func New(projectToken string, uid string) *LogService {
+func New(store LogStore, projectToken string, uid string) *LogService {
 	// key will identify the specific jobruninfo
 	// we can then recombine it with the job run
 
-	ls := LogService{projectToken: projectToken, key: projectToken + "/" + uid}
-	ls.Configure()
+	ls := LogService{store: store, projectToken: projectToken, uid: uid, key: projectToken + "/" + uid}
 	return &ls
 }
 
-func (s *LogService) Configure() error {
-
-	s.bucket = constants.DEFAULT_LOG_BUCKET
-	//load default credentials
-	s.awsConfig = &aws.Config{
-		CredentialsChainVerboseErrors: aws.Bool(true),
-		Region:                        aws.String(constants.DEFAULT_REGION),
-		LogLevel:                      aws.LogLevel(aws.LogDebug),
-	}
-
-	s.awsSession = session.Must(session.NewSession(s.awsConfig))
-	s.uploader = s3manager.NewUploader(s.awsSession)
+// NewEncrypted encrypts the log with the key of the run before it is uploaded, see logcrypt.NewLogEncrypter
+func NewEncrypted(store LogStore, projectToken string, uid string, encryptionKey string) *LogService {
+	ls := New(store, projectToken, uid)
+	ls.encryptionKey = encryptionKey
+	return ls
+}
 
-	return nil
+// ValidUid is false for anything that would reach outside of the project in the store
+func ValidUid(uid string) bool {
+	return uid != "" && uid != "." && uid != ".." && !strings.ContainsAny(uid, "/\\")
 }
 
-// pass a reader to the log service and it will upload it to s3
+// pass a reader to the log service and it will upload it to the store
 
 func (s *LogService) UploadContent(ctx context.Context, content io.Reader, logger *BriskLogger) (string, error) {
-	logger.Infof("Uploading content to s3 bucket: %s, key: %s", s.bucket, s.key)
+	logger.Infof("Uploading content to the log store key: %s", s.key)
 
-	// Upload the content to S3.
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
-	opts := func(u *s3manager.Uploader) {
-		u.PartSize = 10 * 1024 * 1024 // 10MB per part
-		u.LeavePartsOnError = false   // Don't delete the parts if the upload fails.
-		u.Concurrency = 5
-
+	if s.encryptionKey != "" {
+		encrypted, err := s.encrypt(content)
+		if err != nil {
+			return "", err
+		}
+		defer encrypted.Close()
+		content = encrypted
 	}
-	result, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
-		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(s.key),
-		Body:        content,
-		ContentType: aws.String("text/plain"),
-	}, opts)
+	location, err := s.store.Put(ctx, s.key, content)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload file, %v", err)
+		return "", err
 	}
 
-	logger.Infof("file uploaded to, %s\n", result.Location)
-	s.Location = result.Location
+	logger.Infof("file uploaded to, %s\n", location)
+	s.Location = location
 	return s.Location, nil
 }
+
+// encrypt gives the content encrypted as it is read
+func (s *LogService) encrypt(content io.Reader) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	encrypter, err := logcrypt.NewLogEncrypter(pw, s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_, err := io.Copy(encrypter, content)
+		if err == nil {
+			err = encrypter.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// Download gives the log that was uploaded, LogNotFoundError if there isn't one
+func (s *LogService) Download(ctx context.Context) (io.ReadCloser, error) {
+	if s.projectToken == "" || !ValidUid(s.uid) {
+		return nil, fmt.Errorf("%w: %q is not a log uid", LogNotFoundError, s.uid)
+	}
+	return s.store.Get(ctx, s.key)
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log_service
+
+import (
+	"brisk-supervisor/shared/constants"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Config is for AWS or anything that speaks S3 such as MinIO, the default credentials are used when there is no key
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint is the url of an S3 compatible service, empty for AWS
+	Endpoint string
+	// PathStyle puts the bucket in the path rather than the host, most S3 compatible services need it
+	PathStyle       bool
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+type S3LogStore struct {
+	bucket   string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+func NewS3LogStore(config S3Config) (*S3LogStore, error) {
+	if config.Bucket == "" {
+		config.Bucket = constants.DEFAULT_LOG_BUCKET
+	}
+	if config.Region == "" {
+		config.Region = constants.DEFAULT_REGION
+	}
+	awsConfig := &aws.Config{
+		CredentialsChainVerboseErrors: aws.Bool(true),
+		Region:                        aws.String(config.Region),
+		LogLevel:                      aws.LogLevel(aws.LogDebug),
+		S3ForcePathStyle:              aws.Bool(config.PathStyle),
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+	if config.AccessKeyId != "" || config.SecretAccessKey != "" {
+		if config.AccessKeyId == "" || config.SecretAccessKey == "" {
+			return nil, errors.New("the log store needs both an access key id and a secret access key")
+		}
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.AccessKeyId, config.SecretAccessKey, "")
+	}
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &S3LogStore{bucket: config.Bucket, client: s3.New(awsSession), uploader: s3manager.NewUploader(awsSession)}, nil
+}
+
+func (s *S3LogStore) Put(ctx context.Context, key string, content io.Reader) (string, error) {
+	opts := func(u *s3manager.Uploader) {
+		u.PartSize = 10 * 1024 * 1024 // 10MB per part
+		u.LeavePartsOnError = false   // Don't delete the parts if the upload fails.
+		u.Concurrency = 5
+
+	}
+	result, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        content,
+		ContentType: aws.String("text/plain"),
+	}, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file, %v", err)
+	}
+	return result.Location, nil
+}
+
+func (s *S3LogStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, fmt.Errorf("%w: %v", LogNotFoundError, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
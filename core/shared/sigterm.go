@@ -42,9 +42,11 @@ func terminate(proc *os.Process) error {
 // Start is like calling Start on os/exec.CommandContext but uses
 // SIGTERM on Unix-based systems.
 func TermStart(ctx context.Context, c *exec.Cmd) (wait func() error, err error) {
-	c.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+	// the resource limits may have already put the command in a cgroup
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
 	}
+	c.SysProcAttr.Setpgid = true
 
 	if err := c.Start(); err != nil {
 		return nil, err
@@ -200,5 +200,7 @@ func addExclusions(command []string, exclusions []string) (newCommand []string)
 	for _, v := range exclusions {
 		newCommand = append(newCommand, "--exclude", v)
 	}
+	// the artifacts brought back from the workers never need to go back to them
+	newCommand = append(newCommand, "--exclude", "/"+ArtifactsDirectory+"/")
 	return
 }
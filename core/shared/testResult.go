@@ -0,0 +1,425 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared/logger"
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// the status of a TestResult
+const (
+	ResultPassed  = "passed"
+	ResultFailed  = "failed"
+	ResultSkipped = "skipped"
+)
+
+// TestResult is a single test as reported by one of the workers, every framework's results are parsed into these
+// and they are what the counts, the reports and the split history are built from
+type TestResult struct {
+	Suite          string
+	Name           string
+	File           string
+	Status         string
+	Duration       time.Duration
+	FailureMessage string
+	WorkerNumber   int32
+	WorkerUid      string
+	// Attempt is 0 for the first run of the test and goes up by one for each retry
+	Attempt int
+	// Flaky is set when the test failed and then passed when it was retried
+	Flaky bool
+}
+
+// RepoRelativeFile is file relative to the repo checked out in workDir, jest reports absolute paths and rspec ./ ones
+// so results need this before they are matched with the files that were split
+func RepoRelativeFile(file string, workDir string) string {
+	if workDir != "" {
+		if rest, ok := strings.CutPrefix(file, workDir); ok {
+			file = strings.TrimPrefix(rest, "/")
+		}
+	}
+	return strings.TrimPrefix(file, "./")
+}
+
+// the formats ParseTestResults understands
+const (
+	ResultsFormatJest  = "jest"
+	ResultsFormatRspec = "rspec"
+	ResultsFormatJunit = "junit"
+	ResultsFormatTap   = "tap"
+	ResultsFormatGo    = "go"
+)
+
+// ParseTestResults parses results written in format, an empty format is detected from the results themselves
+func ParseTestResults(format string, results string) ([]TestResult, error) {
+	if format == "" {
+		format = DetectResultsFormat(results)
+	}
+	switch format {
+	case ResultsFormatJest:
+		return parseJestResults(results)
+	case ResultsFormatRspec:
+		return parseRspecResults(results)
+	case ResultsFormatJunit:
+		return parseJunitXmlResults(results)
+	case ResultsFormatTap:
+		return parseTapResults(results)
+	case ResultsFormatGo:
+		return parseGoTestResults(results)
+	}
+	return nil, fmt.Errorf("unrecognized test results format %q", format)
+}
+
+var tapMarker = regexp.MustCompile(`(?m)^(TAP version \d+|1\.\.\d+|(not )?ok\b)`)
+
+// DetectResultsFormat guesses the format of a results file from its contents, empty if we can't tell
+func DetectResultsFormat(results string) string {
+	trimmed := strings.TrimSpace(results)
+	switch {
+	case strings.HasPrefix(trimmed, "<"):
+		return ResultsFormatJunit
+	case strings.HasPrefix(trimmed, "{") && strings.Contains(trimmed, `"testResults"`):
+		return ResultsFormatJest
+	case strings.HasPrefix(trimmed, "{") && strings.Contains(trimmed, `"examples"`):
+		return ResultsFormatRspec
+	case strings.HasPrefix(trimmed, "{") && strings.Contains(trimmed, `"Action"`):
+		return ResultsFormatGo
+	case tapMarker.MatchString(trimmed):
+		return ResultsFormatTap
+	}
+	return ""
+}
+
+// CountTestResults adds the results to the totals
+func CountTestResults(results []TestResult, counts *TestCounts) {
+	for _, r := range results {
+		switch r.Status {
+		case ResultPassed:
+			counts.PassCount++
+		case ResultFailed:
+			counts.FailCount++
+		default:
+			counts.SkipCount++
+		}
+	}
+}
+
+// OutputTestResults are the results carried in a test run output, the workers parse them for us
+// but the JsonResults from a worker that doesn't are parsed here with the framework's parser
+func OutputTestResults(ctx context.Context, framework string, out *pb.Output) ([]TestResult, error) {
+	if out == nil || out.Command == nil || !out.Command.IsTestRun {
+		return nil, nil
+	}
+	if len(out.TestResults) > 0 {
+		return TestResultsFromProto(out.TestResults), nil
+	}
+	if out.JsonResults == "" {
+		return nil, nil
+	}
+	if out.Command.TestFramework != "" {
+		framework = out.Command.TestFramework
+	}
+	f, ok := LookupFramework(framework)
+	if !ok {
+		Logger(ctx).Debugf("No test results support for framework %v", framework)
+		return nil, nil
+	}
+	results, err := f.ParseResults(ctx, out.JsonResults)
+	if err != nil {
+		return nil, err
+	}
+	if out.Worker != nil {
+		for i := range results {
+			results[i].WorkerNumber = out.Worker.Number
+			results[i].WorkerUid = out.Worker.Uid
+		}
+	}
+	return results, nil
+}
+
+// countOutputResults adds the results carried in out to the totals, when the worker didn't send them
+// the JsonResults are parsed with parse
+func countOutputResults(out *pb.Output, counts *TestCounts, parse func(string) ([]TestResult, error)) error {
+	results := TestResultsFromProto(out.TestResults)
+	if len(results) == 0 && out.JsonResults != "" {
+		var err error
+		if results, err = parse(out.JsonResults); err != nil {
+			return err
+		}
+	}
+	CountTestResults(results, counts)
+	return nil
+}
+
+// TestResultsToProto converts the results so a worker can send them back in an Output
+func TestResultsToProto(results []TestResult) []*pb.TestResult {
+	var out []*pb.TestResult
+	for _, r := range results {
+		out = append(out, &pb.TestResult{Suite: r.Suite, Name: r.Name, File: r.File, Status: r.Status, Duration: durationpb.New(r.Duration), FailureMessage: r.FailureMessage, WorkerNumber: r.WorkerNumber, WorkerUid: r.WorkerUid, Attempt: int32(r.Attempt)})
+	}
+	return out
+}
+
+// TestResultsFromProto converts the results carried in an Output
+func TestResultsFromProto(results []*pb.TestResult) []TestResult {
+	var out []TestResult
+	for _, r := range results {
+		out = append(out, TestResult{Suite: r.Suite, Name: r.Name, File: r.File, Status: r.Status, Duration: r.Duration.AsDuration(), FailureMessage: r.FailureMessage, WorkerNumber: r.WorkerNumber, WorkerUid: r.WorkerUid, Attempt: int(r.Attempt)})
+	}
+	return out
+}
+
+type jestJsonResults struct {
+	TestResults []struct {
+		Name             string `json:"name"`
+		Message          string `json:"message"`
+		Status           string `json:"status"`
+		AssertionResults []struct {
+			AncestorTitles  []string `json:"ancestorTitles"`
+			FullName        string   `json:"fullName"`
+			Title           string   `json:"title"`
+			Status          string   `json:"status"`
+			Duration        *float64 `json:"duration"`
+			FailureMessages []string `json:"failureMessages"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+// parseJestResults reads the output of jest --json
+func parseJestResults(jsonString string) ([]TestResult, error) {
+	var results jestJsonResults
+	err := json.Unmarshal([]byte(jsonString), &results)
+	if err != nil {
+		return nil, err
+	}
+	var testCases []TestResult
+	for _, file := range results.TestResults {
+		if len(file.AssertionResults) == 0 && file.Status == ResultFailed {
+			// the suite failed before any tests ran e.g. a syntax error
+			testCases = append(testCases, TestResult{Name: file.Name, File: file.Name, Status: ResultFailed, FailureMessage: file.Message})
+			continue
+		}
+		for _, a := range file.AssertionResults {
+			tc := TestResult{Suite: strings.Join(a.AncestorTitles, " "), Name: a.FullName, File: file.Name}
+			if tc.Name == "" {
+				tc.Name = a.Title
+			}
+			if a.Duration != nil {
+				tc.Duration = time.Duration(*a.Duration * float64(time.Millisecond))
+			}
+			switch a.Status {
+			case "passed":
+				tc.Status = ResultPassed
+			case "failed":
+				tc.Status = ResultFailed
+				tc.FailureMessage = strings.Join(a.FailureMessages, "\n")
+			default:
+				// pending, todo, skipped, disabled
+				tc.Status = ResultSkipped
+			}
+			testCases = append(testCases, tc)
+		}
+	}
+	return testCases, nil
+}
+
+// parseRspecResults reads the output of rspec --format json
+func parseRspecResults(jsonString string) ([]TestResult, error) {
+	results, err := ParseRspecJsonResults(jsonString)
+	if err != nil {
+		return nil, err
+	}
+	var testCases []TestResult
+	for _, e := range results.Examples {
+		tc := TestResult{Name: e.FullDescription, File: e.FilePath, Duration: time.Duration(e.RunTime * float64(time.Second))}
+		switch e.Status {
+		case "passed":
+			tc.Status = ResultPassed
+		case "failed":
+			tc.Status = ResultFailed
+			if e.Exception != nil {
+				tc.FailureMessage = fmt.Sprintf("%v: %v", e.Exception.Class, e.Exception.Message)
+			}
+		default:
+			tc.Status = ResultSkipped
+		}
+		testCases = append(testCases, tc)
+	}
+	// a file that fails to load has no examples but still fails the run
+	for i := 0; i < results.Summary.ErrorsOutsideOfExamplesCount; i++ {
+		testCases = append(testCases, TestResult{Name: "error outside of examples", Status: ResultFailed, FailureMessage: results.SummaryLine})
+	}
+	return testCases, nil
+}
+
+// parseJunitXmlResults reads a JUnit XML report, the root can be a testsuites or a single testsuite
+func parseJunitXmlResults(xmlString string) ([]TestResult, error) {
+	var suites junitXmlTestSuites
+	if err := xml.Unmarshal([]byte(xmlString), &suites); err != nil {
+		var suite junitXmlTestSuite
+		if suiteErr := xml.Unmarshal([]byte(xmlString), &suite); suiteErr != nil {
+			return nil, err
+		}
+		suites.Suites = []junitXmlTestSuite{suite}
+	}
+	var testCases []TestResult
+	for _, suite := range suites.Suites {
+		for _, c := range suite.TestCases {
+			tc := TestResult{Suite: suite.Name, Name: c.Name, File: c.File}
+			if tc.File == "" {
+				tc.File = fileFromClassname(c.Classname)
+			}
+			if seconds, err := strconv.ParseFloat(c.Time, 64); err == nil {
+				tc.Duration = time.Duration(seconds * float64(time.Second))
+			}
+			failure := c.Failure
+			if failure == nil {
+				failure = c.Error
+			}
+			switch {
+			case failure != nil:
+				tc.Status = ResultFailed
+				tc.FailureMessage = strings.TrimSpace(failure.Message + "\n" + failure.Content)
+			case c.Skipped != nil:
+				tc.Status = ResultSkipped
+			default:
+				tc.Status = ResultPassed
+			}
+			testCases = append(testCases, tc)
+		}
+	}
+	return testCases, nil
+}
+
+// fileFromClassname guesses the file from a python style classname like tests.test_models.TestUser
+// the trailing capitalised parts are taken to be classes
+func fileFromClassname(classname string) string {
+	if classname == "" {
+		return ""
+	}
+	parts := strings.Split(classname, ".")
+	for len(parts) > 1 {
+		last := parts[len(parts)-1]
+		if last == "" || !unicode.IsUpper(rune(last[0])) {
+			break
+		}
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, "/") + ".py"
+}
+
+var tapTestLine = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+
+// parseTapResults reads the Test Anything Protocol, only the top level tests are reported
+// the YAML diagnostics after a failed test become its failure message
+func parseTapResults(tap string) ([]TestResult, error) {
+	var results []TestResult
+	var suite string
+	inDiagnostics := false
+	var diagnostics strings.Builder
+
+	finishDiagnostics := func() {
+		inDiagnostics = false
+		if len(results) == 0 {
+			return
+		}
+		last := &results[len(results)-1]
+		for _, line := range strings.Split(diagnostics.String(), "\n") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(line), "duration_ms:"); ok {
+				if ms, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					last.Duration = time.Duration(ms * float64(time.Millisecond))
+				}
+			}
+		}
+		if last.Status == ResultFailed {
+			last.FailureMessage = strings.TrimSpace(diagnostics.String())
+		}
+		diagnostics.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(tap))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if inDiagnostics {
+			if trimmed == "..." {
+				finishDiagnostics()
+			} else {
+				diagnostics.WriteString(trimmed + "\n")
+			}
+			continue
+		}
+		if trimmed == "---" {
+			inDiagnostics = true
+			continue
+		}
+		if name, ok := strings.CutPrefix(trimmed, "# Subtest:"); ok && line == trimmed {
+			suite = strings.TrimSpace(name)
+			continue
+		}
+		if strings.HasPrefix(line, "Bail out!") {
+			results = append(results, TestResult{Suite: suite, Name: line, Status: ResultFailed, FailureMessage: line})
+			break
+		}
+		if line != trimmed {
+			// an indented line belongs to a subtest, its parent reports the result
+			continue
+		}
+		match := tapTestLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		tc := TestResult{Suite: suite, Name: match[3]}
+		if tc.Name == "" {
+			tc.Name = "test " + match[2]
+		}
+		directive := strings.ToUpper(match[4])
+		switch {
+		case strings.HasPrefix(directive, "SKIP"), strings.HasPrefix(directive, "TODO"):
+			// a failing TODO test is expected to fail so it doesn't fail the run
+			tc.Status = ResultSkipped
+		case match[1] == "ok":
+			tc.Status = ResultPassed
+		default:
+			tc.Status = ResultFailed
+		}
+		results = append(results, tc)
+		suite = ""
+	}
+	if inDiagnostics {
+		finishDiagnostics()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 && !tapMarker.MatchString(tap) {
+		return nil, fmt.Errorf("no TAP test results found")
+	}
+	return results, nil
+}
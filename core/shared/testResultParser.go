@@ -30,6 +30,10 @@ type RspecTestResults struct {
 		LineNumber      int         `json:"line_number"`
 		RunTime         float64     `json:"run_time"`
 		PendingMessage  interface{} `json:"pending_message"`
+		Exception       *struct {
+			Class   string `json:"class"`
+			Message string `json:"message"`
+		} `json:"exception"`
 	} `json:"examples"`
 	Summary struct {
 		Duration                     float64 `json:"duration"`
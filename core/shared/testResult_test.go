@@ -0,0 +1,127 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseTapResults(t *testing.T) {
+	tap := `TAP version 13
+1..5
+ok 1 - adds numbers
+not ok 2 - divides by zero
+  ---
+  message: expected an error
+  duration_ms: 12.5
+  ...
+ok 3 - later # SKIP not ready
+not ok 4 - known bug # TODO fix it
+    # Subtest: nested
+    ok 1 - inner
+ok 5
+`
+	if format := DetectResultsFormat(tap); format != ResultsFormatTap {
+		t.Fatalf("expected TAP to be detected got %q", format)
+	}
+	results, err := ParseTestResults("", tap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 top level results got %+v", results)
+	}
+	if results[1].Status != ResultFailed || results[1].FailureMessage == "" || results[1].Duration != 12500*time.Microsecond {
+		t.Errorf("unexpected failed result %+v", results[1])
+	}
+	if results[2].Status != ResultSkipped || results[3].Status != ResultSkipped || results[4].Name != "test 5" {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if _, err := parseTapResults("nothing to see here"); err == nil {
+		t.Error("expected an error for output that isn't TAP")
+	}
+}
+
+func TestDetectResultsFormat(t *testing.T) {
+	tests := map[string]string{
+		`<testsuites></testsuites>`:                   ResultsFormatJunit,
+		`{"testResults":[]}`:                          ResultsFormatJest,
+		`{"examples":[],"summary":{}}`:                ResultsFormatRspec,
+		`{"Action":"pass","Package":"example.com/a"}`: ResultsFormatGo,
+		"random output":                               "",
+	}
+	for results, want := range tests {
+		if got := DetectResultsFormat(results); got != want {
+			t.Errorf("%v: expected %q got %q", results, want, got)
+		}
+	}
+}
+
+func TestOutputTestResults(t *testing.T) {
+	ctx := context.Background()
+	results := []TestResult{{Suite: "maths", Name: "adds", File: "a.test.js", Status: ResultPassed, Duration: time.Second, WorkerNumber: 2, Attempt: 1}}
+	out := &pb.Output{TestResults: TestResultsToProto(results), JsonResults: "not parsed", Command: &api.Command{IsTestRun: true, TestFramework: "Jest"}}
+
+	got, err := OutputTestResults(ctx, "Jest", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != results[0] {
+		t.Errorf("expected the results from the worker got %+v", got)
+	}
+
+	// a worker that doesn't send results has its JsonResults parsed
+	out = &pb.Output{JsonResults: `{"testResults":[{"name":"/src/a.test.js","assertionResults":[{"ancestorTitles":["maths"],"fullName":"maths adds","status":"passed"}]}]}`, Command: &api.Command{IsTestRun: true}, Worker: &pb.Worker{Number: 3}}
+	got, err = OutputTestResults(ctx, "Jest", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Suite != "maths" || got[0].WorkerNumber != 3 {
+		t.Errorf("expected the jest results to be parsed got %+v", got)
+	}
+}
+
+func TestJestSkipCount(t *testing.T) {
+	counts := TestCounts{PassCount: 5}
+	jest, _ := GetFramework("Jest")
+	jest.UpdateCounts(context.Background(), &pb.Output{Stderr: "Tests: 1 skipped test", JsonResults: `{"testResults":[{"name":"/src/a.test.js","assertionResults":[{"fullName":"PASS later","status":"pending"}]}]}`, Command: &api.Command{IsTestRun: true}}, &counts)
+	if counts.SkipCount != 1 || counts.PassCount != 5 {
+		t.Errorf("expected a skip to be counted as a skip got %+v", counts)
+	}
+}
+
+func TestRepoRelativeFile(t *testing.T) {
+	tests := []struct {
+		file    string
+		workDir string
+		want    string
+	}{
+		{"/tmp/remote_dir/src/a.test.js", "/tmp/remote_dir/", "src/a.test.js"},
+		{"/tmp/remote_dir/src/a.test.js", "/tmp/remote_dir", "src/a.test.js"},
+		{"./spec/a_spec.rb", "/tmp/remote_dir/", "spec/a_spec.rb"},
+		{"spec/a_spec.rb", "/tmp/remote_dir/", "spec/a_spec.rb"},
+		{"/other/a.test.js", "/tmp/remote_dir/", "/other/a.test.js"},
+		{"./spec/a_spec.rb", "", "spec/a_spec.rb"},
+	}
+	for _, tt := range tests {
+		if got := RepoRelativeFile(tt.file, tt.workDir); got != tt.want {
+			t.Errorf("RepoRelativeFile(%q, %q) = %q, want %q", tt.file, tt.workDir, got, tt.want)
+		}
+	}
+}
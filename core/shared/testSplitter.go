@@ -260,6 +260,8 @@ type PreTestInfo struct {
 	Filenames      []string
 	TotalTestCount int
 	TotalSkipCount int
+	// ExampleIds are the ids of the tests in each file, for frameworks that can run a single test by its id
+	ExampleIds map[string][]string
 }
 type JestFiles struct {
 	Files []string
@@ -298,14 +300,18 @@ func SplitForRSpec(ctx context.Context, jsonString string) (PreTestInfo, error)
 		return PreTestInfo{}, err
 	}
 	var fileNames []string
+	exampleIds := map[string][]string{}
 	Logger(ctx).Debugf("the result.Examples length is %v", len(result.Examples))
 	for i := 0; i < len(result.Examples); i++ {
 		fileNames = append(fileNames, result.Examples[i].FilePath)
+		if result.Examples[i].ID != "" {
+			exampleIds[result.Examples[i].FilePath] = append(exampleIds[result.Examples[i].FilePath], result.Examples[i].ID)
+		}
 
 	}
 	Logger(ctx).Info("splitForRSpec--")
 	Logger(ctx).Debug("splitForRSpec--")
-	return PreTestInfo{Filenames: RemoveDuplicatesFromSlice(ctx, fileNames), TotalTestCount: result.Summary.ExampleCount, TotalSkipCount: result.Summary.PendingCount}, nil
+	return PreTestInfo{Filenames: RemoveDuplicatesFromSlice(ctx, fileNames), TotalTestCount: result.Summary.ExampleCount, TotalSkipCount: result.Summary.PendingCount, ExampleIds: exampleIds}, nil
 }
 
 type RspecFiles struct {
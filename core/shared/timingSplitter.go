@@ -0,0 +1,420 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// the algorithms understood in the alogorithm field of a SplitRequest
+const (
+	SplitRoundRobin      = "round-robin"
+	SplitFileSize        = "file-size"
+	SplitTimingPartition = "timing-partition"
+	SplitLPT             = "lpt"
+)
+
+var UnknownSplitAlgorithmError = errors.New("unknown split algorithm")
+
+// ValidSplitAlgorithm is true for the algorithms we can split with locally, empty means let the splitter choose
+func ValidSplitAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case "", SplitRoundRobin, SplitFileSize, SplitTimingPartition, SplitLPT:
+		return true
+	}
+	return false
+}
+
+// FileTiming is our best estimate of how long a test file takes to run
+type FileTiming struct {
+	Duration time.Duration `json:"duration"`
+	Runs     int           `json:"runs"`
+}
+
+// SplitHistory holds the per file timings and the last split, it is kept on the supervisor so we can
+// split by timing without the API's splitting service
+type SplitHistory struct {
+	mu        sync.Mutex
+	filename  string
+	Timings   map[string]FileTiming `json:"timings"`
+	LastSplit [][]string            `json:"lastSplit"`
+}
+
+// LoadSplitHistory reads the history from filename, a missing file gives an empty history
+func LoadSplitHistory(ctx context.Context, filename string) (*SplitHistory, error) {
+	history := &SplitHistory{filename: filename, Timings: map[string]FileTiming{}}
+	if filename == "" {
+		return history, nil
+	}
+	jsonBytes, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		Logger(ctx).Debugf("No split history at %v", filename)
+		return history, nil
+	}
+	if err != nil {
+		return history, err
+	}
+	if err := json.Unmarshal(jsonBytes, history); err != nil {
+		return &SplitHistory{filename: filename, Timings: map[string]FileTiming{}}, err
+	}
+	if history.Timings == nil {
+		history.Timings = map[string]FileTiming{}
+	}
+	return history, nil
+}
+
+// Save writes the history back to the file it was loaded from
+func (h *SplitHistory) Save(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.filename == "" {
+		return nil
+	}
+	jsonBytes, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.filename), 0700); err != nil {
+		return err
+	}
+	Logger(ctx).Debugf("Saving split history for %v files to %v", len(h.Timings), h.filename)
+	// write then rename so a crash doesn't leave us with half a history
+	tmp := h.filename + ".tmp"
+	if err := os.WriteFile(tmp, jsonBytes, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.filename)
+}
+
+// RecordRun updates the timings from a finished run. A file with test results in results is measured by adding up
+// its tests' durations, that is precise so it replaces the estimate. The other files get an equal share of the rest of
+// the test command's time, a run of a single file is also precise, the more files sharing the time the less we trust
+// the share and the less it moves the estimate. Results are matched with the files relative to the repo in workDir.
+func (h *SplitHistory) RecordRun(ri *api.RunInfo, results []TestResult, workDir string) {
+	var files []string
+	inRun := map[string]string{}
+	for _, f := range ri.Files {
+		if f != "" {
+			files = append(files, f)
+			inRun[RepoRelativeFile(f, workDir)] = f
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	measured := map[string]time.Duration{}
+	var measuredTotal time.Duration
+	for _, r := range results {
+		if f, ok := inRun[RepoRelativeFile(r.File, workDir)]; ok {
+			measured[f] += r.Duration
+			measuredTotal += r.Duration
+		}
+	}
+
+	var total time.Duration
+	for _, info := range ri.ExecutionInfos {
+		if info.Command == nil || !info.Command.IsTestRun || info.Started == nil || info.Finished == nil {
+			continue
+		}
+		total += info.Finished.AsTime().Sub(info.Started.AsTime())
+	}
+	if total <= 0 && ri.StartedAt != nil && ri.FinishedAt != nil {
+		total = ri.FinishedAt.AsTime().Sub(ri.StartedAt.AsTime())
+	}
+	if total <= 0 && len(measured) == 0 {
+		return
+	}
+
+	unmeasured := len(files) - len(measured)
+	var share time.Duration
+	var weight float64
+	if unmeasured > 0 {
+		share = total / time.Duration(len(files))
+		if remaining := total - measuredTotal; remaining > 0 && len(measured) > 0 {
+			share = remaining / time.Duration(unmeasured)
+		}
+		weight = 1 / float64(unmeasured)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, f := range files {
+		if duration, ok := measured[f]; ok {
+			h.Timings[f] = FileTiming{Duration: duration, Runs: h.Timings[f].Runs + 1}
+			continue
+		}
+		if share <= 0 {
+			continue
+		}
+		timing, ok := h.Timings[f]
+		if !ok {
+			h.Timings[f] = FileTiming{Duration: share, Runs: 1}
+			continue
+		}
+		timing.Duration += time.Duration(float64(share-timing.Duration) * weight)
+		timing.Runs++
+		h.Timings[f] = timing
+	}
+}
+
+// SetLastSplit records the split we ran with so the next timing partition can start from it
+func (h *SplitHistory) SetLastSplit(buckets [][]string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.LastSplit = buckets
+}
+
+// Duration is our estimate for file, once it has been split into parts and run the parts' timings are used instead
+func (h *SplitHistory) Duration(file string, parts []string) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var total time.Duration
+	var found bool
+	for _, part := range parts {
+		if timing, ok := h.Timings[part]; ok {
+			total += timing.Duration
+			found = true
+		}
+	}
+	if found {
+		return total, true
+	}
+	timing, ok := h.Timings[file]
+	return timing.Duration, ok
+}
+
+// EstimateParts gives the parts of a file we have split up an equal share of the file's time
+// until they have timings of their own, otherwise each part would be estimated at the average for a whole file
+func (h *SplitHistory) EstimateParts(file string, parts []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	timing, ok := h.Timings[file]
+	if !ok || len(parts) == 0 {
+		return
+	}
+	share := timing.Duration / time.Duration(len(parts))
+	for _, part := range parts {
+		if _, ok := h.Timings[part]; !ok {
+			h.Timings[part] = FileTiming{Duration: share}
+		}
+	}
+}
+
+// estimates gives a duration for every file, files we've never seen get the average of the ones we have
+func (h *SplitHistory) estimates(files []string) map[string]time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var known time.Duration
+	var count int
+	for _, f := range files {
+		if timing, ok := h.Timings[f]; ok {
+			known += timing.Duration
+			count++
+		}
+	}
+	average := time.Second
+	if count > 0 {
+		average = known / time.Duration(count)
+	}
+
+	result := map[string]time.Duration{}
+	for _, f := range files {
+		if timing, ok := h.Timings[f]; ok {
+			result[f] = timing.Duration
+		} else {
+			result[f] = average
+		}
+	}
+	return result
+}
+
+// LocalSplitter splits test files on the supervisor, it answers the same requests as the API's splitting service
+type LocalSplitter struct {
+	History *SplitHistory
+	// the directory the file names are relative to, used for the file size split
+	WorkDir string
+}
+
+func (s *LocalSplitter) SplitForProject(ctx context.Context, req *api.SplitRequest) (*api.SplitResponse, error) {
+	numBuckets := int(req.NumBuckets)
+	if numBuckets > len(req.Filenames) {
+		numBuckets = len(req.Filenames)
+	}
+	if numBuckets < 1 {
+		numBuckets = 1
+	}
+	history := s.History
+	if history == nil {
+		history = &SplitHistory{Timings: map[string]FileTiming{}}
+	}
+
+	algorithm := req.Alogorithm
+	if algorithm == "" {
+		algorithm = SplitTimingPartition
+	}
+	Logger(ctx).Debugf("Splitting %v files into %v buckets locally using %v", len(req.Filenames), numBuckets, algorithm)
+
+	var buckets [][]string
+	switch algorithm {
+	case SplitRoundRobin:
+		buckets = splitRoundRobin(req.Filenames, numBuckets)
+	case SplitFileSize:
+		sizes := map[string]time.Duration{}
+		for _, f := range req.Filenames {
+			// the size stands in for the time, a file we can't stat counts as empty
+			if fi, err := os.Stat(filepath.Join(s.WorkDir, f)); err == nil {
+				sizes[f] = time.Duration(fi.Size())
+			}
+		}
+		buckets = splitLongestFirst(req.Filenames, sizes, numBuckets)
+	case SplitLPT:
+		buckets = splitLongestFirst(req.Filenames, history.estimates(req.Filenames), numBuckets)
+	case SplitTimingPartition:
+		history.mu.Lock()
+		lastSplit := history.LastSplit
+		history.mu.Unlock()
+		buckets = splitTimingPartition(req.Filenames, history.estimates(req.Filenames), lastSplit, numBuckets)
+	default:
+		return nil, fmt.Errorf("%w: %v", UnknownSplitAlgorithmError, algorithm)
+	}
+
+	response := &api.SplitResponse{SplitMethod: "local " + algorithm}
+	for _, bucket := range buckets {
+		response.FileLists = append(response.FileLists, &api.TestFiles{Filenames: bucket})
+	}
+	return response, nil
+}
+
+func splitRoundRobin(files []string, numBuckets int) [][]string {
+	buckets := make([][]string, numBuckets)
+	for i, f := range files {
+		buckets[i%numBuckets] = append(buckets[i%numBuckets], f)
+	}
+	return buckets
+}
+
+// splitLongestFirst is the longest processing time first split, the longest file goes to the least loaded bucket
+func splitLongestFirst(files []string, durations map[string]time.Duration, numBuckets int) [][]string {
+	sorted := append([]string{}, files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return durations[sorted[i]] > durations[sorted[j]]
+	})
+
+	buckets := make([][]string, numBuckets)
+	totals := make([]time.Duration, numBuckets)
+	for _, f := range sorted {
+		lightest := 0
+		for b := 1; b < numBuckets; b++ {
+			// ties go to the bucket with fewer files so files with no size or timing still spread out
+			if totals[b] < totals[lightest] || (totals[b] == totals[lightest] && len(buckets[b]) < len(buckets[lightest])) {
+				lightest = b
+			}
+		}
+		buckets[lightest] = append(buckets[lightest], f)
+		totals[lightest] += durations[f]
+	}
+	return buckets
+}
+
+// splitTimingPartition starts from the last split and, starting at the slowest bucket, moves its fastest file to the
+// fastest bucket, working in from both ends until we reach the middle. Repeated each run this balances the buckets
+// without reshuffling every file.
+func splitTimingPartition(files []string, durations map[string]time.Duration, lastSplit [][]string, numBuckets int) [][]string {
+	wanted := map[string]bool{}
+	for _, f := range files {
+		wanted[f] = true
+	}
+
+	buckets := make([][]string, numBuckets)
+	placed := map[string]bool{}
+	if len(lastSplit) == numBuckets {
+		for b, bucket := range lastSplit {
+			for _, f := range bucket {
+				if wanted[f] && !placed[f] {
+					buckets[b] = append(buckets[b], f)
+					placed[f] = true
+				}
+			}
+		}
+	}
+
+	totals := make([]time.Duration, numBuckets)
+	for b, bucket := range buckets {
+		for _, f := range bucket {
+			totals[b] += durations[f]
+		}
+	}
+	// new files, or everything on the first run, go to the least loaded bucket
+	for _, f := range files {
+		if placed[f] {
+			continue
+		}
+		lightest := 0
+		for b := 1; b < numBuckets; b++ {
+			if totals[b] < totals[lightest] || (totals[b] == totals[lightest] && len(buckets[b]) < len(buckets[lightest])) {
+				lightest = b
+			}
+		}
+		buckets[lightest] = append(buckets[lightest], f)
+		totals[lightest] += durations[f]
+		placed[f] = true
+	}
+
+	order := make([]int, numBuckets)
+	for b := range order {
+		order[b] = b
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return totals[order[i]] > totals[order[j]]
+	})
+	for i := 0; i < numBuckets/2; i++ {
+		slowest := order[i]
+		fastest := order[numBuckets-1-i]
+		// we can't split below the file level
+		if len(buckets[slowest]) < 2 {
+			continue
+		}
+		quickest := 0
+		for j, f := range buckets[slowest] {
+			if durations[f] < durations[buckets[slowest][quickest]] {
+				quickest = j
+			}
+		}
+		f := buckets[slowest][quickest]
+		// only move it if the fast bucket stays faster than the slow one was
+		if totals[fastest]+durations[f] >= totals[slowest] {
+			continue
+		}
+		buckets[slowest] = append(buckets[slowest][:quickest:quickest], buckets[slowest][quickest+1:]...)
+		buckets[fastest] = append(buckets[fastest], f)
+		totals[slowest] -= durations[f]
+		totals[fastest] += durations[f]
+	}
+	return buckets
+}
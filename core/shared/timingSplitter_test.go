@@ -0,0 +1,152 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"brisk-supervisor/api"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func bucketTotals(buckets [][]string, durations map[string]time.Duration) []time.Duration {
+	totals := make([]time.Duration, len(buckets))
+	for b, bucket := range buckets {
+		for _, f := range bucket {
+			totals[b] += durations[f]
+		}
+	}
+	return totals
+}
+
+func TestSplitLongestFirst(t *testing.T) {
+	durations := map[string]time.Duration{"a": 8 * time.Second, "b": 7 * time.Second, "c": 6 * time.Second, "d": 5 * time.Second, "e": 4 * time.Second}
+	buckets := splitLongestFirst([]string{"e", "d", "c", "b", "a"}, durations, 2)
+	totals := bucketTotals(buckets, durations)
+	// a+d+e=17 against b+c=13
+	if totals[0] != 17*time.Second || totals[1] != 13*time.Second {
+		t.Errorf("expected totals of 17s and 13s got %v from %v", totals, buckets)
+	}
+}
+
+func TestSplitTimingPartition(t *testing.T) {
+	durations := map[string]time.Duration{"a": 10 * time.Second, "b": 1 * time.Second, "c": 2 * time.Second, "d": 2 * time.Second}
+	lastSplit := [][]string{{"a", "b"}, {"c", "d"}, {"gone"}}
+
+	buckets := splitTimingPartition([]string{"a", "b", "c", "d"}, durations, lastSplit, 3)
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets got %v", buckets)
+	}
+	// the quickest file from the slowest bucket moves to the now empty one
+	if len(buckets[0]) != 1 || buckets[0][0] != "a" {
+		t.Errorf("expected only a to stay in the slow bucket got %v", buckets)
+	}
+	if len(buckets[2]) != 1 || buckets[2][0] != "b" {
+		t.Errorf("expected b to move to the fast bucket got %v", buckets)
+	}
+
+	// a bucket count that doesn't match the last split starts again
+	buckets = splitTimingPartition([]string{"a", "b", "c", "d"}, durations, lastSplit, 2)
+	totals := bucketTotals(buckets, durations)
+	if totals[0]+totals[1] != 15*time.Second {
+		t.Errorf("expected every file to be placed got %v", buckets)
+	}
+}
+
+func TestSplitHistoryRecordRun(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "history.json")
+	history, err := LoadSplitHistory(ctx, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	testRun := func(d time.Duration) *api.ExecutionInfo {
+		return &api.ExecutionInfo{Command: &api.Command{IsTestRun: true}, Started: timestamppb.New(start), Finished: timestamppb.New(start.Add(d))}
+	}
+	history.RecordRun(&api.RunInfo{Files: []string{"a", "b"}, ExecutionInfos: []*api.ExecutionInfo{{Command: &api.Command{}, Started: timestamppb.New(start), Finished: timestamppb.New(start.Add(time.Hour))}, testRun(4 * time.Second)}}, nil, "")
+	if history.Timings["a"].Duration != 2*time.Second {
+		t.Errorf("expected a to get half of the test run got %v", history.Timings["a"])
+	}
+	// a single file run is trusted completely
+	history.RecordRun(&api.RunInfo{Files: []string{"a"}, ExecutionInfos: []*api.ExecutionInfo{testRun(6 * time.Second)}}, nil, "")
+	if history.Timings["a"].Duration != 6*time.Second || history.Timings["a"].Runs != 2 {
+		t.Errorf("expected a to take 6s over 2 runs got %+v", history.Timings["a"])
+	}
+	history.SetLastSplit([][]string{{"a"}, {"b"}})
+	if err := history.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSplitHistory(ctx, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Timings["b"].Duration != 2*time.Second || len(loaded.LastSplit) != 2 {
+		t.Errorf("expected the saved history to load got %+v", loaded)
+	}
+
+	splitter := LocalSplitter{History: loaded}
+	response, err := splitter.SplitForProject(ctx, &api.SplitRequest{Alogorithm: SplitLPT, NumBuckets: 5, Filenames: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.FileLists) != 2 || response.SplitMethod != "local lpt" {
+		t.Errorf("expected 2 buckets from lpt got %+v", response)
+	}
+	_, err = splitter.SplitForProject(ctx, &api.SplitRequest{Alogorithm: "fastest", NumBuckets: 2, Filenames: []string{"a", "b"}})
+	if !errors.Is(err, UnknownSplitAlgorithmError) {
+		t.Errorf("expected an unknown algorithm error got %v", err)
+	}
+}
+
+func TestSplitHistoryRecordRunWithResults(t *testing.T) {
+	history := &SplitHistory{Timings: map[string]FileTiming{}}
+	start := time.Now()
+	run := &api.RunInfo{Files: []string{"a", "b", "c"}, ExecutionInfos: []*api.ExecutionInfo{{Command: &api.Command{IsTestRun: true}, Started: timestamppb.New(start), Finished: timestamppb.New(start.Add(10 * time.Second))}}}
+	results := []TestResult{{File: "c", Name: "one", Duration: 3 * time.Second}, {File: "c", Name: "two", Duration: time.Second}, {File: "elsewhere", Duration: time.Hour}}
+
+	history.RecordRun(run, results, "/tmp/remote_dir/")
+	if history.Timings["c"].Duration != 4*time.Second {
+		t.Errorf("expected c to be measured from its tests got %v", history.Timings["c"])
+	}
+	if history.Timings["a"].Duration != 3*time.Second || history.Timings["b"].Duration != 3*time.Second {
+		t.Errorf("expected a and b to share the rest of the run got %v", history.Timings)
+	}
+	if _, ok := history.Timings["elsewhere"]; ok {
+		t.Error("expected results from files outside the run to be ignored")
+	}
+}
+
+func TestSplitHistoryRecordRunMatchesPaths(t *testing.T) {
+	history := &SplitHistory{Timings: map[string]FileTiming{}}
+	start := time.Now()
+	run := &api.RunInfo{Files: []string{"src/a.test.js", "spec/b_spec.rb"}, ExecutionInfos: []*api.ExecutionInfo{{Command: &api.Command{IsTestRun: true}, Started: timestamppb.New(start), Finished: timestamppb.New(start.Add(10 * time.Second))}}}
+	// jest reports absolute paths and rspec ./ ones
+	results := []TestResult{{File: "/tmp/remote_dir/src/a.test.js", Duration: 2 * time.Second}, {File: "./spec/b_spec.rb", Duration: 5 * time.Second}}
+
+	history.RecordRun(run, results, "/tmp/remote_dir/")
+	if history.Timings["src/a.test.js"].Duration != 2*time.Second || history.Timings["spec/b_spec.rb"].Duration != 5*time.Second {
+		t.Errorf("expected both files to be measured from their tests got %v", history.Timings)
+	}
+	if len(history.Timings) != 2 {
+		t.Errorf("expected the timings to be kept under the split names got %v", history.Timings)
+	}
+}
@@ -49,6 +49,7 @@ const (
 	Rails   Framework = "Rails"
 	Python  Framework = "Python"
 	Raw     Framework = "Raw"
+	Go      Framework = "Go"
 )
 
 type workerRunInfoUID string
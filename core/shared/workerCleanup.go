@@ -0,0 +1,259 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	. "brisk-supervisor/shared/logger"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// workers are reused between runs so anything a run starts has to be gone before the next one
+
+// ProcessGroups are the process groups started during a run, TermStart puts each command in a group of its own
+type ProcessGroups struct {
+	mu    sync.Mutex
+	pgids []int
+}
+
+func (p *ProcessGroups) Add(pid int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pgids = append(p.pgids, pid)
+}
+
+// killWait is how long a group gets to go after SIGKILL, it can take a moment for the kernel to tear down big processes
+const killWait = 5 * time.Second
+
+// Terminate sends SIGINT to every group like terminate and waits up to grace for them to go, anything left is killed.
+// It returns once the groups have gone so whatever runs next doesn't see them.
+func (p *ProcessGroups) Terminate(ctx context.Context, grace time.Duration) {
+	p.mu.Lock()
+	pgids := p.pgids
+	p.pgids = nil
+	p.mu.Unlock()
+
+	running := func() []int {
+		var alive []int
+		for _, pgid := range pgids {
+			if processGroupRunning(pgid) {
+				alive = append(alive, pgid)
+			}
+		}
+		return alive
+	}
+	pgids = running()
+	if len(pgids) == 0 {
+		return
+	}
+	Logger(ctx).Infof("Terminating the process groups %v left running by the run", pgids)
+	for _, pgid := range pgids {
+		syscall.Kill(-pgid, syscall.SIGINT)
+	}
+	deadline := time.Now().Add(grace)
+	for len(pgids) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		pgids = running()
+	}
+	for _, pgid := range pgids {
+		Logger(ctx).Infof("Process group %v didn't stop so sending SIGKILL", pgid)
+		syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+	deadline = time.Now().Add(killWait)
+	for len(pgids) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		pgids = running()
+	}
+	if len(pgids) > 0 {
+		Logger(ctx).Warnf("Process groups %v are still running after SIGKILL", pgids)
+	}
+}
+
+// processGroupRunning is true while the group has a process that isn't a zombie, the processes the group leader
+// started are children of init once it has gone and init doesn't always reap them
+func processGroupRunning(pgid int) bool {
+	if syscall.Kill(-pgid, 0) != nil {
+		return false
+	}
+	processes, err := readProcesses()
+	if err != nil {
+		return true
+	}
+	for _, process := range processes {
+		if process.pgrp == pgid {
+			return true
+		}
+	}
+	return false
+}
+
+type procStat struct {
+	pid  int
+	name string
+	pgrp int
+}
+
+// readProcesses reads the processes from /proc/<pid>/stat, leaving out zombies
+func readProcesses() ([]procStat, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var processes []procStat
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			// it has already exited
+			continue
+		}
+		// pid (comm) state ppid pgrp ..., comm can have spaces and brackets in it
+		start, end := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+		if start < 0 || end < start {
+			continue
+		}
+		fields := strings.Fields(string(stat[end+1:]))
+		if len(fields) < 3 || fields[0] == "Z" {
+			continue
+		}
+		pgrp, _ := strconv.Atoi(fields[2])
+		processes = append(processes, procStat{pid: pid, name: string(stat[start+1 : end]), pgrp: pgrp})
+	}
+	return processes, nil
+}
+
+// WorkerSnapshot is what is running and listening on the worker, from /proc
+type WorkerSnapshot struct {
+	// Processes are the names of the processes by pid
+	Processes map[int]string
+	Ports     map[int]bool
+}
+
+func TakeWorkerSnapshot() (WorkerSnapshot, error) {
+	snapshot := WorkerSnapshot{Processes: map[int]string{}, Ports: map[int]bool{}}
+	processes, err := readProcesses()
+	if err != nil {
+		return snapshot, err
+	}
+	for _, process := range processes {
+		if process.pid != os.Getpid() {
+			snapshot.Processes[process.pid] = process.name
+		}
+	}
+	for _, table := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		data, err := os.ReadFile(table)
+		if err != nil {
+			continue
+		}
+		for port := range parseListeningPorts(data) {
+			snapshot.Ports[port] = true
+		}
+	}
+	return snapshot, nil
+}
+
+// parseListeningPorts reads a /proc/net/tcp table, the local address is hex ip:port and state 0A is LISTEN
+func parseListeningPorts(data []byte) map[int]bool {
+	ports := map[int]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != "0A" {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if port, err := strconv.ParseInt(hexPort, 16, 32); err == nil {
+			ports[int(port)] = true
+		}
+	}
+	return ports
+}
+
+// LeftSince is what is in s that wasn't in before
+func (s WorkerSnapshot) LeftSince(before WorkerSnapshot) WorkerSnapshot {
+	left := WorkerSnapshot{Processes: map[int]string{}, Ports: map[int]bool{}}
+	for pid, name := range s.Processes {
+		if _, ok := before.Processes[pid]; !ok {
+			left.Processes[pid] = name
+		}
+	}
+	for port := range s.Ports {
+		if !before.Ports[port] {
+			left.Ports[port] = true
+		}
+	}
+	return left
+}
+
+// StillIn is what is in s that is also in current, the same pid with a different name is a different process
+func (s WorkerSnapshot) StillIn(current WorkerSnapshot) WorkerSnapshot {
+	still := WorkerSnapshot{Processes: map[int]string{}, Ports: map[int]bool{}}
+	for pid, name := range s.Processes {
+		if current.Processes[pid] == name {
+			still.Processes[pid] = name
+		}
+	}
+	for port := range s.Ports {
+		if current.Ports[port] {
+			still.Ports[port] = true
+		}
+	}
+	return still
+}
+
+func (s WorkerSnapshot) Empty() bool {
+	return len(s.Processes) == 0 && len(s.Ports) == 0
+}
+
+func (s WorkerSnapshot) String() string {
+	var parts []string
+	if len(s.Processes) > 0 {
+		pids := make([]int, 0, len(s.Processes))
+		for pid := range s.Processes {
+			pids = append(pids, pid)
+		}
+		sort.Ints(pids)
+		var processes []string
+		for _, pid := range pids {
+			processes = append(processes, fmt.Sprintf("%v (%v)", s.Processes[pid], pid))
+		}
+		parts = append(parts, "processes "+strings.Join(processes, ", "))
+	}
+	if len(s.Ports) > 0 {
+		ports := make([]int, 0, len(s.Ports))
+		for port := range s.Ports {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		parts = append(parts, "listening ports "+strings.Trim(fmt.Sprint(ports), "[]"))
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const procNetTcp = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1 1 0 100 0 0 10 0
+   1: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 2 1 0 100 0 0 10 0
+   2: 0100007F:0BB8 0100007F:D3A2 01 00000000:00000000 00:00000000 00000000  1000        0 3 1 0 20 4 30 10 -1
+`
+
+func TestWorkerSnapshot(t *testing.T) {
+	if got := parseListeningPorts([]byte(procNetTcp)); !reflect.DeepEqual(got, map[int]bool{3000: true, 6379: true}) {
+		t.Errorf("expected ports 3000 and 6379 got %v", got)
+	}
+
+	before := WorkerSnapshot{Processes: map[int]string{1: "tini", 7: "server"}, Ports: map[int]bool{50052: true}}
+	after := WorkerSnapshot{Processes: map[int]string{1: "tini", 7: "server", 120: "redis-server", 98: "node"}, Ports: map[int]bool{50052: true, 6379: true}}
+	left := after.LeftSince(before)
+	if got := left.String(); got != "processes node (98), redis-server (120) and listening ports 6379" {
+		t.Errorf("unexpected leftovers %q", got)
+	}
+
+	// node has exited and its pid has been reused
+	next := WorkerSnapshot{Processes: map[int]string{1: "tini", 7: "server", 120: "redis-server", 98: "bash"}, Ports: map[int]bool{50052: true}}
+	if got := left.StillIn(next).String(); got != "processes redis-server (120)" {
+		t.Errorf("unexpected leftovers still running %q", got)
+	}
+	if !left.StillIn(before).Empty() {
+		t.Error("expected nothing to be left")
+	}
+}
+
+func TestProcessGroupsTerminate(t *testing.T) {
+	var processes ProcessGroups
+	var commands []*exec.Cmd
+	// the second one ignores SIGINT so it has to be killed
+	for _, script := range []string{"sleep 60 & sleep 60", "trap '' INT; sleep 60 & sleep 60"} {
+		cmd := exec.Command("bash", "-c", script)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		go cmd.Wait()
+		commands = append(commands, cmd)
+		processes.Add(cmd.Process.Pid)
+	}
+
+	// give bash time to set up the trap
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	processes.Terminate(context.Background(), 500*time.Millisecond)
+	if took := time.Since(start); took < 500*time.Millisecond || took > 5*time.Second {
+		t.Errorf("expected to wait for the grace period then kill the group, took %v", took)
+	}
+	for _, cmd := range commands {
+		if processGroupRunning(cmd.Process.Pid) {
+			t.Errorf("expected process group %v to be gone", cmd.Process.Pid)
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+	}
+}
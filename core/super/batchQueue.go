@@ -0,0 +1,118 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+)
+
+// batchQueue holds the test files for a dynamically split run, workers pull the next batch when they finish the last one
+// so a slow file or a slow host doesn't leave the other workers sitting idle
+type batchQueue struct {
+	mu        sync.Mutex
+	files     []string
+	batchSize int
+	// files that have been handed out but not finished, by worker uid
+	inFlight map[string][]string
+	// every file handed to a worker during the run, by worker uid
+	assigned map[string][]string
+}
+
+func newBatchQueue(files []string, batchSize int) *batchQueue {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	queued := make([]string, len(files))
+	copy(queued, files)
+	return &batchQueue{files: queued, batchSize: batchSize, inFlight: map[string][]string{}, assigned: map[string][]string{}}
+}
+
+// defaultBatchSize aims for each worker to pull around four batches so there is room to even out the slow ones
+func defaultBatchSize(numFiles int, numWorkers int) int {
+	if numWorkers < 1 {
+		return numFiles
+	}
+	size := numFiles / (numWorkers * 4)
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// Next hands the worker its next batch of files, an empty batch means the queue is drained
+func (q *batchQueue) Next(workerUid string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	size := q.batchSize
+	if size > len(q.files) {
+		size = len(q.files)
+	}
+	batch := q.files[:size]
+	q.files = q.files[size:]
+	if len(batch) > 0 {
+		q.inFlight[workerUid] = batch
+		q.assigned[workerUid] = append(q.assigned[workerUid], batch...)
+	}
+	return batch
+}
+
+// Done marks the worker's in flight batch as finished
+func (q *batchQueue) Done(workerUid string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inFlight, workerUid)
+}
+
+// Requeue puts a lost worker's in flight batch back at the front of the queue and returns it
+func (q *batchQueue) Requeue(workerUid string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	batch := q.inFlight[workerUid]
+	delete(q.inFlight, workerUid)
+	q.files = append(append([]string{}, batch...), q.files...)
+	return append([]string{}, batch...)
+}
+
+// InFlight returns a copy of the batches currently being run, by worker uid
+func (q *batchQueue) InFlight() map[string][]string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	inFlight := map[string][]string{}
+	for uid, batch := range q.inFlight {
+		inFlight[uid] = append([]string{}, batch...)
+	}
+	return inFlight
+}
+
+// Assigned returns all the files the worker has been given during the run
+func (q *batchQueue) Assigned(workerUid string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string{}, q.assigned[workerUid]...)
+}
+
+func (q *batchQueue) Remaining() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.files)
+}
+
+// Batches is how many batches are left in the queue, the most a worker starting now could pull
+func (q *batchQueue) Batches() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return (len(q.files) + q.batchSize - 1) / q.batchSize
+}
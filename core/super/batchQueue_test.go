@@ -0,0 +1,141 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_batchQueue(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name          string
+		run           func(q *batchQueue) []string
+		want          []string
+		wantRemaining int
+		wantBatches   int
+		wantInFlight  map[string][]string
+	}{
+		{
+			name:          "next hands out a batch",
+			run:           func(q *batchQueue) []string { return q.Next("w1") },
+			want:          []string{"a", "b"},
+			wantRemaining: 3,
+			wantBatches:   2,
+			wantInFlight:  map[string][]string{"w1": {"a", "b"}},
+		},
+		{
+			name: "the last batch is short and then the queue is drained",
+			run: func(q *batchQueue) []string {
+				q.Next("w1")
+				q.Next("w2")
+				q.Done("w1")
+				q.Done("w2")
+				last := q.Next("w1")
+				q.Done("w1")
+				if empty := q.Next("w1"); len(empty) != 0 {
+					return empty
+				}
+				return last
+			},
+			want:          []string{"e"},
+			wantRemaining: 0,
+			wantBatches:   0,
+			wantInFlight:  map[string][]string{},
+		},
+		{
+			name: "done takes the batch out of flight",
+			run: func(q *batchQueue) []string {
+				q.Next("w1")
+				q.Done("w1")
+				return q.Assigned("w1")
+			},
+			want:          []string{"a", "b"},
+			wantRemaining: 3,
+			wantBatches:   2,
+			wantInFlight:  map[string][]string{},
+		},
+		{
+			name: "requeue puts a lost worker's batch back at the front",
+			run: func(q *batchQueue) []string {
+				q.Next("w1")
+				q.Next("w2")
+				q.Done("w1")
+				return q.Requeue("w2")
+			},
+			want:          []string{"c", "d"},
+			wantRemaining: 3,
+			wantBatches:   2,
+			wantInFlight:  map[string][]string{},
+		},
+		{
+			name: "requeue of a finished worker has nothing to give back",
+			run: func(q *batchQueue) []string {
+				q.Next("w1")
+				q.Done("w1")
+				return q.Requeue("w1")
+			},
+			want:          []string{},
+			wantRemaining: 3,
+			wantBatches:   2,
+			wantInFlight:  map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newBatchQueue(files, 2)
+			if got := tt.run(q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := q.Remaining(); got != tt.wantRemaining {
+				t.Errorf("Remaining() = %v, want %v", got, tt.wantRemaining)
+			}
+			if got := q.Batches(); got != tt.wantBatches {
+				t.Errorf("Batches() = %v, want %v", got, tt.wantBatches)
+			}
+			if got := q.InFlight(); !reflect.DeepEqual(got, tt.wantInFlight) {
+				t.Errorf("InFlight() = %v, want %v", got, tt.wantInFlight)
+			}
+		})
+	}
+}
+
+func Test_requeuedBatchIsNextOut(t *testing.T) {
+	q := newBatchQueue([]string{"a", "b", "c"}, 2)
+	q.Next("lost")
+	q.Requeue("lost")
+	if got := q.Next("w2"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Next() after Requeue() = %v, want the requeued batch", got)
+	}
+	if got := q.Assigned("w2"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Assigned() = %v, want the requeued batch", got)
+	}
+}
+
+func Test_defaultBatchSize(t *testing.T) {
+	tests := []struct {
+		files, workers, want int
+	}{
+		{100, 5, 5},
+		{10, 5, 1},
+		{3, 0, 3},
+	}
+	for _, tt := range tests {
+		if got := defaultBatchSize(tt.files, tt.workers); got != tt.want {
+			t.Errorf("defaultBatchSize(%v, %v) = %v, want %v", tt.files, tt.workers, got, tt.want)
+		}
+	}
+}
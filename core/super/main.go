@@ -20,13 +20,13 @@ import (
 	"brisk-supervisor/shared/constants"
 	"brisk-supervisor/shared/env"
 	"brisk-supervisor/shared/honeycomb"
+	"brisk-supervisor/shared/logcrypt"
 	. "brisk-supervisor/shared/logger"
 	"brisk-supervisor/shared/nomad"
 	"brisk-supervisor/shared/types"
 	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -54,6 +54,7 @@ import (
 	pb "brisk-supervisor/brisk-supervisor"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	. "brisk-supervisor/shared"
@@ -84,6 +85,24 @@ var globalPublicKey string
 var globalPrivateKey string
 var globalInstanceID string
 
+// where the output of runs is kept once they finish
+var logStore log_service.LogStore
+
+// the test file timings recorded on this supervisor, used to split when the API can't
+var splitHistory *SplitHistory
+var splitHistoryOnce sync.Once
+
+func getSplitHistory(ctx context.Context) *SplitHistory {
+	splitHistoryOnce.Do(func() {
+		var err error
+		splitHistory, err = LoadSplitHistory(ctx, viper.GetString("SPLIT_HISTORY_FILE"))
+		if err != nil {
+			Logger(ctx).Errorf("Error loading split history so starting again %v", err)
+		}
+	})
+	return splitHistory
+}
+
 type server struct {
 	pb.UnimplementedBriskSupervisorServer
 }
@@ -182,6 +201,42 @@ const name = "supervisor"
 // lock channel
 var lockChannel = make(chan bool, 1)
 
+// lockDeadline is when a held lock is given up, each session on a worker pushes it back so the lock outlasts the run
+type lockDeadline struct {
+	mu sync.Mutex
+	at time.Time
+	// changed is closed and replaced each time the deadline moves
+	changed chan struct{}
+}
+
+var heldLock = &lockDeadline{changed: make(chan struct{})}
+
+// reset starts the deadline again for a new lock
+func (d *lockDeadline) reset(timeout time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.at = time.Now().Add(timeout)
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// extend makes sure the lock is held for at least timeout from now
+func (d *lockDeadline) extend(timeout time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if at := time.Now().Add(timeout); at.After(d.at) {
+		d.at = at
+		close(d.changed)
+		d.changed = make(chan struct{})
+	}
+}
+
+func (d *lockDeadline) get() (time.Time, <-chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.at, d.changed
+}
+
 // so long as we hold a stream to this lock function, we hold the lock
 // as soon as we close the stream, we release the lock
 // for graceful stop this will continue to hold the lock because graceful stop just prevents new connections
@@ -209,17 +264,24 @@ func (s *server) Lock(LockRequest *brisksupervisor.LockRequest, stream brisksupe
 			Logger(ctx).Errorf("Error sending lock response %v", streamErr)
 		}
 
-		select {
-		case <-ctx.Done():
-			Logger(ctx).Infof("Context done so unlocking %s cause is ", ctx.Err(), context.Cause(ctx))
-			lockChannel <- true
-			return nil
-		case <-time.After(projectRunTimeout):
+		// the run gets PROJECT_RUN_TIMEOUT to start and then as long as its sessions on the workers need
+		lockedAt := time.Now()
+		heldLock.reset(projectRunTimeout)
+		for {
+			deadline, changed := heldLock.get()
+			select {
+			case <-ctx.Done():
+				Logger(ctx).Infof("Context done so unlocking %s cause is ", ctx.Err(), context.Cause(ctx))
+				lockChannel <- true
+				return nil
+			case <-changed:
+			case <-time.After(time.Until(deadline)):
 
-			Logger(ctx).Error("We timed out waiting for the lock to be released")
-			lockChannel <- true
-			return errors.New(fmt.Sprintf("Time out waiting for test to finish after %v", projectRunTimeout))
+				Logger(ctx).Error("We timed out waiting for the lock to be released")
+				lockChannel <- true
+				return errors.New(fmt.Sprintf("Time out waiting for test to finish after %v", time.Since(lockedAt)))
 
+			}
 		}
 
 	case <-time.After(superTimeout):
@@ -274,6 +336,10 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 	if len(TestOption.Commands) == 0 {
 		return errors.New("no commands passed to RunTests")
 	}
+	graph, graphErr := NewCommandGraph(TestOption.Commands)
+	if graphErr != nil {
+		return graphErr
+	}
 
 	Logger(ctx).Debugf("The context is %+v ", ctx)
 	Logger(ctx).Debugf("The test option : %+v", TestOption)
@@ -304,43 +370,26 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 			Logger(ctx).Panic("Panic in RunTests - repanicing %v", r)
 		}
 	}(responseStream)
-	s3Buffer := &bytes.Buffer{}
+	logBuffer := &bytes.Buffer{}
 	logUid := uuid.New().String()
 	Logger(ctx).Infof("logUid for this group of runs is %s", logUid)
-	streamInfo := types.LogStreamInfo{LogUid: logUid}
-	defer sendBufferToS3(context.Background(), s3Buffer, streamInfo, Logger(ctx))
+	// the logs of the run are encrypted with a key made for it, only the CLI that started the run is given it
+	logKey, err := logcrypt.NewLogEncryptionKey()
+	if err != nil {
+		Logger(ctx).Errorf("Error making the log encryption key %v", err)
+		return err
+	}
+	streamInfo := types.LogStreamInfo{LogUid: logUid, ProjectToken: globalProjectToken, LogEncryptionKey: logKey}
+	defer storeLog(context.Background(), logBuffer, streamInfo, Logger(ctx))
+	run := runLogs.start(streamInfo)
+	// the workers store it again as they finish, this is for a run without any
+	defer storeWorkerLogIndex(context.Background(), run, logKey, Logger(ctx))
+	defer run.finish()
+	responseStream <- &pb.Output{RunId: logUid, LogEncryptionKey: logKey, Created: timestamppb.Now()}
 
 	go func() {
-
-		for rs := range responseStream {
-
-			output := rs
-			if output.Stderr != "" {
-				s3Buffer.WriteString(fmt.Sprintf("%v %v : %v ", output.Created.AsTime().Format("2006-01-02 15:04:05"), PrintWorkerDetails(output), output.Stderr))
-				s3Buffer.WriteRune('\n')
-
-			}
-
-			if output.Stdout != "" {
-				s3Buffer.WriteString(fmt.Sprintf("%v %v : %v ", output.Created.AsTime().Format("2006-01-02 15:04:05"), PrintWorkerDetails(output), output.Stdout))
-				s3Buffer.WriteRune('\n')
-			}
-
-			if output.Response != "" && output.Response != output.Stderr && output.Response != output.Stdout {
-				// only want to share when it's not the same as stderr or stdout
-				s3Buffer.WriteString(fmt.Sprintf("%v %v : %v ", output.Created.AsTime().Format("2006-01-02 15:04:05"), PrintWorkerDetails(output), output.Response))
-				s3Buffer.WriteRune('\n')
-			}
-
-			Logger(ctx).Debugf("sending Response: %+v", rs)
-
-			streamErr := stream.Send(rs)
-			if streamErr != nil {
-				Logger(ctx).Errorf("Error sending response %v", streamErr)
-				errChannel <- streamErr
-				return
-			}
-
+		if streamErr := forwardResponses(responseStream, stream.Send, logBuffer, run, Logger(ctx)); streamErr != nil {
+			errChannel <- streamErr
 		}
 	}()
 	Logger(ctx).Debugf("The environment passed to the Super is %+v", TestOption.Config.Environment)
@@ -349,14 +398,32 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 	protectedCounter := ProtectedCounter{}
 	protectedCounter.Add(len(TestOption.Commands))
 
-	for _, c := range TestOption.Commands {
-		go func(command api.Command) {
+	pipeline := newCommandPipeline(graph, TestOption.Commands, responseStream)
+
+	for i, c := range TestOption.Commands {
+		go func(i int, command api.Command) {
+			if graph.HasDependencies() {
+				command.Stage = graph.StageName(i)
+				if err := pipeline.waitForDependencies(ctx, i, &command); err != nil {
+					select {
+					case errChannel <- err:
+					case <-ctx.Done():
+					}
+					return
+				}
+			}
+			// the stage status is sent before the result so it isn't lost when RunTests returns on the first error
+			finish := func(passed bool, err error) {
+				pipeline.finished(i, &command, passed)
+				errChannel <- err
+			}
+
 			retry := true
 			for retryCount := 0; retry && retryCount < (1+viper.GetInt("REBUILD_RETRY")); retryCount++ {
 
 				var outErr error
 
-				retry, outErr = runTestTheTests(ctx, responseStream, TestOption.BuildCommands, command, TestOption.Config, *TestOption.RepoInfo, logUid)
+				retry, outErr = runTestTheTests(ctx, responseStream, TestOption.BuildCommands, command, TestOption.Config, *TestOption.RepoInfo, run, logKey)
 
 				if outErr != nil {
 					if outErr == context.Canceled {
@@ -370,10 +437,17 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 						}
 					}
 
+					if TestOption.Config.NoFailFast && errors.Is(outErr, TestFailedError) {
+						// the failures have been sent already, the commands that don't depend on this one carry on
+						Logger(ctx).Infof("command %v failed but not failing fast", graph.Name(i))
+						finish(false, outErr)
+						return
+					}
+
 					Logger(ctx).Errorf("error from test run %v", outErr)
 					responseStream <- &pb.Output{Response: "error from test run", Stdout: "error from test run", Stderr: outErr.Error(), Created: timestamppb.Now()}
 
-					errChannel <- outErr
+					finish(false, outErr)
 					return
 				}
 				if retry {
@@ -388,25 +462,34 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 				// we've left the loop because we've retried too many times
 				Logger(ctx).Error("Timed out waiting for workers to rebuild")
 				responseStream <- &pb.Output{Response: "Timed out waiting for workers to rebuild", Created: timestamppb.Now()}
-				errChannel <- errors.New("Timed out waiting for workers to rebuild")
+				finish(false, errors.New("Timed out waiting for workers to rebuild"))
 				return
 			}
 
-			errChannel <- nil
+			finish(true, nil)
 
-		}(*c)
+		}(i, *c)
 	}
 
 	Logger(ctx).Info("Waiting at end of RunTests")
 
+	// without failing fast the other commands carry on after a failure, the run still fails once they have finished
+	var failed error
 	for err := range errChannel {
 
-		if err != nil {
+		if err != nil && !carriesOn(TestOption.Config, err) {
 			Logger(ctx).Errorf("Got an error from test run %v", err)
 			// this is where we could decide to wait for everyone to finish - but we are failing fast now
 			return err
 		} else {
-			Logger(ctx).Info("No error from test run - things finished normally")
+			if err != nil {
+				Logger(ctx).Infof("Command didn't pass, waiting on the rest %v", err)
+				if failed == nil {
+					failed = err
+				}
+			} else {
+				Logger(ctx).Info("No error from test run - things finished normally")
+			}
 			protectedCounter.Done()
 			if protectedCounter.Finished() {
 				Logger(ctx).Info("All tests finished")
@@ -416,10 +499,19 @@ func (s *server) RunTests(TestOption *pb.TestOption, stream brisksupervisor.Bris
 
 	}
 	// we get here if the errChannel is closed without an error being sent to it
+	if failed != nil {
+		return failed
+	}
 	Logger(ctx).Info("No error from test run - things finished normally")
 
 	return nil
 }
+
+// carriesOn is true when the run waits for the other commands after err, a failing command when not failing fast or
+// a command skipped because of it
+func carriesOn(config *brisksupervisor.Config, err error) bool {
+	return config.NoFailFast && (errors.Is(err, TestFailedError) || errors.Is(err, stageSkippedError))
+}
 func CheckIfFirstRun(ctx context.Context, filePath string) error {
 	Logger(ctx).Debug("Checking if this is the first run")
 	Logger(ctx).Info("in CheckIfFirstRun printing out the stacktrace so we know where we came from")
@@ -496,7 +588,15 @@ func main() {
 
 	Logger(ctx).Info("Init Super")
 
+	var logStoreErr error
+	logStore, logStoreErr = log_service.NewLogStoreFromConfig(IsDev())
+	if logStoreErr != nil {
+		Logger(ctx).Errorf("Can't set up the log store %v", logStoreErr)
+		SafeExit(logStoreErr)
+	}
+
 	serverGracefulStop := listenOn(ctx, ":50050")
+	logsGracefulStop := listenForLogs(ctx, ":"+constants.LOGSERVICE_PORT)
 
 	// need to regsiter the machine if is has not already been registered
 	// need to do this for k8s
@@ -571,6 +671,7 @@ func main() {
 	Logger(ctx).Sync()
 	stopTime := time.Now()
 	serverGracefulStop()
+	logsGracefulStop()
 	Logger(ctx).Warnf("Graceful stop took %v for super %v", time.Since(stopTime), super.Uid)
 	Logger(ctx).Debug("Graceful stop finished")
 	Logger(ctx).Warn("Quitting")
@@ -584,7 +685,8 @@ func jitter(t int) time.Duration {
 	return time.Duration(rand.Intn(t)) * time.Minute
 }
 
-func listenOn(ctx context.Context, port string) func() {
+// newGrpcServer listens on port and gives a server with the keepalive, tracing and auth every service here uses
+func newGrpcServer(ctx context.Context, port string) (*grpc.Server, net.Listener) {
 	Logger(ctx).Debugf("ListenOn, port : %v", port)
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -624,6 +726,11 @@ func listenOn(ctx context.Context, port string) func() {
 			grpc_ctxtags.StreamServerInterceptor(),
 			grpc_auth.StreamServerInterceptor(DoAuth),
 		))
+	return s, lis
+}
+
+func listenOn(ctx context.Context, port string) func() {
+	s, lis := newGrpcServer(ctx, port)
 	Logger(ctx).Debugf("Listening on port %v", port)
 	pb.RegisterBriskSupervisorServer(s, &server{})
 
@@ -637,6 +744,20 @@ func listenOn(ctx context.Context, port string) func() {
 	return s.GracefulStop
 }
 
+// listenForLogs serves the log stream so other terminals can attach to the runs on this supervisor
+func listenForLogs(ctx context.Context, port string) func() {
+	s, lis := newGrpcServer(ctx, port)
+	Logger(ctx).Debugf("Serving logs on port %v", port)
+	pb.RegisterLogStreamServer(s, &logStreamServer{})
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			Logger(ctx).Errorf("failed to serve logs: %v", err)
+			SafeExit(err)
+		}
+	}()
+	return s.GracefulStop
+}
+
 func addDefaultRemoteDirectory(ctx context.Context, buildCommands []*api.Command, command api.Command) ([]*api.Command, api.Command) {
 	for i := range buildCommands {
 
@@ -668,7 +789,37 @@ func AddDefaultRemoteDirectorybriskSupervisor(ctx context.Context, buildCommands
 	return buildCommands, commands
 }
 
-func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildCommands []*api.Command, command api.Command, config *brisksupervisor.Config, repoInfo api.RepoInfo, logUid string) (bool, error) {
+// forwardResponses sends the outputs of the run to the CLI, keeping them for the log and attach, until the stream is
+// closed or a send fails
+func forwardResponses(responseStream <-chan *pb.Output, send func(*pb.Output) error, logBuffer io.Writer, run *runLog, logger *BriskLogger) error {
+	for rs := range responseStream {
+
+		WriteLogLines(logBuffer, rs)
+
+		run.add(rs)
+		logger.Debugf("sending Response: %+v", withoutLogKey(rs))
+
+		streamErr := send(rs)
+		if streamErr != nil {
+			logger.Errorf("Error sending response %v", streamErr)
+			return streamErr
+		}
+
+	}
+	return nil
+}
+
+// withoutLogKey is the output to log, the log encryption key must never end up in the supervisor's own logs
+func withoutLogKey(output *pb.Output) *pb.Output {
+	if output.LogEncryptionKey == "" {
+		return output
+	}
+	logged := proto.Clone(output).(*pb.Output)
+	logged.LogEncryptionKey = ""
+	return logged
+}
+
+func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildCommands []*api.Command, command api.Command, config *brisksupervisor.Config, repoInfo api.RepoInfo, run *runLog, logKey string) (bool, error) {
 	ctx, cancel := context.WithCancelCause(ctx)
 
 	defer cancel(errors.New("returned from runTestTheTests"))
@@ -712,6 +863,10 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 	if requestedWorkers > maxWorkers {
 		requestedWorkers = maxWorkers
 	}
+	if len(config.OnlyFiles) > 0 && !command.NoTestFiles && requestedWorkers > len(config.OnlyFiles) {
+		// no point claiming more workers than we have files to run
+		requestedWorkers = len(config.OnlyFiles)
+	}
 
 	request := command
 	startWorkerTime := time.Now()
@@ -748,7 +903,7 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 		}
 	}(ctx, Logger(ctx))
 
-	goodWorkers, jobrunId, jobrunLink, workerErr := getWorkingWorkers(ctx, requestedWorkers, config.WorkerImage, config.RebuildFilePaths, repoInfo, logUid)
+	goodWorkers, jobrunId, jobrunLink, workerErr := getWorkingWorkers(ctx, requestedWorkers, config.WorkerImage, config.RebuildFilePaths, repoInfo, run.id)
 
 	finalWorkerCount = int32(len(goodWorkers))
 	if workerErr != nil && strings.Contains(workerErr.Error(), "We don't have enough workers for this run") {
@@ -756,7 +911,7 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 		timeout := viper.GetDuration("NO_WORKER_TIMEOUT")
 		responseStream <- &pb.Output{Response: "Waiting on workers for project - trying again in " + timeout.String(), Stderr: "Waiting on workers for project", Created: timestamppb.Now()}
 		time.Sleep(timeout)
-		goodWorkers, jobrunId, jobrunLink, workerErr = getWorkingWorkers(ctx, requestedWorkers, config.WorkerImage, config.RebuildFilePaths, repoInfo, logUid)
+		goodWorkers, jobrunId, jobrunLink, workerErr = getWorkingWorkers(ctx, requestedWorkers, config.WorkerImage, config.RebuildFilePaths, repoInfo, run.id)
 
 	}
 
@@ -903,12 +1058,26 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 	// going to check the last command maybe we'll want to use the first commands for setup or something
 	var myFiles [][]string
 	var totalTestCount int
+	// set when the workers pull batches of files as they go instead of getting a fixed split up front
+	var queue *batchQueue
 
 	if command.NoTestFiles {
 		Logger(ctx).Debugf("Not a test run so not splitting files")
 		totalTestCount = len(goodWorkers)
+	} else if config.DynamicSplitting {
+		myFiles, totalTestCount, err = splitTestFiles(ctx, 1, goodWorkers[0], config, &command, responseStream, !config.SkipRecalcFiles)
+		if err == nil && len(myFiles) > 0 && len(myFiles[0]) > 0 {
+			batchSize := int(config.BatchSize)
+			if batchSize < 1 {
+				batchSize = defaultBatchSize(len(myFiles[0]), len(goodWorkers))
+			}
+			queue = newBatchQueue(myFiles[0], batchSize)
+			responseStream <- &pb.Output{Response: fmt.Sprintf("Dynamic splitting %v files in batches of %v", len(myFiles[0]), batchSize), Created: timestamppb.Now()}
+		} else if err == nil {
+			Logger(ctx).Debugf("No files to queue so every worker runs the command without files")
+		}
 	} else {
-		myFiles, totalTestCount, err = splitTestFiles(ctx, len(goodWorkers), goodWorkers[0], config, responseStream, !config.SkipRecalcFiles)
+		myFiles, totalTestCount, err = splitTestFiles(ctx, len(goodWorkers), goodWorkers[0], config, &command, responseStream, !config.SkipRecalcFiles)
 	}
 	if err != nil {
 		Logger(ctx).Errorf("Error splitting files: %v", err)
@@ -940,15 +1109,25 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 	doneChannel := make(chan bool, 1)
 
 	cs := countStruct{TotalTestCount: totalTestCount}
+	pool := newWorkerPool(len(goodWorkers))
 	Logger(ctx).Debug("about to run tests")
 	for i, worker := range goodWorkers {
 
 		go func(i int, worker api.Worker) {
 			defer bugsnag.AutoNotify(ctx)
 			defer wg.Done()
+			// once we are finished this worker can take over the files of any worker we lose
+			reassigned := false
+			defer func() {
+				if !reassigned {
+					pool.Release(worker)
+				}
+			}()
 
 			var files = []string{}
-			if len(myFiles) > i && myFiles[i] != nil && len(myFiles[i]) > 0 {
+			if queue != nil {
+				Logger(ctx).Debugf("Worker %v will pull its files from the queue", i)
+			} else if len(myFiles) > i && myFiles[i] != nil && len(myFiles[i]) > 0 {
 				Logger(ctx).Debugf("i is %v", i)
 				Logger(ctx).Debugf("Files I'm sending are %v", myFiles[i])
 				files = myFiles[i]
@@ -956,7 +1135,7 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 				Logger(ctx).Errorf("We have no files for this worker number %v", i)
 				Logger(ctx).Errorf("command is %+v", command)
 				// have to have a check here for the number of files with a command
-				if config.Framework == string(types.Python) || command.NoTestFiles {
+				if !frameworkListsTests(config, &command) || command.NoTestFiles {
 					Logger(ctx).Debug("No test files for this run")
 					files = []string{""}
 				} else {
@@ -981,7 +1160,9 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 			copV := copVI.(api.Command)
 			copV.WorkerNumber = int32(i)
 			copV.TotalWorkerCount = int32(len(goodWorkers))
-			copV.Stage = "Run"
+			if copV.Stage == "" {
+				copV.Stage = "Run"
+			}
 			copiedRequest := copV
 
 			Logger(ctx).Infof("Copied Requests %+v", copiedRequest)
@@ -1024,29 +1205,14 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 			// we read from the responseStream and write to the buffer and the actual response stream
 			go func() {
 				for output := range bufferStream {
-
-					if output.Stderr != "" {
-						buffer.WriteString(fmt.Sprintf("%v : %v ", PrintWorkerDetails(output), output.Stderr))
-						buffer.WriteRune('\n')
-
-					}
-
-					if output.Stdout != "" {
-						buffer.WriteString(fmt.Sprintf("%v : %v ", PrintWorkerDetails(output), output.Stdout))
-						buffer.WriteRune('\n')
-					}
-
-					if output.Response != "" && output.Response != output.Stderr && output.Response != output.Stdout {
-						// only want to share when it's not the same as stderr or stdout
-						buffer.WriteString(fmt.Sprintf("%v : %v ", PrintWorkerDetails(output), output.Response))
-						buffer.WriteRune('\n')
-					}
+					WriteLogLines(buffer, output)
 					responseStream <- output
 
 				}
 			}()
 
-			streamInfo := types.LogStreamInfo{WorkerRunInfoUID: uid}
+			// the key is passed in as the workers can still be running after a failure has ended the run
+			streamInfo := types.LogStreamInfo{WorkerRunInfoUID: uid, ProjectToken: run.projectToken, LogEncryptionKey: logKey}
 
 			unCancelledContext := context.Background()
 			authcreds, authErr := auth.GetAuthCredsFromMd(ctx)
@@ -1061,7 +1227,14 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 
 			var executionInfo *api.ExecutionInfo
 			copiedRequests = append(copiedRequests, copiedRequest)
-			executionInfos, err := connectToServer(ctx, files, copiedRequests, copiedBuildCmds, totalTestCount, &cs, bufferStream, worker, config)
+			executionInfos, err := runWithRetries(ctx, files, copiedRequests, copiedBuildCmds, totalTestCount, &cs, bufferStream, worker, config, retriesFor(command, config), queue, pool)
+			if queue != nil {
+				files = queue.Assigned(worker.Uid)
+			}
+			if workerLost(ctx, err) {
+				reassigned = true
+				executionInfos, err = reassignLostWorker(ctx, worker, err, files, copiedRequest, totalTestCount, &cs, bufferStream, config, retriesFor(command, config), queue, pool)
+			}
 			if len(executionInfos) > 0 {
 				executionInfo = executionInfos[len(executionInfos)-1]
 			} else {
@@ -1082,9 +1255,11 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 				}
 			}
 			// we pass the logger cause we still want to associate logs with this context trace key etc
-			logLocation, logErr := sendBufferToS3(unCancelledContext, buffer, streamInfo, Logger(ctx))
+			logLocation, logErr := storeLog(unCancelledContext, buffer, streamInfo, Logger(ctx))
 			if logErr != nil {
-				Logger(ctx).Errorf("Error sending buffer to s3 %v", logErr)
+				Logger(ctx).Errorf("Error storing the worker log %v", logErr)
+			} else {
+				run.addWorkerLog(unCancelledContext, WorkerLog{Uid: uid, Worker: int32(i), CommandId: command.CommandId}, logKey, Logger(ctx))
 			}
 
 			end_run := timestamppb.Now()
@@ -1106,14 +1281,24 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 			ri := api.RunInfo{JobrunId: uint32(jobrunId), RebuildHash: executionInfo.RebuildHash, LogLocation: logLocation, Uid: uid, Files: files, WorkerId: uint32(worker.Id), ExitCode: strconv.Itoa(int(executionInfo.ExitCode)), Error: errorString, StartedAt: executionInfo.Started, FinishedAt: executionInfo.Finished, ExecutionInfos: executionInfos}
 
 			Logger(ctx).Debugf("The run info is %+v", ri)
+			// the key is recorded with the run after it is logged so it doesn't end up in our logs
+			ri.LogEncryptionKey = streamInfo.LogEncryptionKey
 
 			logRunErr := LogRun(unCancelledContext, &ri, Logger(ctx), &command)
 			if logRunErr != nil {
-				Logger(ctx).Errorf("Error logging run %v - run info is %+v", logRunErr, ri)
+				Logger(ctx).Errorf("Error logging run %v - run is %v", logRunErr, ri.Uid)
 				responseStream <- &pb.Output{Stderr: logRunErr.Error(), Created: timestamppb.Now()}
 			}
 			defer cancelUnCancel()
 
+			if err == nil {
+				history := getSplitHistory(ctx)
+				history.RecordRun(&ri, cs.testResults(), os.Getenv("REMOTE_DIR"))
+				if saveErr := history.Save(ctx); saveErr != nil {
+					Logger(ctx).Errorf("Error saving split history %v", saveErr)
+				}
+			}
+
 			if err != nil {
 				Logger(ctx).Error("Error at end of loop: ", err.Error())
 				responseStream <- &pb.Output{Response: err.Error(), Stderr: err.Error(), Exitcode: executionInfo.ExitCode, Created: timestamppb.Now()}
@@ -1155,6 +1340,15 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 		return false, e
 	case <-doneChannel:
 		Logger(ctx).Debugf("Got done channel")
+		if len(cs.FlakyTests) > 0 {
+			responseStream <- &pb.Output{Response: fmt.Sprintf("%v flaky tests passed only on retry: %v", len(cs.FlakyTests), strings.Join(cs.FlakyTests, ", ")), Created: timestamppb.Now()}
+		}
+		if coverage := cs.mergedCoverage(); coverage != nil {
+			responseStream <- &pb.Output{Response: "Coverage of all workers - " + coverage.Totals().String(), Command: &command, Created: timestamppb.Now()}
+			for _, chunk := range CoverageToProto(coverage, CoverageChunkSize) {
+				responseStream <- &pb.Output{Coverage: chunk, Command: &command, Created: timestamppb.Now()}
+			}
+		}
 		if exitCode == 0 {
 			jrunStatus = api.JobRunStatus_completed
 
@@ -1162,6 +1356,10 @@ func runTestTheTests(ctx context.Context, responseStream chan *pb.Output, buildC
 			jrunStatus = api.JobRunStatus_failed
 		}
 		Logger(ctx).Debugf("Got all done")
+		if exitCode != 0 {
+			// when failing fast the error has already gone to the exit channel, otherwise the commands that depend on this one need to know it failed
+			return false, TestFailedError
+		}
 		return false, nil
 
 	}
@@ -1321,11 +1519,51 @@ func createConnectionToWorkerNoRetry(ctx context.Context, worker *api.Worker, co
 // we respond with the final exit code and how long the final command took
 // we are ignoring build commands for that calculcation
 func connectToServer(ctx context.Context, files []string, requests []api.Command, buildCommands []api.Command, totalFileCount int, cs *countStruct, responseStream chan *pb.Output, worker api.Worker, config *brisksupervisor.Config) ([]*api.ExecutionInfo, error) {
+	executionInfos, err := connectToServerWithBatches(ctx, files, requests, buildCommands, totalFileCount, cs, responseStream, worker, config, nil)
+	if errors.Is(err, workerLostError) && len(executionInfos) == 1 && executionInfos[0].ExitCode == 50 {
+		// test runs hand the files to another worker instead, everyone else needs to stop waiting for output
+		responseStream <- &pb.Output{Control: types.FAILED, BriskError: &pb.BriskError{Error: "internal error - could not connect to workers"}, Created: timestamppb.Now()}
+	}
+	return executionInfos, err
+}
+
+// the worker times out each command itself, we wait a little longer so its error gets back to us first
+const workerTimeoutGrace = constants.COMMAND_TIMEOUT - constants.RUNNER_COMMAND_TIMEOUT
+
+// superCommandTimeout is how long we wait to hear from the worker while it runs command
+func superCommandTimeout(command *api.Command) time.Duration {
+	return max(viper.GetDuration("COMMAND_TIMEOUT"), CommandTimeout(command)+workerTimeoutGrace, ReadyTimeout(command)+workerTimeoutGrace)
+}
+
+// sessionTimeout is how long a worker has to run all of the commands, the commands with their own timeout add to it
+func sessionTimeout(commandLists ...[]api.Command) time.Duration {
+	timeout := viper.GetDuration("COMMAND_TIMEOUT")
+	for _, commands := range commandLists {
+		for i := range commands {
+			if commands[i].Timeout != nil && !commands[i].Background {
+				timeout += CommandTimeout(&commands[i])
+			}
+			timeout += ReadyTimeout(&commands[i])
+		}
+	}
+	return timeout
+}
+
+// connectToServerWithBatches runs the commands on the worker, if queue is set the test files are pulled from it
+// a batch at a time and the test command is re-sent on the same stream for each batch until the queue is empty
+func connectToServerWithBatches(ctx context.Context, files []string, requests []api.Command, buildCommands []api.Command, totalFileCount int, cs *countStruct, responseStream chan *pb.Output, worker api.Worker, config *brisksupervisor.Config, queue *batchQueue) ([]*api.ExecutionInfo, error) {
 
 	ctx, span := otel.Tracer(name).Start(ctx, "connectToServer(worker)")
 	defer span.End()
 
-	ctx, cancel := context.WithTimeout(ctx, viper.GetDuration("COMMAND_TIMEOUT"))
+	timeout := sessionTimeout(buildCommands, requests)
+	if queue != nil && len(requests) > 0 {
+		// the test command is sent again for each batch the worker pulls
+		timeout += time.Duration(max(queue.Batches()-1, 0)) * superCommandTimeout(&requests[0])
+	}
+	// the lock is held until this session has had its time as well
+	heldLock.extend(timeout + workerTimeoutGrace)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer CancelCtx(ctx, cancel, "connectToServer has finished so we are cancelling the context")
 
 	// Set up a connection to the server.
@@ -1339,6 +1577,14 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 	ctx = AddIntendedAllocIdTo(ctx, worker.Uid)
 	size := 1024 * 1024 * 1024 * 4
 
+	if queue != nil {
+		files = queue.Next(worker.Uid)
+		if len(files) == 0 {
+			Logger(ctx).Debugf("No batches left in the queue for worker %v", worker.Uid)
+			return []*api.ExecutionInfo{}, nil
+		}
+	}
+
 	Logger(ctx).Debugf("the files we have are of length %v", len(files))
 	Logger(ctx).Debugf("Connecting to endpoint %v", endpoint)
 
@@ -1398,9 +1644,8 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 	}
 	if err != nil {
 		Logger(ctx).Errorf("Error from DialContext when trying to connect to worker err: (%+v) worker: (%+v) time getting connection took %v", err, worker, time.Since(startConn))
-		responseStream <- &pb.Output{Control: types.FAILED, BriskError: &pb.BriskError{Error: "internal error - could not connect to workers"}, Created: timestamppb.Now()}
 
-		return []*api.ExecutionInfo{{ExitCode: 50, Output: "internal error - could not connect to worker"}}, err
+		return []*api.ExecutionInfo{{ExitCode: 50, Output: "internal error - could not connect to worker"}}, errors.Errorf("%w: %v", workerLostError, err)
 	}
 	if conn == nil {
 		Logger(ctx).Error("conn is nil - this should not happen")
@@ -1434,6 +1679,8 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 		Logger(ctx).Debug("No commands to run!")
 		return []*api.ExecutionInfo{{ExitCode: 11, Output: "No commands"}}, errors.New("no commands to run")
 	}
+	// keep the test command without any files so we can send it again for each batch
+	batchArgs := append([]string{}, requests[0].Args...)
 	// we add the filenames to the first of the requests, then we do it after the builds
 	requests[0].Args = append(requests[0].Args, filenames...)
 	if !requests[0].IsListTest {
@@ -1456,7 +1703,8 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 	// this is where we keep all the execution infos
 	allExecutionInfo := []*api.ExecutionInfo{}
 
-	for i, command := range requests {
+	for i := 0; i < len(requests); i++ {
+		command := requests[i]
 		//exitCode is confusing here - we are setting it before in this loop cause we want it to have a value of -1
 		// this 0 can come from build commands being run, thats what is happening here - if build commands were run set it
 		if exitCode == 0 {
@@ -1465,6 +1713,8 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 				Logger(ctx).Debugf("Setting the build commands run at to %v for %v", startTime, worker.Uid)
 				go setBuildCommandsRunAt(ctx, worker)
 				Logger(ctx).Debug("Not waiting for build commands set to run")
+				// so we only record it once when running batches
+				worker.BuildCommandsRunAt = timestamppb.Now()
 			}
 		} else {
 			Logger(ctx).Debugf("Exit code is %v - not setting build commands for worker %v", exitCode, worker.Uid)
@@ -1477,7 +1727,8 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 		Logger(ctx).Debugf("The command I'm sending is %+v", command)
 		responseStream <- &pb.Output{Stdout: fmt.Sprintf("Sending command %+v", command), Response: fmt.Sprintf("Sending command %v", command.Commandline), Created: timestamppb.Now()}
 
-		if i == len(requests)-1 {
+		// when running batches we don't know which is the last command, we close the stream once the queue is empty
+		if i == len(requests)-1 && queue == nil {
 			command.LastCommand = true
 		}
 		Logger(ctx).Infof("Sending command line %v with NoTestFiles %v and CommandConcurrency %v to worker %v", command.Commandline, command.NoTestFiles, command.CommandConcurrency, worker.Uid)
@@ -1492,9 +1743,9 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 		for {
 
 			select {
-			case <-time.After(viper.GetDuration("COMMAND_TIMEOUT")):
-				Logger(ctx).Errorf("Timed out after %v", viper.GetDuration("COMMAND_TIMEOUT"))
-				return append(allExecutionInfo, &api.ExecutionInfo{ExitCode: 13, Output: fmt.Sprintf("Timed out after %v", viper.GetDuration("COMMAND_TIMEOUT"))}), errors.New("command timedout")
+			case <-time.After(superCommandTimeout(&command)):
+				Logger(ctx).Errorf("Timed out after %v", superCommandTimeout(&command))
+				return append(allExecutionInfo, &api.ExecutionInfo{ExitCode: 13, Output: fmt.Sprintf("Timed out after %v", superCommandTimeout(&command))}), commandTimedOutError
 			case <-ctx.Done():
 				Logger(ctx).Info("Context done - closing the connection cause is %v", context.Cause(ctx))
 				r.CloseSend()
@@ -1574,12 +1825,7 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 				Logger(ctx).Debug(in)
 				Logger(ctx).Debug("trying to send output to channel")
 				if cs != nil {
-					updateCounts(ctx, in, cs, types.Framework(config.Framework))
-					in.TotalTestFail = int32(cs.FailCount)
-					in.TotalTestPass = int32(cs.PassCount)
-					in.TotalTestSkip = int32(cs.SkipCount)
-
-					in.TotalTestCount = int32(cs.TotalTestCount)
+					countOutput(ctx, in, cs, config.Framework)
 				}
 				responseStream <- in
 				Logger(ctx).Debug("sent output to channel")
@@ -1592,12 +1838,28 @@ func connectToServer(ctx context.Context, files []string, requests []api.Command
 					// I think we should check shit
 					Logger(ctx).Debug("Breaking out because we are finished")
 
+					if queue != nil && command.IsTestRun {
+						queue.Done(worker.Uid)
+						if next := queue.Next(worker.Uid); len(next) > 0 {
+							Logger(ctx).Debugf("Worker %v pulled the next batch of %v files, %v files left in the queue", worker.Uid, len(next), queue.Remaining())
+							nextCommand := command
+							nextCommand.Args = append(append([]string{}, batchArgs...), next...)
+							nextCommand.LastCommand = false
+							requests = append(requests, nextCommand)
+						}
+					}
+
 					break CommandFor
 				}
 				if in.Control == types.FAILED {
 					Logger(ctx).Debug("Got failed from server")
 					Logger(ctx).Debugf("Exit code is %v", in.Exitcode)
-					return allExecutionInfo, errors.Errorf("failed with exit code %v", in.Exitcode)
+					if queue != nil && command.IsTestRun {
+						// the batch has run, its failures are retried from the files assigned to this worker so it
+						// mustn't go back on the queue if the worker is lost later
+						queue.Done(worker.Uid)
+					}
+					return allExecutionInfo, errors.Errorf("%w with exit code %v", CommandFailedError, in.Exitcode)
 
 				}
 
@@ -1626,36 +1888,37 @@ func readToChannel(ctx context.Context, r pb.CommandRunner_RunCommandsClient, re
 	}
 }
 
-func sendBufferToS3(ctx context.Context, buffer *bytes.Buffer, streamInfo types.LogStreamInfo, logger *BriskLogger) (string, error) {
-	if IsDev() {
-		Logger(ctx).Debug("DEV mode so not sending to s3")
-	} else {
-		logger.Debug("sendBufferToS3")
-		// send the buffer to s3
-		logger.Debugf("Uploading logs to s3 we have %v bytes", buffer.Len())
-		var ls *log_service.LogService
-		if streamInfo.LogUid != "" {
-			ls = log_service.New(streamInfo.ProjectToken, streamInfo.LogUid)
-		} else {
-			ls = log_service.New(streamInfo.ProjectToken, streamInfo.WorkerRunInfoUID)
-		}
-		location, err := ls.UploadContent(ctx, io.Reader(buffer), logger)
-		if err != nil {
-			logger.Errorf("Error uploading logs to s3 %v", err)
-			return "", err
-		}
-		logger.Debugf("Uploaded logs to %v", location)
-		return location, nil
+// storeLog keeps the output of a run or worker run in the log store, it gives where it went
+func storeLog(ctx context.Context, buffer *bytes.Buffer, streamInfo types.LogStreamInfo, logger *BriskLogger) (string, error) {
+	logger.Debugf("Storing logs we have %v bytes", buffer.Len())
+	uid := streamInfo.LogUid
+	if uid == "" {
+		uid = streamInfo.WorkerRunInfoUID
+	}
+	// logs are only ever stored encrypted
+	if streamInfo.LogEncryptionKey == "" {
+		err := errors.Errorf("no key to encrypt the log %v with", uid)
+		logger.Errorf("Error storing logs %v", err)
+		return "", err
 	}
-	return "", nil
+	location, err := log_service.NewEncrypted(logStore, streamInfo.ProjectToken, uid, streamInfo.LogEncryptionKey).UploadContent(ctx, io.Reader(buffer), logger)
+	if err != nil {
+		logger.Errorf("Error storing logs %v", err)
+		return "", err
+	}
+	logger.Debugf("Stored logs at %v", location)
+	return location, nil
 }
 
 type countStruct struct {
 	TotalTestCount int
-	FailCount      int
-	PassCount      int
-	SkipCount      int
-	mu             sync.Mutex
+	TestCounts
+	FlakyTests []string
+	// results holds the latest result of each test so a retry replaces the earlier attempt
+	results map[string]TestResult
+	// coverage is merged from every worker as they finish
+	coverage *CoverageReport
+	mu       sync.Mutex
 }
 
 func setBuildCommandsRunAt(ctx context.Context, worker api.Worker) {
@@ -1710,57 +1973,66 @@ func setBuildCommandsRunAt(ctx context.Context, worker api.Worker) {
 	}
 
 }
-func updateCountsJest(ctx context.Context, out *pb.Output, cs *countStruct) {
-	Logger(ctx).Debugf("Using the string %v to check for Pass Fail", out.Stderr)
-	switch res := out.Stderr; {
-	case strings.Contains(res, "PASS"):
-		Logger(ctx).Debug("Looks like we have recognized a PASS")
-		cs.PassCount = cs.PassCount + 1
-	case strings.Contains(res, "skipped test"):
-		Logger(ctx).Debug("Looks like we have recognized a Skip")
-		cs.PassCount = cs.SkipCount + 1
-	case strings.Contains(res, "FAIL"):
-		Logger(ctx).Debug("Looks like we have recognized a FAIL")
 
-		cs.FailCount = cs.FailCount + 1
-	}
+// countOutput adds the results of the output to the counts and puts the totals so far on it
+func countOutput(ctx context.Context, out *pb.Output, cs *countStruct, framework string) {
+	updateCounts(ctx, out, cs, framework)
+	out.TotalTestFail = int32(cs.FailCount)
+	out.TotalTestPass = int32(cs.PassCount)
+	out.TotalTestSkip = int32(cs.SkipCount)
 
+	out.TotalTestCount = int32(cs.TotalTestCount)
+	out.TotalTestFlaky = int32(len(cs.FlakyTests))
 }
 
-// figure out exactly what an rspec output says
-func updateCountsRspec(ctx context.Context, out *pb.Output, cs *countStruct) error {
-	if out.JsonResults != "" {
-		Logger(ctx).Debug("waiting on lock")
-		cs.mu.Lock()
-		Logger(ctx).Debug("acquired lock")
+func updateCounts(ctx context.Context, out *pb.Output, cs *countStruct, framework string) {
+	testFramework, err := GetFramework(framework)
+	if err != nil {
+		Logger(ctx).Errorf("Can't count the results %v", err)
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err := testFramework.UpdateCounts(ctx, out, &cs.TestCounts); err != nil {
+		Logger(ctx).Errorf("Error updating the counts for %v %v", framework, err)
+	}
+	Logger(ctx).Debugf("updated countStruct is %+v", cs.TestCounts)
 
-		parsed, err := ParseRspecJsonResults(out.JsonResults)
-		if err != nil {
-			Logger(ctx).Errorf("Error parsing rspec json results %v", err)
-			return err
+	if len(out.TestResults) > 0 {
+		if cs.results == nil {
+			cs.results = map[string]TestResult{}
+		}
+		for _, r := range TestResultsFromProto(out.TestResults) {
+			cs.results[r.File+"\x00"+r.Name] = r
 		}
-		cs.FailCount += parsed.Summary.FailureCount
-		cs.SkipCount = parsed.Summary.PendingCount
-
-		cs.PassCount += (len(parsed.Examples) - parsed.Summary.FailureCount) //- parsed.Summary.PendingCount)
-		Logger(ctx).Debugf("The summary is %v", parsed.SummaryLine)
-		cs.mu.Unlock()
-	} else {
-		Logger(ctx).Debug("updateCountsRspec no JSON results!")
 	}
-	return nil
+	if out.Coverage != nil {
+		if cs.coverage == nil {
+			cs.coverage = NewCoverageReport()
+		}
+		cs.coverage.Merge(CoverageFromProto(out.Coverage))
+		// the CLI gets the coverage of all of the workers at the end instead
+		out.Coverage = nil
+	}
+
 }
-func updateCounts(ctx context.Context, out *pb.Output, cs *countStruct, testFramework types.Framework) {
 
-	if testFramework == types.Jest {
-		updateCountsJest(ctx, out, cs)
-	} else if testFramework == types.Cypress {
-		Logger(ctx).Debug("No countrstruct for cypress")
-	} else {
-		updateCountsRspec(ctx, out, cs)
-	}
-	Logger(ctx).Debugf("updated countStruct is %+v", cs)
+// mergedCoverage is the coverage of every worker so far, nil when the command doesn't have a coverageFile
+func (cs *countStruct) mergedCoverage() *CoverageReport {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.coverage
+}
 
+// testResults are the results of every test in the run so far
+func (cs *countStruct) testResults() []TestResult {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	results := make([]TestResult, 0, len(cs.results))
+	for _, r := range cs.results {
+		results = append(results, r)
+	}
+	return results
 }
 
 func MapWorkers(vs []*api.Worker, f func(*api.Worker) string) []string {
@@ -1771,195 +2043,30 @@ func MapWorkers(vs []*api.Worker, f func(*api.Worker) string) []string {
 	return vsm
 }
 
-func SplitFilesByRaw(ctx context.Context, worker *api.Worker, remote_dir string, numServers int, responseStream chan *pb.Output, config *brisksupervisor.Config) ([][]string, int, error) {
-
-	num_buckets := numServers
-
-	info, err := getAllFilesRawRemote(ctx, worker, remote_dir, responseStream, config)
-
-	if err != nil {
-		Logger(ctx).Errorf("Error getting all files %v", err)
-		return nil, 0, err
-	}
-	Logger(ctx).Debugf("Got all files %v", info)
-	files := info.Filenames
-	result := make([][]string, num_buckets)
-	for i := 0; i < num_buckets; i++ {
-		for j := i; j < len(files); j = j + num_buckets {
-			localPath := files[j]
-			remotePath := strings.Replace(localPath, remote_dir, "", 1)
-			result[i] = append(result[i], string(remotePath))
-		}
-	}
-	return result, len(result), nil
-}
-
-func splitForRaw(ctx context.Context, jsonString string) (PreTestInfo, error) {
-	Logger(ctx).Debug("splitForRaw++")
-	Logger(ctx).Debugf("the json string is %v", jsonString)
-	var result JestFiles
-
-	err := json.Unmarshal([]byte(jsonString), &result.Files)
-	if err != nil {
-		Logger(ctx).Debugf("Error parsing json %v", err)
-		return PreTestInfo{}, fmt.Errorf("error parsing json: %v", err)
-	}
-	var fileNames []string
-	Logger(ctx).Debugf("the result.Examples length is %v", len(result.Files))
-	for i := 0; i < len(result.Files); i++ {
-		fileNames = append(fileNames, result.Files[i])
-
-	}
-
-	Logger(ctx).Debug("splitForJest--")
-	return PreTestInfo{Filenames: RemoveDuplicatesFromSlice(ctx, fileNames), TotalTestCount: len(result.Files), TotalSkipCount: 0}, nil
-}
-
-func getAllFilesRawRemote(ctx context.Context, worker *api.Worker, workDir string, responseStream chan *pb.Output, config *brisksupervisor.Config) (PreTestInfo, error) {
-	Logger(ctx).Debugf("getAllFilesRawRemote with worker %v", worker.Id)
-
-	var buildCmds []api.Command
-	for _, c := range config.BuildCommands {
-		copy := *c
-		copy.Environment = config.Environment
-		copy.Stage = "Build"
-
-		buildCmds = append(buildCmds, copy)
-	}
-	commands := []api.Command{{Commandline: config.ListTestCommand, WorkDirectory: workDir, IsListTest: true, IsTestRun: false, Environment: config.Environment, TestFramework: config.Framework}}
-
-	editedStream := make(chan *pb.Output, 100)
-	ctx, cancel := context.WithCancelCause(ctx)
-	go func() {
-
-		buildCmds, commands = AddDefaultRemoteDirectorybriskSupervisor(ctx, buildCmds, commands)
-
-		executionInfos, err := connectToServer(ctx, nil, commands, buildCmds, 1, nil, editedStream, *worker, config)
-		if err != nil {
-			Logger(ctx).Error(err.Error())
-			responseStream <- &pb.Output{Response: err.Error(), Stderr: err.Error(), Created: timestamppb.Now()}
-			cancel(fmt.Errorf("error from connectToServer: %v", err))
-		}
-		executionInfo := executionInfos[len(executionInfos)-1]
-
-		Logger(ctx).Debugf("exitCode is %v", executionInfo.ExitCode)
-	}()
-
-	for out := range editedStream {
-		if out.Control == types.FINISHED {
-			Logger(ctx).Debugf("Received %+v from worker", out)
-			Logger(ctx).Infof("Received control from connectToServer %v for cmd %v", out.Control, out.CmdSqNum)
-			Logger(ctx).Info(out.JsonResults)
-			if out.Exitcode != 0 {
-				Logger(ctx).Debugf("Error running command response is %+v", out)
-				return PreTestInfo{}, errors.Errorf("error running command exit code is %+v", out.Exitcode)
-			}
-			if len(out.JsonResults) > 0 {
-				Logger(ctx).Debugf("We have json  test results they are %v", out.JsonResults)
-				testInfo, parseErr := splitForRaw(ctx, out.JsonResults)
-				if parseErr != nil {
-					Logger(ctx).Debugf("Error parsing json for jest, %v", parseErr.Error())
-					return PreTestInfo{}, parseErr
-				}
-				return testInfo, nil
-			}
-		}
-
-		if out.Control == types.FAILED {
-			Logger(ctx).Debugf("Received %+v from worker", out)
-			Logger(ctx).Infof("Received control from connectToServer %v", out.Control)
-
-			responseStream <- out
-			// lets see if this helps the errors deliver
-
-			return PreTestInfo{}, errors.Errorf("error running splitting files exit code is %+v", out.Exitcode)
-
-		}
-		Logger(ctx).Debugf("in parsing stream - %v", out)
-
-		responseStream <- out
-
-	}
-	Logger(ctx).Debug("SHOULD NEVER GET HERE")
-	return PreTestInfo{}, nil
-
-}
+// the listing is memoized so we can skip it when the config says the files haven't changed
+var mPretestInfo PreTestInfo
+var mListed bool
+var lastListTestCommand string
 
-func GetAllFilesJestRemote(ctx context.Context, worker *api.Worker, workDir string, responseStream chan *pb.Output, config *brisksupervisor.Config) (PreTestInfo, error) {
-	Logger(ctx).Debug("GetAllFilesJest")
-	Logger(ctx).Debugf("Config passed to GetAllFilesJestRemote is : %+v", config)
-	//TODO lets make this work right - remove these and get them from the config file
-	var buildCmds []api.Command
-	for _, c := range config.BuildCommands {
-		copy := *c
-		copy.Environment = config.Environment
-		copy.Stage = "Build"
+// GetAllFilesRemote runs the framework's list command on the worker and parses the test files out of the results
+func GetAllFilesRemote(ctx context.Context, worker *api.Worker, workDir string, responseStream chan *pb.Output, config *brisksupervisor.Config, command *api.Command, framework TestFramework, reCalcFiles bool) (PreTestInfo, error) {
+	defer bugsnag.AutoNotify(ctx)
+	Logger(ctx).Debugf("GetAllFilesRemote for %v with worker %v", framework.Name(), worker.Id)
 
-		buildCmds = append(buildCmds, copy)
+	listCommand, ok := framework.ListCommand(config, command, workDir)
+	if !ok {
+		return PreTestInfo{}, errors.Errorf("%v can't list its tests", framework.Name())
 	}
-	commands := []api.Command{{Commandline: config.ListTestCommand, WorkDirectory: workDir, IsListTest: true, IsTestRun: false, Environment: config.Environment, TestFramework: config.Framework}}
-
-	editedStream := make(chan *pb.Output, 100)
-	ctx, cancel := context.WithCancelCause(ctx)
-	go func() {
-		Logger(ctx).Debugf("in GetAllFilesJestRemote before addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
-
-		buildCmds, commands = AddDefaultRemoteDirectorybriskSupervisor(ctx, buildCmds, commands)
-		Logger(ctx).Debugf("in GetAllFilesJestRemote after addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
-
-		Logger(ctx).Debugf("buildCmds in GetAllFilesJestRemote : %+v", buildCmds)
-		exitCode, err := connectToServer(ctx, nil, commands, buildCmds, 1, nil, editedStream, *worker, config)
-		if err != nil {
-			Logger(ctx).Error(err.Error())
-			responseStream <- &pb.Output{Response: err.Error(), Stderr: err.Error(), Created: timestamppb.Now()}
-			cancel(fmt.Errorf("error from connectToServer: %v", err))
-		}
-		Logger(ctx).Debugf("exitCode is %v", exitCode)
-	}()
-
-	for out := range editedStream {
-		if out.Control == types.FINISHED {
-			Logger(ctx).Debugf("Received %+v from worker", out)
-			Logger(ctx).Infof("Received control from connectToServer %v for cmd %v", out.Control, out.CmdSqNum)
-			Logger(ctx).Infof("Json results from the command are %v", out.JsonResults)
-			if out.Exitcode != 0 {
-				Logger(ctx).Debugf("Error running command response is %+v", out)
-				return PreTestInfo{}, errors.Errorf("error running command exit code is %+v", out.Exitcode)
-			}
-			if len(out.JsonResults) > 0 {
-				Logger(ctx).Debugf("We have json  test results they are %v", out.JsonResults)
-				testInfo, parseErr := SplitForJest(ctx, out.JsonResults)
-				if parseErr != nil {
-					Logger(ctx).Errorf("Error parsing json for jest, %v", parseErr.Error())
-					return PreTestInfo{}, parseErr
-				}
-				return testInfo, nil
-			}
-		}
-
-		if out.Control == types.FAILED {
-			Logger(ctx).Debugf("Received %+v from worker", out)
-			Logger(ctx).Infof("Received control from connectToServer %v", out.Control)
-
-			responseStream <- out
-			// lets see if this helps the errors deliver
-
-			return PreTestInfo{}, errors.Errorf("error running splitting files exit code is %+v", out.Exitcode)
-
-		}
-		Logger(ctx).Debugf("in parsing stream - %v", out)
-
-		responseStream <- out
 
+	memoKey := string(framework.Name()) + " " + listCommand.Commandline
+	if mListed && !reCalcFiles && memoKey == lastListTestCommand {
+		Logger(ctx).Debugf("Using the files from the last listing of %v", memoKey)
+		return mPretestInfo, nil
 	}
-	Logger(ctx).Debug("SHOULD NEVER GET HERE")
-	return PreTestInfo{}, nil
+	lastListTestCommand = memoKey
+	mListed = false
+	mPretestInfo = PreTestInfo{}
 
-}
-
-func GetAllFilesRspecRemote(ctx context.Context, worker *api.Worker, workDir string, responseStream chan *pb.Output, config *brisksupervisor.Config) (PreTestInfo, error) {
-	defer bugsnag.AutoNotify(ctx)
-	Logger(ctx).Debug("GetAllFilesRspec")
 	//TODO lets make this work right - remove these and get them from the config file
 	var buildCmds []api.Command
 	for _, c := range config.BuildCommands {
@@ -1969,36 +2076,31 @@ func GetAllFilesRspecRemote(ctx context.Context, worker *api.Worker, workDir str
 
 		buildCmds = append(buildCmds, copy)
 	}
-	if len(config.ListTestCommand) == 0 {
-		config.ListTestCommand = os.Getenv("RSPEC_LIST_TEST_CMD")
-	}
 
-	Logger(ctx).Debugf("The list test command is %v", config.ListTestCommand)
+	Logger(ctx).Debugf("The list test command is %v", listCommand.Commandline)
 	Logger(ctx).Debugf("The environment passed in is  %v", config.Environment)
 	Logger(ctx).Debugf("The config passed in is %+v", config)
-	// setting  IsTestRun to true because we want to run with a file output to get our json back
-	commands := []api.Command{{Commandline: config.ListTestCommand, WorkDirectory: workDir, IsTestRun: true, Environment: config.Environment, TestFramework: config.Framework}}
+	commands := []api.Command{listCommand}
 
 	editedStream := make(chan *pb.Output, 100)
 
 	ctx, cancel := context.WithCancelCause(ctx)
-	Logger(ctx).Debug("in GetAllFilesRspecRemote before gofunc connecting to server")
 	go func() {
 		defer bugsnag.AutoNotify(ctx)
-		Logger(ctx).Debugf("in GetAllFilesRspecRemote before addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
+		Logger(ctx).Debugf("in GetAllFilesRemote before addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
 		buildCmds, commands = AddDefaultRemoteDirectorybriskSupervisor(ctx, buildCmds, commands)
-		Logger(ctx).Debugf("in GetAllFilesRspecRemote after addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
+		Logger(ctx).Debugf("in GetAllFilesRemote after addDefaultRemoteDirectorybriskSupervisor buildCmds: %+v  and commands %+v", buildCmds, commands)
 		executionInfos, err := connectToServer(ctx, nil, commands, buildCmds, 1, nil, editedStream, *worker, config)
 
-		Logger(ctx).Debug("in GetAllFilesRspecRemote after connectToServer")
+		Logger(ctx).Debug("in GetAllFilesRemote after connectToServer")
 		if err != nil {
 			Logger(ctx).Error(err.Error())
 			responseStream <- &pb.Output{Response: err.Error(), Stderr: err.Error(), Created: timestamppb.Now()}
 			cancel(fmt.Errorf("error from connectToServer: %v", err))
 		}
-		executionInfo := executionInfos[len(executionInfos)-1]
-
-		Logger(ctx).Debugf("exitCode is %v", executionInfo.ExitCode)
+		if len(executionInfos) > 0 {
+			Logger(ctx).Debugf("exitCode is %v", executionInfos[len(executionInfos)-1].ExitCode)
+		}
 	}()
 
 	for out := range editedStream {
@@ -2012,11 +2114,13 @@ func GetAllFilesRspecRemote(ctx context.Context, worker *api.Worker, workDir str
 			}
 			if len(out.JsonResults) > 0 {
 				Logger(ctx).Debugf("We have json  test results they are %v", out.JsonResults)
-				testInfo, parseErr := SplitForRSpec(ctx, out.JsonResults)
+				testInfo, parseErr := framework.ParseListing(ctx, out.JsonResults)
 				if parseErr != nil {
-					Logger(ctx).Debugf("Error parsing json for rspec, %v", parseErr.Error())
+					Logger(ctx).Errorf("Error parsing json for %v, %v", framework.Name(), parseErr.Error())
 					return PreTestInfo{}, parseErr
 				}
+				mPretestInfo = testInfo
+				mListed = true
 				return testInfo, nil
 			}
 		}
@@ -2121,53 +2225,6 @@ func warmOtherWorkers(ctx context.Context, workers []api.Worker, config *brisksu
 
 }
 
-var mPretestInfo PreTestInfo
-var mFiles [][]string
-var lastListTestCommand string
-
-func SplitFilesByRspec(ctx context.Context, worker *api.Worker, workDir string, num_buckets int, responseStream chan *pb.Output, reCalcFiles bool, config *brisksupervisor.Config) ([][]string, PreTestInfo, error) {
-
-	//memoize
-	if mFiles != nil && !reCalcFiles && config.ListTestCommand == lastListTestCommand {
-		return mFiles, mPretestInfo, nil
-	} else {
-		lastListTestCommand = config.ListTestCommand
-		mFiles = nil
-		mPretestInfo = PreTestInfo{}
-	}
-	Logger(ctx).Debug("SplitFilesByRspec++")
-	Logger(ctx).Debugf("The params are %v | %v | %v", worker, workDir, num_buckets)
-	preTestInfo, err := GetAllFilesRspecRemote(ctx, worker, workDir, responseStream, config)
-
-	Logger(ctx).Debug("after GetAllFilesRspec")
-	if err != nil {
-		Logger(ctx).Debug(err)
-		return nil, PreTestInfo{}, errors.Errorf("Error trying to split tests - %v", err.Error())
-	}
-	files := preTestInfo.Filenames
-	Logger(ctx).Debugf("All the files from rspec are %v", files)
-	Logger(ctx).Debugf("The files length is %v", len(files))
-
-	result := make([][]string, num_buckets)
-	for i := 0; i < num_buckets; i++ {
-		for j := i; j < len(files); j = j + num_buckets {
-			localPath := files[j]
-			remotePath := strings.Replace(localPath, workDir, "", 1)
-			result[i] = append(result[i], string(remotePath))
-		}
-	}
-	for i := 0; i < num_buckets; i++ {
-		Logger(ctx).Debugf("the bucket full of files is %v", result[i])
-		for _, v := range result {
-			Logger(ctx).Debugf("The results have size %v", len(v))
-		}
-	}
-	Logger(ctx).Debug("SplitFilesByRspec--")
-	mFiles = result
-	mPretestInfo = preTestInfo
-	return result, preTestInfo, err
-}
-
 func getWorkingWorkers(ctx context.Context, numWorkers int, workerImage string, rebuildFilePaths []string, repoInfo api.RepoInfo, logUid string) ([]*api.Worker, int32, string, error) {
 
 	workers, jobrunId, jobrunLink, err := GetWorkersForProject(ctx, numWorkers, workerImage, rebuildFilePaths, repoInfo, 1, logUid)
@@ -2333,7 +2390,7 @@ func syncToWorkers(ctx context.Context, workers []*api.Worker, config *brisksupe
 
 }
 
-func splitTestFiles(ctx context.Context, numServers int, worker *api.Worker, config *brisksupervisor.Config, responseStream chan *pb.Output, reCalcFiles bool) ([][]string, int, error) {
+func splitTestFiles(ctx context.Context, numServers int, worker *api.Worker, config *brisksupervisor.Config, command *api.Command, responseStream chan *pb.Output, reCalcFiles bool) ([][]string, int, error) {
 	ctx, span := otel.Tracer(name).Start(ctx, "splitTestFiles")
 	defer span.End()
 	Logger(ctx).Debug("splitTestFiles++")
@@ -2341,88 +2398,89 @@ func splitTestFiles(ctx context.Context, numServers int, worker *api.Worker, con
 	var totalTestCount int
 	var err error
 
-	var preTestInfo PreTestInfo
-
 	var allFiles PreTestInfo
 	var errFiles error
-	switch config.Framework {
-	case string(types.Rspec):
-		responseStream <- &pb.Output{Response: "Rspec detected", Created: timestamppb.Now()}
-		Logger(ctx).Debug("Splitting by rspec")
-		myFiles, preTestInfo, err = SplitFilesByRspec(ctx, worker, os.Getenv("REMOTE_DIR"), numServers, responseStream, reCalcFiles, config)
-		Logger(ctx).Debug("Split by rspec results are %+v %+v %+v", myFiles, preTestInfo, err)
-
-		if err != nil {
-			// need to stream the info back instead of a one off command. makes more sense anyway
-			Logger(ctx).Errorf("received error from SplitFilesByRspec :- %v", err)
-
-			return nil, 0, err
-		}
-		totalTestCount = preTestInfo.TotalTestCount
-		Logger(ctx).Debugf("the total test count is %v", totalTestCount)
-		Logger(ctx).Debugf("myFiles are %v", myFiles)
-	case string(types.Python):
-		responseStream <- &pb.Output{Response: "Python detected", Created: timestamppb.Now()}
-		Logger(ctx).Debug("Splitting by python")
-		Logger(ctx).Debug("Nop pytest using pytest-split so nothing required")
-
-	case string(types.Raw):
-		responseStream <- &pb.Output{Response: "Raw detected", Created: timestamppb.Now()}
-		Logger(ctx).Debug("Splitting using provided list command : " + config.ListTestCommand)
 
-		myFiles, totalTestCount, err = SplitFilesByRaw(ctx, worker, os.Getenv("REMOTE_DIR"), numServers, responseStream, config)
+	framework, err := GetFramework(config.Framework)
+	if err != nil {
+		Logger(ctx).Errorf("Error splitting files %s", err)
+		return nil, -1, err
+	}
+	responseStream <- &pb.Output{Response: fmt.Sprintf("%v detected", framework.Name()), Created: timestamppb.Now()}
 
-		if err != nil {
+	if len(config.OnlyFiles) > 0 {
+		if err := CheckOnlyFiles(config.Framework, command); err != nil {
 			Logger(ctx).Errorf("Error splitting files %s", err)
 			return nil, -1, err
 		}
-
-	default:
-		responseStream <- &pb.Output{Response: "Default test splitter", Created: timestamppb.Now()}
-
-		if config.SplitByJUnit {
-			Logger(ctx).Debug("Splitting using provided files")
-			if len(config.OrderedFiles) < 1 {
-				errFiles = errors.New("Split by JUnit but no files provided")
-			} else {
-
-				allFiles = PreTestInfo{Filenames: config.OrderedFiles, TotalTestCount: len(config.OrderedFiles)}
-			}
-
+		// the cli has asked for a specific set of files (e.g. the ones that failed last time) so we don't need to list the suite
+		responseStream <- &pb.Output{Response: fmt.Sprintf("Running only %v requested test files", len(config.OnlyFiles)), Created: timestamppb.Now()}
+		Logger(ctx).Debugf("Splitting only the requested files %v", config.OnlyFiles)
+		allFiles = PreTestInfo{Filenames: config.OnlyFiles, TotalTestCount: len(config.OnlyFiles)}
+	} else if _, lists := framework.ListCommand(config, command, os.Getenv("REMOTE_DIR")); !lists {
+		Logger(ctx).Debugf("%v doesn't list its tests so there is nothing to split", framework.Name())
+		return nil, 0, nil
+	} else if config.SplitByJUnit {
+		Logger(ctx).Debug("Splitting using provided files")
+		if len(config.OrderedFiles) < 1 {
+			errFiles = errors.New("Split by JUnit but no files provided")
 		} else {
 
-			allFiles, errFiles = GetAllFilesJestRemote(ctx, worker, os.Getenv("REMOTE_DIR"), responseStream, config)
-
+			allFiles = PreTestInfo{Filenames: config.OrderedFiles, TotalTestCount: len(config.OrderedFiles)}
 		}
-		if errFiles != nil {
-			//need to parse different and use --outputFile=
-			Logger(ctx).Errorf("Error getting files %s", errFiles)
-			return nil, -1, errFiles
-		}
-		if config.AutomaticSplitting {
-			Logger(ctx).Debug("Splitting using automatic splitting")
-			totalTestCount = len(allFiles.Filenames)
-			shortNames := removeWorkDir(allFiles.Filenames, os.Getenv("REMOTE_DIR"))
-			var splitMethod string
-			myFiles, splitMethod, err = SplitTests(ctx, int32(numServers), shortNames)
-			for v := range myFiles {
-				Logger(ctx).Debugf("Bucket %v has %v files", v, len(myFiles[v]))
-			}
-			responseStream <- &pb.Output{Response: "Split method: " + splitMethod, Created: timestamppb.Now()}
 
-		} else {
-			Logger(ctx).Debug("Splitting using SplitFilesByJest")
-			myFiles, totalTestCount, err = SplitFilesByJest(ctx, os.Getenv("REMOTE_DIR"), numServers, responseStream, allFiles.Filenames)
+	} else {
+		Logger(ctx).Debug("Splitting using provided list command : " + config.ListTestCommand)
+		allFiles, errFiles = GetAllFilesRemote(ctx, worker, os.Getenv("REMOTE_DIR"), responseStream, config, command, framework, reCalcFiles)
+
+	}
+	if errFiles != nil {
+		Logger(ctx).Errorf("Error getting files %s", errFiles)
+		return nil, -1, errFiles
+	}
+	totalTestCount = allFiles.TotalTestCount
+	Logger(ctx).Debugf("the total test count is %v", totalTestCount)
+	if config.ExampleSplitThreshold > 0 && len(allFiles.ExampleIds) > 0 {
+		filenames := SplitSlowRspecFiles(ctx, allFiles.Filenames, allFiles.ExampleIds, time.Duration(config.ExampleSplitThreshold)*time.Second, getSplitHistory(ctx))
+		if len(filenames) > len(allFiles.Filenames) {
+			responseStream <- &pb.Output{Response: fmt.Sprintf("Splitting slow files by example - %v files became %v parts", len(allFiles.Filenames), len(filenames)), Created: timestamppb.Now()}
 		}
-		if err != nil {
-			Logger(ctx).Errorf("Error splitting files %s", err)
-			return nil, 0, err
+		allFiles.Filenames = filenames
+	}
+
+	if config.AutomaticSplitting || config.SplitAlgorithm != "" {
+		Logger(ctx).Debug("Splitting using automatic splitting")
+		shortNames := removeWorkDir(allFiles.Filenames, os.Getenv("REMOTE_DIR"))
+		var splitMethod string
+		myFiles, splitMethod, err = SplitTests(ctx, int32(numServers), shortNames, config.SplitAlgorithm, getSplitHistory(ctx))
+		for v := range myFiles {
+			Logger(ctx).Debugf("Bucket %v has %v files", v, len(myFiles[v]))
 		}
+		responseStream <- &pb.Output{Response: "Split method: " + splitMethod, Created: timestamppb.Now()}
+
+	} else {
+		Logger(ctx).Debug("Splitting using SplitFilesByJest")
+		myFiles, _, err = SplitFilesByJest(ctx, os.Getenv("REMOTE_DIR"), numServers, responseStream, allFiles.Filenames)
+	}
+	if err != nil {
+		Logger(ctx).Errorf("Error splitting files %s", err)
+		return nil, 0, err
 	}
+	Logger(ctx).Debugf("myFiles are %v", myFiles)
 
 	return myFiles, totalTestCount, nil
 }
 
+// frameworkListsTests is false for frameworks that split the tests of command themselves on each worker
+func frameworkListsTests(config *brisksupervisor.Config, command *api.Command) bool {
+	framework, err := GetFramework(config.Framework)
+	if err != nil {
+		return true
+	}
+	_, lists := framework.ListCommand(config, command, os.Getenv("REMOTE_DIR"))
+	return lists
+}
+
 func removeWorkDir(files []string, workDir string) []string {
 	var shortNames []string
 	for _, file := range files {
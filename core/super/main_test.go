@@ -19,20 +19,29 @@ import (
 	brisksupervisor "brisk-supervisor/brisk-supervisor"
 	pb "brisk-supervisor/brisk-supervisor"
 
+	. "brisk-supervisor/shared/logger"
+	"bytes"
 	"context"
 	_ "net/http/pprof"
+	"strings"
 	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func Test_runTestTheTests(t *testing.T) {
 	type args struct {
 		ctx            context.Context
-		responseStream chan pb.Output
+		responseStream chan *pb.Output
 		buildCommands  []*api.Command
-		commands       []*api.Command
+		command        api.Command
 		config         *brisksupervisor.Config
 		repoInfo       api.RepoInfo
-		logUid         string
+		run            *runLog
+		logKey         string
 	}
 	tests := []struct {
 		name    string
@@ -44,12 +53,13 @@ func Test_runTestTheTests(t *testing.T) {
 			name: "Test runTestTheTests",
 			args: args{
 				ctx:            (context.Background()),
-				responseStream: make(chan pb.Output),
+				responseStream: make(chan *pb.Output, 100),
 				buildCommands:  []*api.Command{},
-				commands:       []*api.Command{},
+				command:        api.Command{},
 				config:         &brisksupervisor.Config{},
 				repoInfo:       api.RepoInfo{},
-				logUid:         "test",
+				run:            &runLog{id: "test", changed: make(chan struct{})},
+				logKey:         "test",
 			},
 			want:    false,
 			wantErr: true,
@@ -58,12 +68,13 @@ func Test_runTestTheTests(t *testing.T) {
 			name: "Test runTestTheTests",
 			args: args{
 				ctx:            (context.Background()),
-				responseStream: make(chan pb.Output),
+				responseStream: make(chan *pb.Output, 100),
 				buildCommands:  []*api.Command{},
-				commands:       []*api.Command{{Commandline: "ls -al | wc -l"}, {Commandline: "ls -al"}},
+				command:        api.Command{Commandline: "ls -al | wc -l"},
 				config:         &brisksupervisor.Config{WorkerImage: "rails"},
 				repoInfo:       api.RepoInfo{},
-				logUid:         "test",
+				run:            &runLog{id: "test", changed: make(chan struct{})},
+				logKey:         "test",
 			},
 			want:    false,
 			wantErr: true,
@@ -71,7 +82,7 @@ func Test_runTestTheTests(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := runTestTheTests(tt.args.ctx, tt.args.responseStream, tt.args.buildCommands, tt.args.commands, tt.args.config, tt.args.repoInfo, tt.args.logUid)
+			got, err := runTestTheTests(tt.args.ctx, tt.args.responseStream, tt.args.buildCommands, tt.args.command, tt.args.config, tt.args.repoInfo, tt.args.run, tt.args.logKey)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("runTestTheTests() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -82,3 +93,61 @@ func Test_runTestTheTests(t *testing.T) {
 		})
 	}
 }
+
+func Test_forwardResponses(t *testing.T) {
+	key := "the-log-encryption-key"
+	responseStream := make(chan *pb.Output, 2)
+	responseStream <- &pb.Output{RunId: "run-id", LogEncryptionKey: key}
+	responseStream <- &pb.Output{Stdout: "a line of output"}
+	close(responseStream)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &BriskLogger{SugaredLogger: *zap.New(core).Sugar()}
+	var sent []*pb.Output
+	send := func(output *pb.Output) error {
+		sent = append(sent, output)
+		return nil
+	}
+	run := &runLog{id: "run-id", changed: make(chan struct{})}
+	if err := forwardResponses(responseStream, send, &bytes.Buffer{}, run, logger); err != nil {
+		t.Fatalf("forwardResponses() error = %v", err)
+	}
+
+	if len(sent) != 2 || sent[0].LogEncryptionKey != key {
+		t.Errorf("forwardResponses() sent %v, want both outputs with the key still in the first", sent)
+	}
+	if logs.Len() == 0 {
+		t.Fatal("forwardResponses() logged nothing")
+	}
+	for _, entry := range logs.All() {
+		if strings.Contains(entry.Message, key) {
+			t.Errorf("forwardResponses() logged the key: %v", entry.Message)
+		}
+	}
+}
+
+func Test_lockDeadline(t *testing.T) {
+	d := &lockDeadline{changed: make(chan struct{})}
+	d.reset(time.Minute)
+	first, changed := d.get()
+
+	d.extend(time.Second)
+	if at, _ := d.get(); !at.Equal(first) {
+		t.Errorf("extend() moved the deadline forward to %v from %v", at, first)
+	}
+	select {
+	case <-changed:
+		t.Error("extend() said the deadline changed when it didn't")
+	default:
+	}
+
+	d.extend(time.Hour)
+	if at, _ := d.get(); !at.After(first) {
+		t.Errorf("extend() kept the deadline at %v, want it past %v", at, first)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Error("extend() didn't say the deadline changed")
+	}
+}
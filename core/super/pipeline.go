@@ -0,0 +1,122 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	. "brisk-supervisor/shared/logger"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stageSkippedError is the result of a command that didn't run because a command it depends on failed
+var stageSkippedError = errors.New("skipped because a command it depends on failed")
+
+// commandPipeline holds back each command until the commands in its dependsOn have passed, when one fails the
+// commands downstream of it are skipped
+type commandPipeline struct {
+	graph          *CommandGraph
+	commands       []*api.Command
+	responseStream chan *pb.Output
+
+	mu      sync.Mutex
+	done    []chan struct{}
+	passed  []bool
+	skipped []bool
+}
+
+func newCommandPipeline(graph *CommandGraph, commands []*api.Command, responseStream chan *pb.Output) *commandPipeline {
+	p := &commandPipeline{graph: graph, commands: commands, responseStream: responseStream, done: make([]chan struct{}, len(commands)), passed: make([]bool, len(commands)), skipped: make([]bool, len(commands))}
+	for i := range p.done {
+		p.done[i] = make(chan struct{})
+	}
+	return p
+}
+
+// waitForDependencies blocks until the dependencies of command i have finished, it returns stageSkippedError when
+// the command shouldn't run because one of them didn't pass or the context's error when the run was cancelled
+func (p *commandPipeline) waitForDependencies(ctx context.Context, i int, command *api.Command) error {
+	for _, j := range p.graph.DependsOn(i) {
+		select {
+		case <-p.done[j]:
+		case <-ctx.Done():
+			close(p.done[i])
+			return ctx.Err()
+		}
+		p.mu.Lock()
+		passed := p.passed[j]
+		p.mu.Unlock()
+		if !passed {
+			Logger(ctx).Infof("Skipping command %v because %v did not pass", p.graph.Name(i), p.graph.Name(j))
+			p.mu.Lock()
+			p.skip(i, j)
+			p.mu.Unlock()
+			close(p.done[i])
+			return errors.Errorf("%v %w", p.graph.Name(i), stageSkippedError)
+		}
+	}
+	var after []string
+	for _, j := range p.graph.DependsOn(i) {
+		after = append(after, p.graph.Name(j))
+	}
+	if len(after) > 0 {
+		p.status(i, command, StageStarted, fmt.Sprintf("after %v passed", strings.Join(after, ", ")))
+	} else {
+		p.status(i, command, StageStarted, "")
+	}
+	return nil
+}
+
+// finished records how command i went and skips everything downstream of it when it didn't pass
+func (p *commandPipeline) finished(i int, command *api.Command, passed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.passed[i] = passed
+	if p.graph.HasDependencies() {
+		if passed {
+			p.status(i, command, StagePassed, "")
+		} else {
+			p.status(i, command, StageFailed, "")
+			for _, j := range p.graph.Downstream(i) {
+				p.skip(j, i)
+			}
+		}
+	}
+	close(p.done[i])
+}
+
+// skip tells the CLI command i won't run, only once even if more than one of its dependencies failed
+func (p *commandPipeline) skip(i int, failed int) {
+	if p.skipped[i] {
+		return
+	}
+	p.skipped[i] = true
+	p.status(i, p.commands[i], StageSkipped, fmt.Sprintf("because %v failed", p.graph.Name(failed)))
+}
+
+func (p *commandPipeline) status(i int, command *api.Command, status string, detail string) {
+	response := fmt.Sprintf("%v - %v %v", p.graph.StageName(i), p.graph.Name(i), status)
+	if detail != "" {
+		response = response + " " + detail
+	}
+	p.responseStream <- &pb.Output{Response: response, Command: command, Stage: p.graph.StageName(i), StageStatus: status, Created: timestamppb.Now()}
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	"context"
+	"testing"
+
+	"github.com/go-errors/errors"
+)
+
+func Test_commandPipeline(t *testing.T) {
+	// build -> unit -> e2e and lint on its own, unit also waits on lint
+	commands := []*api.Command{
+		{CommandId: "build"},
+		{CommandId: "lint"},
+		{CommandId: "unit", DependsOn: []string{"build", "lint"}},
+		{CommandId: "e2e", DependsOn: []string{"unit"}},
+	}
+	tests := []struct {
+		name       string
+		passed     map[string]bool
+		wantRan    map[string]bool
+		wantStatus map[string]string
+	}{
+		{
+			name:       "everything passes",
+			passed:     map[string]bool{"build": true, "lint": true, "unit": true, "e2e": true},
+			wantRan:    map[string]bool{"build": true, "lint": true, "unit": true, "e2e": true},
+			wantStatus: map[string]string{"build": StagePassed, "lint": StagePassed, "unit": StagePassed, "e2e": StagePassed},
+		},
+		{
+			name:       "a failed build skips everything downstream",
+			passed:     map[string]bool{"build": false, "lint": true},
+			wantRan:    map[string]bool{"build": true, "lint": true},
+			wantStatus: map[string]string{"build": StageFailed, "lint": StagePassed, "unit": StageSkipped, "e2e": StageSkipped},
+		},
+		{
+			name:       "a command waits on all of its dependencies",
+			passed:     map[string]bool{"build": true, "lint": false},
+			wantRan:    map[string]bool{"build": true, "lint": true},
+			wantStatus: map[string]string{"build": StagePassed, "lint": StageFailed, "unit": StageSkipped, "e2e": StageSkipped},
+		},
+		{
+			name:       "a failure late in the pipeline only skips what follows it",
+			passed:     map[string]bool{"build": true, "lint": true, "unit": false},
+			wantRan:    map[string]bool{"build": true, "lint": true, "unit": true},
+			wantStatus: map[string]string{"build": StagePassed, "lint": StagePassed, "unit": StageFailed, "e2e": StageSkipped},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := NewCommandGraph(commands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			responseStream := make(chan *pb.Output, 100)
+			pipeline := newCommandPipeline(graph, commands, responseStream)
+
+			results := make(chan error, len(commands))
+			ran := make(chan string, len(commands))
+			for i, c := range commands {
+				go func(i int, command *api.Command) {
+					if err := pipeline.waitForDependencies(context.Background(), i, command); err != nil {
+						results <- err
+						return
+					}
+					ran <- command.CommandId
+					pipeline.finished(i, command, tt.passed[command.CommandId])
+					results <- nil
+				}(i, c)
+			}
+			skipped := 0
+			for range commands {
+				if err := <-results; err != nil {
+					if !errors.Is(err, stageSkippedError) {
+						t.Errorf("waitForDependencies() error = %v, want a skipped stage", err)
+					}
+					skipped++
+				}
+			}
+			close(ran)
+			close(responseStream)
+
+			gotRan := map[string]bool{}
+			for id := range ran {
+				gotRan[id] = true
+			}
+			if len(gotRan) != len(tt.wantRan) || len(commands)-skipped != len(tt.wantRan) {
+				t.Errorf("ran %v, want %v", gotRan, tt.wantRan)
+			}
+			for id := range tt.wantRan {
+				if !gotRan[id] {
+					t.Errorf("%v didn't run", id)
+				}
+			}
+			// the last status of each command, a skipped one is only reported once
+			gotStatus := map[string]string{}
+			skips := map[string]int{}
+			for output := range responseStream {
+				id := output.Command.CommandId
+				gotStatus[id] = output.StageStatus
+				if output.StageStatus == StageSkipped {
+					skips[id]++
+				}
+			}
+			for id, want := range tt.wantStatus {
+				if gotStatus[id] != want {
+					t.Errorf("%v ended %q, want %q", id, gotStatus[id], want)
+				}
+				if skips[id] > 1 {
+					t.Errorf("%v was reported skipped %v times", id, skips[id])
+				}
+			}
+		})
+	}
+}
+
+func Test_waitForDependenciesCancelled(t *testing.T) {
+	commands := []*api.Command{{CommandId: "build"}, {CommandId: "test", DependsOn: []string{"build"}}}
+	graph, err := NewCommandGraph(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := newCommandPipeline(graph, commands, make(chan *pb.Output, 10))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pipeline.waitForDependencies(ctx, 1, commands[1]); !errors.Is(err, context.Canceled) || errors.Is(err, stageSkippedError) {
+		t.Errorf("waitForDependencies() error = %v, want the run to be cancelled", err)
+	}
+}
+
+func Test_carriesOn(t *testing.T) {
+	skipped := errors.Errorf("%v %w", "e2e", stageSkippedError)
+	failed := errors.Errorf("%w with exit code %v", TestFailedError, 1)
+	tests := []struct {
+		name       string
+		noFailFast bool
+		err        error
+		want       bool
+	}{
+		{"failing fast stops at a failure", false, failed, false},
+		{"not failing fast waits for the rest", true, failed, true},
+		{"a skipped stage waits for the rest", true, skipped, true},
+		{"a worker error stops the run", true, workerLostError, false},
+	}
+	for _, tt := range tests {
+		if got := carriesOn(&pb.Config{NoFailFast: tt.noFailFast}, tt.err); got != tt.want {
+			t.Errorf("carriesOn(%v) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	"brisk-supervisor/shared/auth"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/mitchellh/copystructure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// workerLostError is returned when we can't connect to a worker at all
+var workerLostError = errors.New("lost connection to worker")
+
+var commandTimedOutError = errors.New("command timedout")
+
+// workerLost is true when the error means the worker has gone away rather than the tests failing
+// if the run itself has been cancelled nothing is lost, we are just shutting down
+// a command that times out is a failing test, moving it to another worker would only hang that one as well
+func workerLost(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, workerLostError) {
+		return true
+	}
+	return status.Code(err) == codes.Unavailable
+}
+
+// workerPool tracks which of the run's workers have finished so the files of a lost worker can go to the next free one
+type workerPool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	idle []api.Worker
+	// workers that are still running files and might become idle
+	busy int
+}
+
+func newWorkerPool(busy int) *workerPool {
+	p := &workerPool{busy: busy}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Release hands a healthy worker back to the pool
+func (p *workerPool) Release(worker api.Worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy--
+	p.idle = append(p.idle, worker)
+	p.cond.Broadcast()
+}
+
+// Lost takes a busy worker out of the pool for good
+func (p *workerPool) Lost() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy--
+	p.cond.Broadcast()
+}
+
+// TryAcquire takes an idle worker without waiting for one
+func (p *workerPool) TryAcquire() (api.Worker, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return api.Worker{}, false
+	}
+	worker := p.idle[0]
+	p.idle = p.idle[1:]
+	p.busy++
+	return worker, true
+}
+
+// Acquire waits for a worker to become idle, it returns false when no worker ever will
+func (p *workerPool) Acquire(ctx context.Context) (api.Worker, bool) {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.cond.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.idle) == 0 && p.busy > 0 && ctx.Err() == nil {
+		p.cond.Wait()
+	}
+	if len(p.idle) == 0 || ctx.Err() != nil {
+		return api.Worker{}, false
+	}
+	worker := p.idle[0]
+	p.idle = p.idle[1:]
+	p.busy++
+	return worker, true
+}
+
+// reassignLostWorker deregisters a worker we've lost mid run and re-runs its unfinished files on the free workers
+// if a replacement is lost as well we keep going until we run out of workers
+func reassignLostWorker(ctx context.Context, lost api.Worker, lostErr error, files []string, request api.Command, totalFileCount int, cs *countStruct, responseStream chan *pb.Output, config *pb.Config, retries int, queue *batchQueue, pool *workerPool) ([]*api.ExecutionInfo, error) {
+	Logger(ctx).Errorf("Lost worker %v with %v", lost.Uid, lostErr)
+	pool.Lost()
+	deregisterLostWorker(ctx, lost)
+
+	var unfinished []string
+	if queue != nil {
+		// batches that finished are done, only the one in flight needs to run again
+		unfinished = queue.Requeue(lost.Uid)
+		if len(unfinished) == 0 && queue.Remaining() == 0 {
+			responseStream <- &pb.Output{Response: fmt.Sprintf("Lost worker %v (%v) - it had no unfinished test files", lost.Uid, lostErr), Worker: &pb.Worker{Number: request.WorkerNumber, Uid: lost.Uid}, Created: timestamppb.Now()}
+			return []*api.ExecutionInfo{}, nil
+		}
+	} else {
+		// the results of the files that finished have been counted already
+		var perFile bool
+		unfinished, perFile = cs.unfinishedFiles(files, os.Getenv("REMOTE_DIR"))
+		if !perFile {
+			Logger(ctx).Debugf("No per file results to go by so all %v files of %v run again", len(files), lost.Uid)
+		}
+		if len(unfinished) == 0 {
+			responseStream <- &pb.Output{Response: fmt.Sprintf("Lost worker %v (%v) - it had no unfinished test files", lost.Uid, lostErr), Worker: &pb.Worker{Number: request.WorkerNumber, Uid: lost.Uid}, Created: timestamppb.Now()}
+			return []*api.ExecutionInfo{}, nil
+		}
+	}
+
+	replacement, ok := pool.Acquire(ctx)
+	if !ok {
+		responseStream <- &pb.Output{Response: fmt.Sprintf("Lost worker %v (%v) and there are no workers left to run its %v test files", lost.Uid, lostErr, len(unfinished)), Worker: &pb.Worker{Number: request.WorkerNumber, Uid: lost.Uid}, Created: timestamppb.Now()}
+		if ctx.Err() == nil {
+			responseStream <- &pb.Output{Control: types.FAILED, BriskError: &pb.BriskError{Error: "internal error - could not connect to workers"}, Created: timestamppb.Now()}
+		}
+		return []*api.ExecutionInfo{{ExitCode: 50, Output: "internal error - lost worker " + lost.Uid}}, lostErr
+	}
+	replacements := []api.Worker{replacement}
+	// the queue hands its files to whichever worker is free, without one we split them between the free workers
+	for queue == nil && len(replacements) < len(unfinished) {
+		worker, ok := pool.TryAcquire()
+		if !ok {
+			break
+		}
+		replacements = append(replacements, worker)
+	}
+	responseStream <- &pb.Output{Response: fmt.Sprintf("Lost worker %v (%v) - reassigning %v test files to %v", lost.Uid, lostErr, len(unfinished), strings.Join(workerUids(replacements), ", ")), Worker: &pb.Worker{Number: request.WorkerNumber, Uid: lost.Uid}, Created: timestamppb.Now()}
+
+	shares := make([][]string, len(replacements))
+	for i, file := range unfinished {
+		shares[i%len(shares)] = append(shares[i%len(shares)], file)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var executionInfos []*api.ExecutionInfo
+	var err error
+	for i, worker := range replacements {
+		wg.Add(1)
+		go func(worker api.Worker, share []string) {
+			defer wg.Done()
+			infos, runErr := runOnReplacement(ctx, worker, share, request, totalFileCount, cs, responseStream, config, retries, queue, pool)
+			mu.Lock()
+			defer mu.Unlock()
+			executionInfos = append(executionInfos, infos...)
+			if err == nil {
+				err = runErr
+			}
+		}(worker, shares[i])
+	}
+	wg.Wait()
+	return executionInfos, err
+}
+
+// runOnReplacement runs a lost worker's files on another worker and hands the files on again if that one is lost too
+func runOnReplacement(ctx context.Context, replacement api.Worker, files []string, request api.Command, totalFileCount int, cs *countStruct, responseStream chan *pb.Output, config *pb.Config, retries int, queue *batchQueue, pool *workerPool) ([]*api.ExecutionInfo, error) {
+	copied, err := copystructure.Copy(request)
+	if err != nil {
+		Logger(ctx).Errorf("Error copying request for reassignment %v", err)
+		pool.Release(replacement)
+		return []*api.ExecutionInfo{}, err
+	}
+
+	// the replacement has already been built for this run so there are no build commands
+	var executionInfos []*api.ExecutionInfo
+	if queue != nil {
+		executionInfos, err = runWithRetries(ctx, nil, []api.Command{copied.(api.Command)}, nil, totalFileCount, cs, responseStream, replacement, config, retries, queue, pool)
+	} else {
+		executionInfos, err = runWithRetries(ctx, files, []api.Command{copied.(api.Command)}, nil, totalFileCount, cs, responseStream, replacement, config, retries, nil, pool)
+	}
+	if workerLost(ctx, err) {
+		return reassignLostWorker(ctx, replacement, err, files, request, totalFileCount, cs, responseStream, config, retries, queue, pool)
+	}
+	pool.Release(replacement)
+	return executionInfos, err
+}
+
+func workerUids(workers []api.Worker) []string {
+	uids := make([]string, len(workers))
+	for i, worker := range workers {
+		uids[i] = worker.Uid
+	}
+	return uids
+}
+
+// deregisterLostWorker frees the worker from the project so it isn't handed out again
+func deregisterLostWorker(ctx context.Context, worker api.Worker) {
+	creds, authErr := auth.GetAuthCredsFromMd(ctx)
+	if authErr != nil {
+		Logger(ctx).Errorf("Error getting auth creds from md when deregistering lost worker %v", authErr)
+		return
+	}
+	authCtx, authErr := auth.AddAuthToCtx(ctx, creds)
+	if authErr != nil {
+		Logger(ctx).Errorf("Error adding auth to ctx when deregistering lost worker %v", authErr)
+		return
+	}
+	if err := DeRegisterWorkersForProject(authCtx, []*api.Worker{&worker}); err != nil {
+		Logger(ctx).Errorf("Error deregistering lost worker %v - %v", worker.Uid, err)
+	}
+}
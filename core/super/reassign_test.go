@@ -0,0 +1,194 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	. "brisk-supervisor/shared"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_runRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		idle     []string
+		lose     map[string]bool
+		wantRuns []string
+		wantIdle int
+		wantBusy int
+	}{
+		{
+			name:     "no idle worker retries on this worker",
+			wantRuns: []string{"own"},
+			wantBusy: 1,
+		},
+		{
+			name:     "an idle worker takes the retry and goes back to the pool",
+			idle:     []string{"idle"},
+			wantRuns: []string{"idle"},
+			wantIdle: 1,
+			wantBusy: 1,
+		},
+		{
+			name:     "a lost idle worker leaves the retry to this worker",
+			idle:     []string{"idle"},
+			lose:     map[string]bool{"idle": true},
+			wantRuns: []string{"idle", "own"},
+			wantBusy: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the worker doing the retry is busy, the idle ones have finished
+			pool := newWorkerPool(1 + len(tt.idle))
+			for _, uid := range tt.idle {
+				pool.Release(api.Worker{Uid: uid})
+			}
+			var runs []string
+			_, err := runRetry(context.Background(), pool, api.Worker{Uid: "own"}, func(worker api.Worker) ([]*api.ExecutionInfo, error) {
+				runs = append(runs, worker.Uid)
+				if tt.lose[worker.Uid] {
+					return nil, workerLostError
+				}
+				return []*api.ExecutionInfo{{}}, nil
+			})
+			if err != nil {
+				t.Fatalf("runRetry() error = %v", err)
+			}
+			if len(runs) != len(tt.wantRuns) {
+				t.Fatalf("runRetry() ran on %v, want %v", runs, tt.wantRuns)
+			}
+			for i := range runs {
+				if runs[i] != tt.wantRuns[i] {
+					t.Errorf("runRetry() ran on %v, want %v", runs, tt.wantRuns)
+				}
+			}
+			if len(pool.idle) != tt.wantIdle || pool.busy != tt.wantBusy {
+				t.Errorf("pool has %v idle and %v busy, want %v and %v", len(pool.idle), pool.busy, tt.wantIdle, tt.wantBusy)
+			}
+		})
+	}
+}
+
+func Test_workerPool(t *testing.T) {
+	tests := []struct {
+		name string
+		// what the other workers do while one waits to take over a lost worker's files
+		others   func(p *workerPool)
+		busy     int
+		wantUid  string
+		wantOk   bool
+		wantBusy int
+	}{
+		{
+			name:     "a worker that is already idle is taken straight away",
+			others:   func(p *workerPool) { p.Release(api.Worker{Uid: "w1"}) },
+			busy:     2,
+			wantUid:  "w1",
+			wantOk:   true,
+			wantBusy: 2,
+		},
+		{
+			name: "waits for a busy worker to finish",
+			others: func(p *workerPool) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					p.Release(api.Worker{Uid: "w2"})
+				}()
+			},
+			busy:     1,
+			wantUid:  "w2",
+			wantOk:   true,
+			wantBusy: 1,
+		},
+		{
+			name: "gives up when the busy workers are lost as well",
+			others: func(p *workerPool) {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					p.Lost()
+				}()
+			},
+			busy:     1,
+			wantOk:   false,
+			wantBusy: 0,
+		},
+		{
+			name:     "gives up with no workers left",
+			others:   func(p *workerPool) {},
+			busy:     0,
+			wantOk:   false,
+			wantBusy: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newWorkerPool(tt.busy)
+			tt.others(pool)
+			worker, ok := pool.Acquire(context.Background())
+			if ok != tt.wantOk || worker.Uid != tt.wantUid {
+				t.Errorf("Acquire() = %v, %v, want %v, %v", worker.Uid, ok, tt.wantUid, tt.wantOk)
+			}
+			if pool.busy != tt.wantBusy {
+				t.Errorf("pool has %v busy, want %v", pool.busy, tt.wantBusy)
+			}
+		})
+	}
+}
+
+func Test_workerPoolAcquireCancelled(t *testing.T) {
+	pool := newWorkerPool(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, ok := pool.Acquire(ctx); ok {
+		t.Error("Acquire() took a worker after the run was cancelled")
+	}
+	if _, ok := pool.TryAcquire(); ok {
+		t.Error("TryAcquire() took a worker when none were idle")
+	}
+}
+
+func Test_workerLost(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"no error", context.Background(), nil, false},
+		{"lost connection", context.Background(), workerLostError, true},
+		{"worker unavailable", context.Background(), status.Error(codes.Unavailable, "gone"), true},
+		{"failing tests", context.Background(), CommandFailedError, false},
+		{"timed out command", context.Background(), commandTimedOutError, false},
+		{"run cancelled", cancelled, workerLostError, false},
+		{"some other error", context.Background(), errors.New("oops"), false},
+	}
+	for _, tt := range tests {
+		if got := workerLost(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("workerLost(%v) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/types"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/mitchellh/copystructure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// retriesFor gives the number of times to retry failing files, a retries setting on the command wins over the config
+func retriesFor(command api.Command, config *pb.Config) int {
+	if command.Retries > 0 {
+		return int(command.Retries)
+	}
+	return int(config.Retries)
+}
+
+// runWithRetries runs the commands on the worker and if they fail re-runs only the failing files up to retries times
+// tests that fail and then pass on a retry are reported as flaky rather than being hidden
+// when queue is set the first attempt pulls its files from the queue, retries run on an idle worker from pool when
+// there is one
+func runWithRetries(ctx context.Context, files []string, requests []api.Command, buildCommands []api.Command, totalFileCount int, cs *countStruct, responseStream chan *pb.Output, worker api.Worker, config *pb.Config, retries int, queue *batchQueue, pool *workerPool) ([]*api.ExecutionInfo, error) {
+	if retries <= 0 {
+		return connectToServerWithBatches(ctx, files, requests, buildCommands, totalFileCount, cs, responseStream, worker, config, queue)
+	}
+
+	results := NewJunitReport()
+	reportedFlaky := 0
+	attemptFiles := files
+	allExecutionInfo := []*api.ExecutionInfo{}
+
+	for attempt := 0; ; attempt++ {
+		willRetry := attempt < retries
+
+		attemptStream := make(chan *pb.Output)
+		attemptDone := make(chan bool)
+		go func() {
+			defer close(attemptDone)
+			for output := range attemptStream {
+				// the attempt goes on the results before they are counted so the counted results have it too
+				for _, r := range output.TestResults {
+					r.Attempt = int32(attempt)
+				}
+				if cs != nil {
+					countOutput(ctx, output, cs, config.Framework)
+				}
+				results.AddOutput(ctx, config.Framework, output)
+				if willRetry && output.Control == types.FAILED {
+					// the cli stops the run when it sees FAILED so we hold it back while we can still retry
+					output = proto.Clone(output).(*pb.Output)
+					output.Control = ""
+				}
+				responseStream <- output
+			}
+		}()
+
+		var executionInfos []*api.ExecutionInfo
+		var err error
+		// connectToServer appends the files to the args so every attempt needs fresh copies
+		run := func(worker api.Worker, queue *batchQueue) ([]*api.ExecutionInfo, error) {
+			attemptRequests, err := copyRequests(requests)
+			if err != nil {
+				Logger(ctx).Errorf("Error copying request for retry %v", err)
+				return []*api.ExecutionInfo{}, err
+			}
+			return connectToServerWithBatches(ctx, attemptFiles, attemptRequests, buildCommands, totalFileCount, nil, attemptStream, worker, config, queue)
+		}
+		if attempt == 0 {
+			executionInfos, err = run(worker, queue)
+			if queue != nil {
+				attemptFiles = queue.Assigned(worker.Uid)
+			}
+		} else {
+			executionInfos, err = runRetry(ctx, pool, worker, func(worker api.Worker) ([]*api.ExecutionInfo, error) {
+				return run(worker, nil)
+			})
+		}
+		close(attemptStream)
+		<-attemptDone
+		allExecutionInfo = append(allExecutionInfo, executionInfos...)
+
+		flaky := results.FlakyTests()
+		for _, tc := range flaky[reportedFlaky:] {
+			responseStream <- &pb.Output{Response: fmt.Sprintf("FLAKY: %v (%v) passed on retry %v", tc.Name, tc.File, attempt), Worker: &pb.Worker{Number: requests[0].WorkerNumber, Uid: worker.Uid}, Created: timestamppb.Now()}
+			cs.addFlaky(fmt.Sprintf("%v (%v)", tc.Name, tc.File))
+		}
+		reportedFlaky = len(flaky)
+
+		failed := err != nil || (len(executionInfos) > 0 && executionInfos[len(executionInfos)-1].ExitCode != 0)
+		if !failed {
+			if attempt > 0 && len(flaky) == 0 {
+				// we couldn't parse the results but the files passed this time so they are flaky
+				responseStream <- &pb.Output{Response: fmt.Sprintf("FLAKY: %v passed on retry %v", strings.Join(attemptFiles, ", "), attempt), Worker: &pb.Worker{Number: requests[0].WorkerNumber, Uid: worker.Uid}, Created: timestamppb.Now()}
+				for _, f := range attemptFiles {
+					cs.addFlaky(f)
+				}
+			}
+			return allExecutionInfo, err
+		}
+		if !willRetry || ctx.Err() != nil {
+			return allExecutionInfo, err
+		}
+		if err != nil && !errors.Is(err, CommandFailedError) {
+			// we couldn't talk to the worker - retrying the tests won't help
+			Logger(ctx).Debugf("Not retrying as the error is not a test failure %v", err)
+			return allExecutionInfo, err
+		}
+
+		failedFiles := results.FailedFiles()
+		if len(failedFiles) > 0 {
+			cs.removeRetried(results, failedFiles)
+			attemptFiles = retryTargets(attemptFiles, failedFiles)
+		} else {
+			Logger(ctx).Debugf("No parsed results for the failure so retrying all %v files", len(attemptFiles))
+		}
+		responseStream <- &pb.Output{Response: fmt.Sprintf("Retrying %v failing test files (attempt %v of %v)", len(attemptFiles), attempt+1, retries), Worker: &pb.Worker{Number: requests[0].WorkerNumber, Uid: worker.Uid}, Created: timestamppb.Now()}
+		// the worker has already been built
+		buildCommands = nil
+	}
+}
+
+func copyRequests(requests []api.Command) ([]api.Command, error) {
+	copiedRequests := []api.Command{}
+	for _, r := range requests {
+		copied, err := copystructure.Copy(r)
+		if err != nil {
+			return nil, err
+		}
+		copiedRequests = append(copiedRequests, copied.(api.Command))
+	}
+	return copiedRequests, nil
+}
+
+// runRetry runs a retry on an idle worker when there is one so whatever is wrong with this worker doesn't fail the
+// files again, if the idle worker is lost the retry runs on this worker instead
+func runRetry(ctx context.Context, pool *workerPool, worker api.Worker, run func(api.Worker) ([]*api.ExecutionInfo, error)) ([]*api.ExecutionInfo, error) {
+	if pool == nil {
+		return run(worker)
+	}
+	idle, ok := pool.TryAcquire()
+	if !ok {
+		return run(worker)
+	}
+	Logger(ctx).Debugf("Retrying the files of %v on idle worker %v", worker.Uid, idle.Uid)
+	executionInfos, err := run(idle)
+	if !workerLost(ctx, err) {
+		pool.Release(idle)
+		return executionInfos, err
+	}
+	Logger(ctx).Errorf("Lost worker %v running a retry %v", idle.Uid, err)
+	pool.Lost()
+	deregisterLostWorker(ctx, idle)
+	return run(worker)
+}
+
+// retryTargets is what to re-run for the failed files, a file this worker only ran some of the examples of
+// re-runs just those examples as the rest ran on other workers
+func retryTargets(attemptFiles []string, failedFiles []string) []string {
+	examples := map[string][]string{}
+	for _, f := range attemptFiles {
+		if file := RspecExampleFile(f); file != f {
+			examples[file] = append(examples[file], f)
+		}
+	}
+	var targets []string
+	for _, f := range failedFiles {
+		if ids, ok := examples[f]; ok {
+			targets = append(targets, ids...)
+		} else {
+			targets = append(targets, f)
+		}
+	}
+	return targets
+}
+
+// removeRetried takes the results of files we are about to retry out of the counts so they aren't counted twice
+func (cs *countStruct) removeRetried(results *JunitReport, files []string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	retried := map[string]bool{}
+	for _, f := range files {
+		retried[f] = true
+	}
+	for _, tc := range results.TestCases {
+		if !retried[tc.File] {
+			continue
+		}
+		switch tc.Status {
+		case ResultFailed:
+			cs.FailCount--
+		case ResultPassed:
+			cs.PassCount--
+		case ResultSkipped:
+			cs.SkipCount--
+		}
+	}
+}
+
+// unfinishedFiles are the files that no results have come back for yet, compared relative to the repo in workDir.
+// false means there are no per file results to go by, like for a framework that doesn't report them, so all of the
+// files have to run again
+func (cs *countStruct) unfinishedFiles(files []string, workDir string) ([]string, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if len(cs.results) == 0 {
+		return files, false
+	}
+	finished := map[string]bool{}
+	for _, r := range cs.results {
+		finished[RepoRelativeFile(r.File, workDir)] = true
+	}
+	var unfinished []string
+	for _, f := range files {
+		if !finished[RepoRelativeFile(f, workDir)] {
+			unfinished = append(unfinished, f)
+		}
+	}
+	return unfinished, true
+}
+
+func (cs *countStruct) addFlaky(name string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.FlakyTests = append(cs.FlakyTests, name)
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	. "brisk-supervisor/shared"
+	"reflect"
+	"testing"
+)
+
+func Test_unfinishedFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string
+		results     []TestResult
+		want        []string
+		wantPerFile bool
+	}{
+		{
+			name:        "jest reports absolute paths",
+			files:       []string{"src/a.test.js", "src/b.test.js"},
+			results:     []TestResult{{File: "/tmp/remote_dir/src/a.test.js", Name: "a"}},
+			want:        []string{"src/b.test.js"},
+			wantPerFile: true,
+		},
+		{
+			name:        "rspec reports ./ paths",
+			files:       []string{"spec/a_spec.rb", "./spec/b_spec.rb"},
+			results:     []TestResult{{File: "./spec/a_spec.rb", Name: "a"}, {File: "./spec/b_spec.rb", Name: "b"}},
+			want:        nil,
+			wantPerFile: true,
+		},
+		{
+			name:        "split files with the work dir",
+			files:       []string{"/tmp/remote_dir/spec/a_spec.rb", "/tmp/remote_dir/spec/b_spec.rb"},
+			results:     []TestResult{{File: "./spec/b_spec.rb", Name: "b"}},
+			want:        []string{"/tmp/remote_dir/spec/a_spec.rb"},
+			wantPerFile: true,
+		},
+		{
+			name:        "no per file results",
+			files:       []string{"a", "b"},
+			results:     nil,
+			want:        []string{"a", "b"},
+			wantPerFile: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &countStruct{}
+			for _, r := range tt.results {
+				if cs.results == nil {
+					cs.results = map[string]TestResult{}
+				}
+				cs.results[r.File+"\x00"+r.Name] = r
+			}
+			got, perFile := cs.unfinishedFiles(tt.files, "/tmp/remote_dir/")
+			if !reflect.DeepEqual(got, tt.want) || perFile != tt.wantPerFile {
+				t.Errorf("unfinishedFiles() = %v, %v, want %v, %v", got, perFile, tt.want, tt.wantPerFile)
+			}
+		})
+	}
+}
+
+func Test_removeRetried(t *testing.T) {
+	// go tests are counted per test so a retried package takes each of its tests out of the counts
+	results := &JunitReport{TestCases: []TestResult{
+		{File: "example.com/a", Name: "TestOk", Status: ResultPassed},
+		{File: "example.com/a", Name: "TestBroken", Status: ResultFailed},
+		{File: "example.com/a", Name: "TestLater", Status: ResultSkipped},
+		{File: "example.com/b", Name: "TestOk", Status: ResultPassed},
+	}}
+	cs := &countStruct{TestCounts: TestCounts{PassCount: 2, FailCount: 1, SkipCount: 1}}
+	cs.removeRetried(results, []string{"example.com/a"})
+	if cs.PassCount != 1 || cs.FailCount != 0 || cs.SkipCount != 0 {
+		t.Errorf("removeRetried() left %+v, want only the test in example.com/b", cs.TestCounts)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	. "brisk-supervisor/shared"
+	. "brisk-supervisor/shared/logger"
+	"brisk-supervisor/shared/services/log_service"
+	"brisk-supervisor/shared/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// how many runs are kept for attaching once they have finished
+const keptRuns = 5
+
+// the most output kept for a run, anything after is only sent live
+const maxRunLogBytes = 32 * 1024 * 1024
+
+// runLog is the output of a run kept so attach can replay it from the start and then follow it
+type runLog struct {
+	id           string
+	projectToken string
+
+	mu      sync.Mutex
+	outputs []*pb.Output
+	size    int
+	dropped int
+	done    bool
+	// changed is closed and replaced each time an output is added or the run finishes
+	changed    chan struct{}
+	workerLogs []WorkerLog
+	// indexMu keeps the stores of the worker log index in order so the last one has every worker
+	indexMu sync.Mutex
+}
+
+// add keeps what attach shows of the output and wakes up anyone following the run
+func (r *runLog) add(output *pb.Output) {
+	output = runLogOutput(output)
+	if output == nil {
+		return
+	}
+	size := proto.Size(output)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size+size <= maxRunLogBytes {
+		r.outputs = append(r.outputs, output)
+		r.size += size
+	} else {
+		r.dropped++
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// runLogOutput is the part of the output attach prints, nil when there isn't any. The rest, like the test results
+// and the files in the command, can be much bigger. Artifacts and coverage are only written by the CLI that started
+// the run and so is the log encryption key.
+func runLogOutput(output *pb.Output) *pb.Output {
+	kept := &pb.Output{Stdout: output.Stdout, Stderr: output.Stderr, Response: output.Response, Control: output.Control, StageStatus: output.StageStatus, BriskError: output.BriskError, RunId: output.RunId, Created: output.Created}
+	if kept.Stdout == "" && kept.Stderr == "" && kept.Response == "" && kept.Control == "" && kept.StageStatus == "" && kept.BriskError == nil && kept.RunId == "" {
+		return nil
+	}
+	if output.Worker != nil {
+		kept.Worker = &pb.Worker{Number: output.Worker.Number, Uid: output.Worker.Uid}
+	}
+	if output.Command != nil {
+		kept.Command = &api.Command{CommandId: output.Command.CommandId}
+	}
+	// the totals so far for the status line
+	kept.TotalTestCount = output.TotalTestCount
+	kept.TotalTestPass = output.TotalTestPass
+	kept.TotalTestFail = output.TotalTestFail
+	kept.TotalTestFlaky = output.TotalTestFlaky
+	return kept
+}
+
+// addWorkerLog records a worker log that has been stored and stores the index again so brisk logs can find it, a
+// failure can end the run while the other workers are still storing their logs
+func (r *runLog) addWorkerLog(ctx context.Context, workerLog WorkerLog, logEncryptionKey string, logger *BriskLogger) {
+	r.mu.Lock()
+	r.workerLogs = append(r.workerLogs, workerLog)
+	r.mu.Unlock()
+	storeWorkerLogIndex(ctx, r, logEncryptionKey, logger)
+}
+
+func (r *runLog) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// follow sends every output of the run from the start and returns once the run has finished and everything has been
+// sent, or ctx is done
+func (r *runLog) follow(ctx context.Context, send func(*pb.Output) error) error {
+	next := 0
+	warned := false
+	for {
+		r.mu.Lock()
+		outputs := r.outputs[next:len(r.outputs):len(r.outputs)]
+		next = len(r.outputs)
+		dropped, done, changed := r.dropped, r.done, r.changed
+		r.mu.Unlock()
+
+		for _, output := range outputs {
+			if err := send(output); err != nil {
+				return err
+			}
+		}
+		if dropped > 0 && !warned {
+			warned = true
+			if err := send(&pb.Output{Response: fmt.Sprintf("The run has too much output to keep, only the first %v MiB are replayed", maxRunLogBytes/(1024*1024)), Created: timestamppb.Now()}); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runLogRegistry is the runs on this supervisor that can be attached to, the latest last
+type runLogRegistry struct {
+	mu   sync.Mutex
+	runs []*runLog
+}
+
+var runLogs = &runLogRegistry{}
+
+func (l *runLogRegistry) start(streamInfo types.LogStreamInfo) *runLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	run := &runLog{id: streamInfo.LogUid, projectToken: streamInfo.ProjectToken, changed: make(chan struct{})}
+	l.runs = append(l.runs, run)
+	if len(l.runs) > keptRuns {
+		l.runs = l.runs[len(l.runs)-keptRuns:]
+	}
+	return run
+}
+
+// find gives the run with the id for the project, an empty id is the latest run
+func (l *runLogRegistry) find(id string, projectToken string) *runLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := len(l.runs) - 1; i >= 0; i-- {
+		run := l.runs[i]
+		if run.projectToken == projectToken && (id == "" || run.id == id) {
+			return run
+		}
+	}
+	return nil
+}
+
+// storeWorkerLogIndex stores the list of the worker logs of the run so far, encrypted like the logs
+func storeWorkerLogIndex(ctx context.Context, run *runLog, logEncryptionKey string, logger *BriskLogger) {
+	run.indexMu.Lock()
+	defer run.indexMu.Unlock()
+	run.mu.Lock()
+	workerLogs := run.workerLogs
+	run.mu.Unlock()
+	if workerLogs == nil {
+		workerLogs = []WorkerLog{}
+	}
+	index, err := json.Marshal(workerLogs)
+	if err != nil {
+		logger.Errorf("Error making the worker log index %v", err)
+		return
+	}
+	logger.Debugf("Storing the index of %v worker logs", len(workerLogs))
+	storeLog(ctx, bytes.NewBuffer(index), types.LogStreamInfo{LogUid: WorkerLogIndexUid(run.id), ProjectToken: run.projectToken, LogEncryptionKey: logEncryptionKey}, logger)
+}
+
+type logStreamServer struct {
+	pb.UnimplementedLogStreamServer
+}
+
+func (s *logStreamServer) Attach(req *pb.AttachRequest, stream pb.LogStream_AttachServer) error {
+	ctx := stream.Context()
+	projectToken, err := GetAuthenticatedProjectToken(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid auth: %v", err)
+	}
+	run := runLogs.find(req.RunId, projectToken)
+	if run == nil {
+		if req.RunId == "" {
+			return status.Error(codes.NotFound, "there are no runs on this supervisor")
+		}
+		return status.Errorf(codes.NotFound, "run %v is not on this supervisor", req.RunId)
+	}
+	Logger(ctx).Infof("Attaching to run %v", run.id)
+	return run.follow(ctx, stream.Send)
+}
+
+// how much of a stored log goes in each message
+const logChunkSize = 64 * 1024
+
+func (s *logStreamServer) GetLog(req *pb.GetLogRequest, stream pb.LogStream_GetLogServer) error {
+	ctx := stream.Context()
+	projectToken, err := GetAuthenticatedProjectToken(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid auth: %v", err)
+	}
+	if !log_service.ValidUid(req.Uid) {
+		return status.Errorf(codes.InvalidArgument, "%q is not a run id", req.Uid)
+	}
+	log, err := log_service.New(logStore, projectToken, req.Uid).Download(ctx)
+	if errors.Is(err, log_service.LogNotFoundError) {
+		return status.Errorf(codes.NotFound, "no log for %v, it is stored once the run finishes", req.Uid)
+	}
+	if err != nil {
+		Logger(ctx).Errorf("Error getting log %v %v", req.Uid, err)
+		return err
+	}
+	defer log.Close()
+
+	buf := make([]byte, logChunkSize)
+	for {
+		n, err := log.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&pb.LogChunk{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			Logger(ctx).Errorf("Error reading log %v %v", req.Uid, err)
+			return err
+		}
+	}
+}
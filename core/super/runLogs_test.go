@@ -0,0 +1,126 @@
+// Copyright 2024 Brisk, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"brisk-supervisor/api"
+	pb "brisk-supervisor/brisk-supervisor"
+	"brisk-supervisor/shared/types"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_runLogOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output *pb.Output
+		want   *pb.Output
+	}{
+		{
+			name:   "nothing to print",
+			output: &pb.Output{TestResults: []*pb.TestResult{{Name: "a"}}, JsonResults: "{}"},
+			want:   nil,
+		},
+		{
+			name:   "the key and the results are left out",
+			output: &pb.Output{RunId: "run", LogEncryptionKey: "key", JsonResults: "{}"},
+			want:   &pb.Output{RunId: "run"},
+		},
+		{
+			name:   "only the id of the command is kept",
+			output: &pb.Output{Stdout: "ok", Worker: &pb.Worker{Number: 2, Uid: "w2"}, Command: &api.Command{CommandId: "unit", Args: []string{"a", "b"}}, TotalTestPass: 3},
+			want:   &pb.Output{Stdout: "ok", Worker: &pb.Worker{Number: 2, Uid: "w2"}, Command: &api.Command{CommandId: "unit"}, TotalTestPass: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runLogOutput(tt.output)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("runLogOutput() = %v, want %v", got, tt.want)
+			}
+			if got == nil {
+				return
+			}
+			if got.String() != tt.want.String() {
+				t.Errorf("runLogOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runLogCapped(t *testing.T) {
+	run := &runLog{id: "run", changed: make(chan struct{})}
+	line := strings.Repeat("x", 1024*1024)
+	kept := maxRunLogBytes/(1024*1024) - 1
+	for i := 0; i < kept+5; i++ {
+		run.add(&pb.Output{Stdout: line})
+	}
+	run.add(&pb.Output{TestResults: []*pb.TestResult{{Name: "not kept"}}})
+	run.finish()
+
+	if len(run.outputs) != kept || run.dropped != 5 || run.size > maxRunLogBytes {
+		t.Errorf("kept %v outputs of %v bytes and dropped %v, want %v kept under %v bytes and 5 dropped", len(run.outputs), run.size, run.dropped, kept, maxRunLogBytes)
+	}
+
+	var sent []*pb.Output
+	err := run.follow(context.Background(), func(output *pb.Output) error {
+		sent = append(sent, output)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("follow() error = %v", err)
+	}
+	if len(sent) != kept+1 || !strings.Contains(sent[len(sent)-1].Response, "too much output") {
+		t.Errorf("follow() sent %v outputs, want the %v kept and a warning", len(sent), kept)
+	}
+}
+
+func Test_runLogRegistry(t *testing.T) {
+	registry := &runLogRegistry{}
+	for i := 0; i < keptRuns+2; i++ {
+		registry.start(types.LogStreamInfo{LogUid: fmt.Sprint("run-", i), ProjectToken: "project"})
+	}
+	registry.start(types.LogStreamInfo{LogUid: "other-run", ProjectToken: "other-project"})
+
+	tests := []struct {
+		name         string
+		id           string
+		projectToken string
+		want         string
+	}{
+		{"the latest run of the project", "", "project", fmt.Sprint("run-", keptRuns+1)},
+		{"a run by id", "run-3", "project", "run-3"},
+		{"an evicted run", "run-0", "project", ""},
+		{"another project's run", "other-run", "project", ""},
+		{"the other project's latest run", "", "other-project", "other-run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := registry.find(tt.id, tt.projectToken)
+			got := ""
+			if run != nil {
+				got = run.id
+			}
+			if got != tt.want {
+				t.Errorf("find(%q, %q) = %q, want %q", tt.id, tt.projectToken, got, tt.want)
+			}
+		})
+	}
+	if len(registry.runs) != keptRuns {
+		t.Errorf("registry kept %v runs, want %v", len(registry.runs), keptRuns)
+	}
+}